@@ -0,0 +1,387 @@
+/**
+ * (C) Copyright IBM Corp. 2021.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package dialog models the dialog of a Watson Assistant v1 workspace as a tree.
+//
+// The service returns dialog nodes as a flat list, linked by their parent and previous sibling. NewTree rebuilds the
+// hierarchy and the order of siblings, Walk visits the nodes depth-first in the order the service evaluates them,
+// and Validate reports the problems that a dialog linter looks for. Insert, Move and Delete change the tree and
+// rewrite the parent and previous sibling of every node concerned, so that Nodes returns a list that the service
+// accepts.
+package dialog
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/IBM/go-sdk-core/v5/core"
+	"github.com/watson-developer-cloud/go-sdk/v2/assistantv1"
+)
+
+// SkipChildren can be returned by the function of Walk to skip the descendants of a node
+var SkipChildren = errors.New("skip the children of this node")
+
+// Node : A dialog node in a Tree
+type Node struct {
+	// The dialog node, whose Parent and PreviousSibling the tree keeps up to date.
+	DialogNode *assistantv1.DialogNode
+
+	// The parent in the tree; nil for root nodes and orphans.
+	Parent *Node
+
+	// The children, in sibling order.
+	Children []*Node
+}
+
+// ID : Returns the dialog node ID
+func (node *Node) ID() string {
+	return core.StringNilMapper(node.DialogNode.DialogNode)
+}
+
+// Tree : The dialog nodes of a workspace as a tree
+type Tree struct {
+	// The nodes without a parent, in sibling order.
+	Roots []*Node
+
+	// The nodes whose parent does not exist, in ID order. They are kept apart so that their parent is not lost.
+	Orphans []*Node
+
+	nodes map[string]*Node
+
+	// The problems found while building the tree
+	buildIssues []Issue
+}
+
+// NewTree : Builds the tree of a flat list of dialog nodes, as returned by ListDialogNodes or in a Workspace. The
+// nodes are copied. Siblings are ordered by following their previous siblings; siblings that cannot be placed that
+// way, because of a cycle or a previous sibling that is not a sibling, come last in ID order and are reported by
+// Validate. It is an error for two nodes to have the same ID.
+func NewTree(nodes []assistantv1.DialogNode) (*Tree, error) {
+	tree := &Tree{nodes: make(map[string]*Node, len(nodes))}
+	for i := range nodes {
+		copied := nodes[i]
+		node := &Node{DialogNode: &copied}
+		id := node.ID()
+		if id == "" {
+			return nil, fmt.Errorf("dialog node %d has no ID", i+1)
+		}
+		if _, ok := tree.nodes[id]; ok {
+			return nil, fmt.Errorf("dialog node %q is defined more than once", id)
+		}
+		tree.nodes[id] = node
+	}
+
+	groups := map[string][]*Node{}
+	for _, id := range tree.ids() {
+		node := tree.nodes[id]
+		parentID := core.StringNilMapper(node.DialogNode.Parent)
+		if parentID != "" {
+			parent, ok := tree.nodes[parentID]
+			if !ok {
+				tree.Orphans = append(tree.Orphans, node)
+				tree.buildIssues = append(tree.buildIssues, Issue{Kind: IssueKindOrphanConst, DialogNode: id,
+					Message: fmt.Sprintf("parent %q does not exist", parentID)})
+				continue
+			}
+			node.Parent = parent
+		}
+		groups[parentID] = append(groups[parentID], node)
+	}
+	for parentID, siblings := range groups {
+		ordered := tree.orderSiblings(siblings)
+		if parentID == "" {
+			tree.Roots = ordered
+		} else {
+			tree.nodes[parentID].Children = ordered
+		}
+	}
+	return tree, nil
+}
+
+// orderSiblings : Returns siblings in the order of their previous siblings, recording what stands in the way
+func (tree *Tree) orderSiblings(siblings []*Node) []*Node {
+	inGroup := map[string]bool{}
+	for _, node := range siblings {
+		inGroup[node.ID()] = true
+	}
+	// next maps a previous sibling to the nodes that follow it; "" is the start of the list
+	next := map[string][]*Node{}
+	for _, node := range siblings {
+		previous := core.StringNilMapper(node.DialogNode.PreviousSibling)
+		if previous != "" && !inGroup[previous] {
+			tree.buildIssues = append(tree.buildIssues, Issue{Kind: IssueKindDanglingPreviousSiblingConst, DialogNode: node.ID(),
+				Message: fmt.Sprintf("previous sibling %q is not a sibling", previous)})
+			continue
+		}
+		next[previous] = append(next[previous], node)
+	}
+
+	// Every node that follows a placed node is placed after it, the first in ID order first, so that one
+	// conflict does not cost the rest of the list
+	var ordered []*Node
+	placed := map[string]bool{}
+	var place func(previous string)
+	place = func(previous string) {
+		following := next[previous]
+		for i, node := range following {
+			if i > 0 {
+				tree.buildIssues = append(tree.buildIssues, Issue{Kind: IssueKindSiblingConflictConst, DialogNode: node.ID(),
+					Message: fmt.Sprintf("%q is also the previous sibling of %q", previous, following[0].ID())})
+			}
+			placed[node.ID()] = true
+			ordered = append(ordered, node)
+			place(node.ID())
+		}
+	}
+	place("")
+
+	byID := map[string]*Node{}
+	for _, node := range siblings {
+		byID[node.ID()] = node
+	}
+	for _, node := range siblings {
+		if placed[node.ID()] {
+			continue
+		}
+		if inCycle(node, byID) {
+			tree.buildIssues = append(tree.buildIssues, Issue{Kind: IssueKindSiblingCycleConst, DialogNode: node.ID(),
+				Message: "the previous siblings of the node lead back to it"})
+		}
+		ordered = append(ordered, node)
+	}
+	return ordered
+}
+
+// inCycle : Reports whether following the previous siblings of a node leads back to it
+func inCycle(node *Node, siblings map[string]*Node) bool {
+	current := node
+	for range siblings {
+		current = siblings[core.StringNilMapper(current.DialogNode.PreviousSibling)]
+		if current == nil {
+			return false
+		}
+		if current == node {
+			return true
+		}
+	}
+	return false
+}
+
+// Node : Returns the node with the ID, or nil
+func (tree *Tree) Node(id string) *Node {
+	return tree.nodes[id]
+}
+
+// Len : Returns the number of nodes
+func (tree *Tree) Len() int {
+	return len(tree.nodes)
+}
+
+// Walk : Calls fn for each node depth-first, a parent before its children and siblings in order, with the depth of
+// the node, starting at 0 for roots. Orphans and their descendants come after the roots. Walk stops at the first
+// error of fn and returns it, except for SkipChildren, which skips the descendants of the node.
+func (tree *Tree) Walk(fn func(node *Node, depth int) error) error {
+	var walk func(nodes []*Node, depth int) error
+	walk = func(nodes []*Node, depth int) error {
+		for _, node := range nodes {
+			err := fn(node, depth)
+			if err == SkipChildren {
+				continue
+			}
+			if err != nil {
+				return err
+			}
+			if err = walk(node.Children, depth+1); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	if err := walk(tree.Roots, 0); err != nil {
+		return err
+	}
+	return walk(tree.Orphans, 0)
+}
+
+// Nodes : Returns the dialog nodes in the order of Walk
+func (tree *Tree) Nodes() []assistantv1.DialogNode {
+	nodes := make([]assistantv1.DialogNode, 0, len(tree.nodes))
+	_ = tree.Walk(func(node *Node, depth int) error {
+		nodes = append(nodes, *node.DialogNode)
+		return nil
+	})
+	return nodes
+}
+
+// Insert : Adds a dialog node as a child of parent, or as a root when parent is empty, right after the sibling
+// after, or first when after is empty. The Parent and PreviousSibling of the node are set accordingly, and the node
+// that followed after now follows the new node.
+func (tree *Tree) Insert(dialogNode assistantv1.DialogNode, parent string, after string) (*Node, error) {
+	id := core.StringNilMapper(dialogNode.DialogNode)
+	if id == "" {
+		return nil, fmt.Errorf("the dialog node has no ID")
+	}
+	if _, ok := tree.nodes[id]; ok {
+		return nil, fmt.Errorf("dialog node %q already exists", id)
+	}
+	parentNode, index, err := tree.position(parent, after)
+	if err != nil {
+		return nil, err
+	}
+	node := &Node{DialogNode: &dialogNode}
+	tree.nodes[id] = node
+	tree.attach(node, parentNode, index)
+	return node, nil
+}
+
+// Move : Moves a node, with its descendants, to be a child of parent, or a root when parent is empty, right after
+// the sibling after, or first when after is empty. The siblings of the old and new positions are relinked.
+func (tree *Tree) Move(id string, parent string, after string) error {
+	node, ok := tree.nodes[id]
+	if !ok {
+		return fmt.Errorf("dialog node %q does not exist", id)
+	}
+	if after == id {
+		return fmt.Errorf("dialog node %q cannot follow itself", id)
+	}
+	for ancestor := tree.nodes[parent]; ancestor != nil; ancestor = ancestor.Parent {
+		if ancestor == node {
+			return fmt.Errorf("dialog node %q cannot move under itself", id)
+		}
+	}
+	if _, _, err := tree.position(parent, after); err != nil {
+		return err
+	}
+	tree.detach(node)
+	// The index is found again, since detaching the node may have shifted its siblings
+	parentNode, index, _ := tree.position(parent, after)
+	tree.attach(node, parentNode, index)
+	return nil
+}
+
+// Delete : Removes a node and its descendants, as the service does. The node that followed it now follows its
+// previous sibling. Jumps to the removed nodes are left for Validate to report.
+func (tree *Tree) Delete(id string) error {
+	node, ok := tree.nodes[id]
+	if !ok {
+		return fmt.Errorf("dialog node %q does not exist", id)
+	}
+	tree.detach(node)
+	var forget func(node *Node)
+	forget = func(node *Node) {
+		delete(tree.nodes, node.ID())
+		for _, child := range node.Children {
+			forget(child)
+		}
+	}
+	forget(node)
+	return nil
+}
+
+// position : Returns the parent node and the index among its children of a position given by IDs
+func (tree *Tree) position(parent string, after string) (*Node, int, error) {
+	var parentNode *Node
+	siblings := tree.Roots
+	if parent != "" {
+		var ok bool
+		if parentNode, ok = tree.nodes[parent]; !ok {
+			return nil, 0, fmt.Errorf("parent %q does not exist", parent)
+		}
+		siblings = parentNode.Children
+	}
+	if after == "" {
+		return parentNode, 0, nil
+	}
+	for i, sibling := range siblings {
+		if sibling.ID() == after {
+			return parentNode, i + 1, nil
+		}
+	}
+	return nil, 0, fmt.Errorf("%q is not a child of %q", after, parent)
+}
+
+// siblingsOf : Returns the list that holds a node
+func (tree *Tree) siblingsOf(node *Node) *[]*Node {
+	switch {
+	case node.Parent != nil:
+		return &node.Parent.Children
+	case containsNode(tree.Orphans, node):
+		return &tree.Orphans
+	default:
+		return &tree.Roots
+	}
+}
+
+func (tree *Tree) detach(node *Node) {
+	siblings := tree.siblingsOf(node)
+	for i, sibling := range *siblings {
+		if sibling == node {
+			*siblings = append((*siblings)[:i], (*siblings)[i+1:]...)
+			break
+		}
+	}
+	if siblings != &tree.Orphans {
+		relink(*siblings, node.Parent)
+	}
+	node.Parent = nil
+}
+
+func (tree *Tree) attach(node *Node, parent *Node, index int) {
+	siblings := &tree.Roots
+	if parent != nil {
+		siblings = &parent.Children
+	}
+	*siblings = append(*siblings, nil)
+	copy((*siblings)[index+1:], (*siblings)[index:])
+	(*siblings)[index] = node
+	node.Parent = parent
+	relink(*siblings, parent)
+}
+
+// relink : Sets the Parent and PreviousSibling of siblings from their order
+func relink(siblings []*Node, parent *Node) {
+	for i, node := range siblings {
+		node.Parent = parent
+		node.DialogNode.Parent = nil
+		if parent != nil {
+			node.DialogNode.Parent = core.StringPtr(parent.ID())
+		}
+		node.DialogNode.PreviousSibling = nil
+		if i > 0 {
+			node.DialogNode.PreviousSibling = core.StringPtr(siblings[i-1].ID())
+		}
+	}
+}
+
+// ids : Returns the IDs of the nodes, sorted
+func (tree *Tree) ids() []string {
+	ids := make([]string, 0, len(tree.nodes))
+	for id := range tree.nodes {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+func containsNode(nodes []*Node, node *Node) bool {
+	for _, candidate := range nodes {
+		if candidate == node {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,197 @@
+/**
+ * (C) Copyright IBM Corp. 2021.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package dialog_test
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/IBM/go-sdk-core/v5/core"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/watson-developer-cloud/go-sdk/v2/assistantv1"
+	"github.com/watson-developer-cloud/go-sdk/v2/assistantv1/dialog"
+)
+
+// node : Returns a dialog node; fields are given as name=value pairs
+func node(id string, fields ...string) assistantv1.DialogNode {
+	result := assistantv1.DialogNode{DialogNode: core.StringPtr(id)}
+	for _, field := range fields {
+		parts := strings.SplitN(field, "=", 2)
+		value := core.StringPtr(parts[1])
+		switch parts[0] {
+		case "parent":
+			result.Parent = value
+		case "previous":
+			result.PreviousSibling = value
+		case "conditions":
+			result.Conditions = value
+		case "type":
+			result.Type = value
+		case "jump":
+			result.NextStep = &assistantv1.DialogNodeNextStep{Behavior: core.StringPtr("jump_to"), DialogNode: value, Selector: core.StringPtr("body")}
+		case "disabled":
+			result.Disabled = core.BoolPtr(parts[1] == "true")
+		}
+	}
+	return result
+}
+
+// outline : Returns the tree as indented IDs with their parent and previous sibling
+func outline(tree *dialog.Tree) string {
+	var lines []string
+	_ = tree.Walk(func(n *dialog.Node, depth int) error {
+		lines = append(lines, fmt.Sprintf("%s%s (%s<%s)", strings.Repeat("  ", depth), n.ID(),
+			value(n.DialogNode.Parent), value(n.DialogNode.PreviousSibling)))
+		return nil
+	})
+	return strings.Join(lines, "\n")
+}
+
+func value(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
+
+var _ = Describe(`Tree`, func() {
+	nodes := []assistantv1.DialogNode{
+		node("anything_else", "previous=help", "conditions=anything_else"),
+		node("welcome", "conditions=welcome"),
+		node("help", "previous=welcome", "conditions=#help"),
+		node("help_more", "parent=help", "previous=help_reply"),
+		node("help_reply", "parent=help"),
+	}
+
+	It(`Rebuild the hierarchy and sibling order`, func() {
+		tree, err := dialog.NewTree(nodes)
+		Expect(err).To(BeNil())
+		Expect(tree.Len()).To(Equal(5))
+		Expect(outline(tree)).To(Equal(`welcome (<)
+help (<welcome)
+  help_reply (help<)
+  help_more (help<help_reply)
+anything_else (<help)`))
+		Expect(tree.Node("help_more").Parent.ID()).To(Equal("help"))
+		Expect(tree.Validate()).To(BeEmpty())
+
+		var visited []string
+		Expect(tree.Walk(func(n *dialog.Node, depth int) error {
+			visited = append(visited, n.ID())
+			if n.ID() == "help" {
+				return dialog.SkipChildren
+			}
+			return nil
+		})).To(Succeed())
+		Expect(visited).To(Equal([]string{"welcome", "help", "anything_else"}))
+	})
+
+	It(`Reject duplicate IDs`, func() {
+		_, err := dialog.NewTree([]assistantv1.DialogNode{node("a"), node("a")})
+		Expect(err).ToNot(BeNil())
+	})
+
+	It(`Insert, move and delete nodes`, func() {
+		tree, err := dialog.NewTree(nodes)
+		Expect(err).To(BeNil())
+
+		_, err = tree.Insert(node("goodbye", "conditions=#goodbye"), "", "help")
+		Expect(err).To(BeNil())
+		_, err = tree.Insert(node("help_first"), "help", "")
+		Expect(err).To(BeNil())
+		Expect(outline(tree)).To(Equal(`welcome (<)
+help (<welcome)
+  help_first (help<)
+  help_reply (help<help_first)
+  help_more (help<help_reply)
+goodbye (<help)
+anything_else (<goodbye)`))
+
+		Expect(tree.Move("help_reply", "goodbye", "")).To(Succeed())
+		Expect(tree.Move("welcome", "", "anything_else")).To(Succeed())
+		Expect(outline(tree)).To(Equal(`help (<)
+  help_first (help<)
+  help_more (help<help_first)
+goodbye (<help)
+  help_reply (goodbye<)
+anything_else (<goodbye)
+welcome (<anything_else)`))
+
+		Expect(tree.Move("help", "help_more", "")).ToNot(Succeed())
+		Expect(tree.Move("help", "", "missing")).ToNot(Succeed())
+		Expect(tree.Node("help")).ToNot(BeNil())
+		_, err = tree.Insert(node("welcome"), "", "")
+		Expect(err).ToNot(BeNil())
+
+		Expect(tree.Delete("help")).To(Succeed())
+		Expect(tree.Node("help_first")).To(BeNil())
+		Expect(outline(tree)).To(Equal(`goodbye (<)
+  help_reply (goodbye<)
+anything_else (<goodbye)
+welcome (<anything_else)`))
+		Expect(tree.Nodes()).To(HaveLen(4))
+		Expect(tree.Validate()).To(BeEmpty())
+	})
+
+	It(`Report problems`, func() {
+		tree, err := dialog.NewTree([]assistantv1.DialogNode{
+			node("start", "jump=missing"),
+			node("start_child", "parent=start"),
+			node("lost", "parent=gone"),
+			node("a", "previous=b"),
+			node("b", "previous=a"),
+			node("c", "previous=start"),
+			node("d", "previous=start"),
+			node("e", "previous=nowhere"),
+			node("never", "previous=c", "conditions=false"),
+			node("target", "previous=never", "conditions=false"),
+			node("jumper", "previous=target", "jump=target"),
+			node("slot", "type=slot", "previous=off"),
+			node("frame", "type=frame", "previous=jumper"),
+			node("frame_slot", "type=slot", "parent=frame"),
+			node("bad_slot", "type=slot", "parent=start", "previous=start_child"),
+			node("off", "previous=frame", "disabled=true"),
+			node("off_child", "parent=off", "conditions=false"),
+		})
+		Expect(err).To(BeNil())
+
+		var found []string
+		for _, issue := range tree.Validate() {
+			found = append(found, issue.DialogNode+" "+issue.Kind)
+		}
+		Expect(found).To(Equal([]string{
+			"a sibling_cycle",
+			"b sibling_cycle",
+			"bad_slot invalid_parent",
+			"d sibling_conflict",
+			"e dangling_previous_sibling",
+			"lost orphan",
+			"never unreachable",
+			"slot missing_parent",
+			"start dangling_jump",
+			"start_child unreachable",
+		}))
+
+		// Fixing the structure fixes the issues
+		Expect(tree.Move("a", "", "off")).To(Succeed())
+		Expect(tree.Move("b", "", "a")).To(Succeed())
+		for _, issue := range tree.Validate() {
+			Expect(issue.DialogNode).ToNot(BeElementOf("a", "b"))
+		}
+	})
+})
@@ -0,0 +1,190 @@
+/**
+ * (C) Copyright IBM Corp. 2021.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package dialog
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/IBM/go-sdk-core/v5/core"
+	"github.com/watson-developer-cloud/go-sdk/v2/assistantv1"
+)
+
+// Kinds of Issue
+const (
+	// The parent of the node does not exist.
+	IssueKindOrphanConst = "orphan"
+
+	// The previous sibling of the node does not exist or has another parent.
+	IssueKindDanglingPreviousSiblingConst = "dangling_previous_sibling"
+
+	// The previous sibling of the node is also the previous sibling of another node.
+	IssueKindSiblingConflictConst = "sibling_conflict"
+
+	// The previous siblings of the node lead back to it.
+	IssueKindSiblingCycleConst = "sibling_cycle"
+
+	// The node jumps to a dialog node that does not exist.
+	IssueKindDanglingJumpConst = "dangling_jump"
+
+	// No conversation can reach the node.
+	IssueKindUnreachableConst = "unreachable"
+
+	// The node is a slot, handler or conditional response without a parent.
+	IssueKindMissingParentConst = "missing_parent"
+
+	// The parent of the node is of a type that cannot have such a child, such as a slot outside a frame.
+	IssueKindInvalidParentConst = "invalid_parent"
+)
+
+// Issue : A problem of a dialog found by Validate
+type Issue struct {
+	Kind       string `json:"kind"`
+	DialogNode string `json:"dialog_node"`
+	Message    string `json:"message"`
+}
+
+func (issue Issue) String() string {
+	return fmt.Sprintf("%s: %s: %s", issue.DialogNode, issue.Kind, issue.Message)
+}
+
+// childTypes are the node types that only exist as children, with the parent types they allow
+var childTypes = map[string][]string{
+	assistantv1.DialogNodeTypeSlotConst:              {assistantv1.DialogNodeTypeFrameConst},
+	assistantv1.DialogNodeTypeEventHandlerConst:      {assistantv1.DialogNodeTypeFrameConst, assistantv1.DialogNodeTypeSlotConst},
+	assistantv1.DialogNodeTypeResponseConditionConst: nil,
+}
+
+// Validate : Returns the problems of the dialog, sorted by dialog node and kind.
+//
+// Besides the problems of structure (orphans, previous siblings that are missing, shared or in a cycle), it reports
+// jumps to nodes that do not exist, slots, event handlers and conditional responses without a parent or under a
+// parent of the wrong type, and nodes that no conversation can reach. A node is reached when it is a root, a child of
+// a node that is reached and does not jump elsewhere, or the target of a jump from a node that is reached; nodes whose
+// condition is `false` are only reached by jumps. Slots, event handlers and conditional responses are reached with
+// their parent. Disabled nodes, and nodes under them, are not reported as unreachable.
+func (tree *Tree) Validate() []Issue {
+	// Building the tree again finds the structural problems as they are now, after any change
+	rebuilt, err := NewTree(tree.Nodes())
+	if err != nil {
+		return []Issue{{Message: err.Error()}}
+	}
+	issues := append([]Issue{}, rebuilt.buildIssues...)
+	orphaned := map[string]bool{}
+	for _, issue := range issues {
+		if issue.Kind == IssueKindOrphanConst {
+			orphaned[issue.DialogNode] = true
+		}
+	}
+
+	for _, id := range rebuilt.ids() {
+		node := rebuilt.nodes[id].DialogNode
+		if target, ok := jumpTarget(node); ok && rebuilt.nodes[target] == nil {
+			issues = append(issues, Issue{Kind: IssueKindDanglingJumpConst, DialogNode: id,
+				Message: fmt.Sprintf("jump target %q does not exist", target)})
+		}
+		allowed, isChildType := childTypes[core.StringNilMapper(node.Type)]
+		parent := core.StringNilMapper(node.Parent)
+		switch {
+		case !isChildType:
+		case parent == "":
+			issues = append(issues, Issue{Kind: IssueKindMissingParentConst, DialogNode: id,
+				Message: fmt.Sprintf("a %s node needs a parent", core.StringNilMapper(node.Type))})
+		case len(allowed) > 0 && rebuilt.nodes[parent] != nil && !contains(allowed, core.StringNilMapper(rebuilt.nodes[parent].DialogNode.Type)):
+			issues = append(issues, Issue{Kind: IssueKindInvalidParentConst, DialogNode: id,
+				Message: fmt.Sprintf("a %s node must be under a %s node", core.StringNilMapper(node.Type), strings.Join(allowed, " or "))})
+		}
+	}
+
+	reached := rebuilt.reachable()
+	_ = rebuilt.Walk(func(node *Node, depth int) error {
+		if isDisabled(node.DialogNode) {
+			return SkipChildren
+		}
+		if !reached[node.ID()] && !orphaned[node.ID()] {
+			issues = append(issues, Issue{Kind: IssueKindUnreachableConst, DialogNode: node.ID(),
+				Message: "no conversation can reach the node"})
+		}
+		return nil
+	})
+
+	sort.SliceStable(issues, func(i, j int) bool {
+		if issues[i].DialogNode != issues[j].DialogNode {
+			return issues[i].DialogNode < issues[j].DialogNode
+		}
+		return issues[i].Kind < issues[j].Kind
+	})
+	return issues
+}
+
+// reachable : Returns the IDs of the nodes that a conversation can reach
+func (tree *Tree) reachable() map[string]bool {
+	reached := map[string]bool{}
+	var queue []*Node
+	reach := func(node *Node, jumped bool) {
+		if node == nil || reached[node.ID()] || isDisabled(node.DialogNode) {
+			return
+		}
+		if !jumped && strings.TrimSpace(core.StringNilMapper(node.DialogNode.Conditions)) == "false" {
+			return
+		}
+		reached[node.ID()] = true
+		queue = append(queue, node)
+	}
+
+	for _, root := range tree.Roots {
+		reach(root, false)
+	}
+	for len(queue) > 0 {
+		node := queue[0]
+		queue = queue[1:]
+		target, jumps := jumpTarget(node.DialogNode)
+		if jumps {
+			reach(tree.nodes[target], true)
+		}
+		for _, child := range node.Children {
+			if _, isChildType := childTypes[core.StringNilMapper(child.DialogNode.Type)]; isChildType {
+				reach(child, true)
+			} else if !jumps {
+				reach(child, false)
+			}
+		}
+	}
+	return reached
+}
+
+// jumpTarget : Returns the node that a dialog node jumps to, if it jumps
+func jumpTarget(node *assistantv1.DialogNode) (string, bool) {
+	if node.NextStep == nil || core.StringNilMapper(node.NextStep.Behavior) != assistantv1.DialogNodeNextStepBehaviorJumpToConst {
+		return "", false
+	}
+	return core.StringNilMapper(node.NextStep.DialogNode), true
+}
+
+func isDisabled(node *assistantv1.DialogNode) bool {
+	return node.Disabled != nil && *node.Disabled
+}
+
+func contains(values []string, value string) bool {
+	for _, candidate := range values {
+		if candidate == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,142 @@
+/**
+ * (C) Copyright IBM Corp. 2021.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package expression_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/watson-developer-cloud/go-sdk/v2/assistantv1/expression"
+	"github.com/watson-developer-cloud/go-sdk/v2/assistantv1/workspace"
+)
+
+const bank = `{
+	"name": "Bank",
+	"language": "en",
+	"intents": [{"intent": "balance"}, {"intent": "transfer"}, {"intent": "General_Greetings"}],
+	"entities": [{"entity": "account", "values": [{"value": "savings"}, {"value": "checking"}, {"value": "joint account"}]}],
+	"dialog_nodes": [
+		{"dialog_node": "greet", "conditions": "#General_Greetings || welcome", "context": {"user_name": null},
+			"output": {"generic": [{"response_type": "text", "values": [{"text": "Hello $user_name, mail me at help@bank.com"}]}]}},
+		{"dialog_node": "balance", "conditions": "#balance && @account:savings", "previous_sibling": "greet",
+			"output": {"generic": [{"response_type": "text", "values": [{"text": "Your @acount balance is <? $balance.format('0.00') ?>"}]}]}},
+		{"dialog_node": "transfer", "type": "frame", "conditions": "#transfer && input.text.lenght() > 3", "previous_sibling": "balance"},
+		{"dialog_node": "amount", "type": "slot", "parent": "transfer", "variable": "$amount"},
+		{"dialog_node": "amount_handler", "type": "event_handler", "parent": "amount", "conditions": "@sys-number && $amount < 1000 &&"},
+		{"dialog_node": "target", "type": "slot", "parent": "transfer", "previous_sibling": "amount", "variable": "target account"},
+		{"dialog_node": "fallback", "conditions": "anything_else", "previous_sibling": "transfer",
+			"output": {"text": {"values": ["Sorry, I did not get that. $4 off your next #transfer!"]}}}
+	]
+}`
+
+var _ = Describe(`Parse`, func() {
+	It(`Parse expressions`, func() {
+		for _, source := range []string{
+			"#balance && (@account:savings || @account:(joint account))",
+			"intents[0].confidence > 0.8 ? true : false",
+			"input.text.toLowerCase().contains('hi') and not $done",
+			"entities['account']?.size() >= 2",
+			"T(java.lang.Math).max($a, 3)",
+			"{1, 2, 3}.contains($n) && {'a': 1}.has('a')",
+			"$items.?[price > 10].size() == 0",
+			"now().sameOrAfter($deadline) ?: 'later'",
+			"-$x * 2 % 3 != 1",
+			"'it''s' + \"quoted \\\" text\"",
+			"anything_else",
+		} {
+			_, err := expression.Parse(source)
+			Expect(err).To(BeNil(), source)
+		}
+	})
+
+	It(`Collect the identifiers`, func() {
+		parsed, err := expression.Parse("input.text.contains('x') && now().before($t) && entities.size() > 1")
+		Expect(err).To(BeNil())
+		Expect(parsed.Methods).To(HaveLen(3))
+		Expect(parsed.Methods[0].Name).To(Equal("contains"))
+		Expect(parsed.Methods[0].Offset).To(Equal(11))
+		Expect(parsed.Functions[0].Name).To(Equal("now"))
+		Expect(parsed.Roots).To(HaveLen(2))
+		Expect(parsed.Tokens[0].Kind).To(Equal(expression.TokenKindIdentifierConst))
+	})
+
+	It(`Report the position of syntax errors`, func() {
+		for source, offset := range map[string]int{
+			"#balance &&":          11,
+			"#balance & @account":  9,
+			"(#a || #b":            9,
+			"@account:(savings":    9,
+			"'unterminated":        0,
+			"# && #b":              0,
+			"$a == $b == $c":       9,
+			"input.text.":          11,
+			"input.text.(1)":       11,
+			"$a ? $b":              7,
+			"":                     0,
+			"#a #b":                3,
+			"input.text.contains(": 20,
+		} {
+			_, err := expression.Parse(source)
+			Expect(err).To(BeAssignableToTypeOf(&expression.SyntaxError{}), source)
+			Expect(err.(*expression.SyntaxError).Offset).To(Equal(offset), source)
+		}
+	})
+})
+
+var _ = Describe(`ValidateWorkspace`, func() {
+	It(`Report unknown references and syntax errors with their position`, func() {
+		ws, err := workspace.Unmarshal([]byte(bank))
+		Expect(err).To(BeNil())
+		var found []string
+		for _, issue := range expression.ValidateWorkspace(ws, nil) {
+			found = append(found, issue.String())
+		}
+		Expect(found).To(Equal([]string{
+			"amount_handler: conditions: offset 32: syntax: unexpected end of the expression",
+			"balance: output.generic[0].values[0].text: offset 5: unknown_entity: unknown entity @acount",
+			"balance: output.generic[0].values[0].text: offset 27: unknown_context_variable: no dialog node sets context variable $balance",
+			"target: variable: offset 0: invalid_variable: the variable of a slot must be a context variable, such as $name, not \"target account\"",
+			"transfer: conditions: offset 24: unsupported_method: unsupported method lenght",
+		}))
+	})
+
+	It(`Check references against a scope`, func() {
+		ws, err := workspace.Unmarshal([]byte(bank))
+		Expect(err).To(BeNil())
+		scope := expression.NewScope(ws)
+		scope.ContextVariables["balance"] = true
+
+		Expect(scope.CheckCondition("#balance && @account:(joint account) && $amount > 0")).To(BeEmpty())
+		issues := scope.CheckCondition("#balanse || @account:loan || @acount || $amout || foo || bar()")
+		var kinds []string
+		for _, issue := range issues {
+			kinds = append(kinds, issue.Kind)
+		}
+		Expect(kinds).To(Equal([]string{
+			expression.IssueKindUnknownIntentConst,
+			expression.IssueKindUnknownEntityValueConst,
+			expression.IssueKindUnknownEntityConst,
+			expression.IssueKindUnknownContextVariableConst,
+			expression.IssueKindUnknownIdentifierConst,
+			expression.IssueKindUnknownIdentifierConst,
+		}))
+		Expect(issues[1].Offset).To(Equal(12))
+		Expect(scope.CheckCondition("@sys-number:5 && @sys-date")).To(BeEmpty())
+		Expect(scope.CheckText("Total: <? $balance + ?>")).To(HaveLen(1))
+		Expect(scope.CheckText("Total: <? $balance")[0].Offset).To(Equal(7))
+		Expect(scope.CheckVariable("$amount")).To(BeEmpty())
+	})
+})
@@ -0,0 +1,376 @@
+/**
+ * (C) Copyright IBM Corp. 2021.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package expression
+
+import (
+	"fmt"
+)
+
+// Expression : A parsed expression
+type Expression struct {
+	// The expression as written.
+	Source string
+
+	Tokens []Token
+
+	// The identifiers called as methods, as `contains` in `input.text.contains('hi')`.
+	Methods []Token
+
+	// The identifiers called as functions, as `now` in `now()`.
+	Functions []Token
+
+	// The identifiers that start a path, as `input` in `input.text`.
+	Roots []Token
+}
+
+// keywords are the operators and literals of SpEL written as words
+var keywords = map[string]bool{
+	"and": true, "or": true, "not": true, "matches": true, "instanceof": true,
+	"eq": true, "ne": true, "lt": true, "gt": true, "le": true, "ge": true,
+	"true": true, "false": true, "null": true, "new": true, "T": true,
+}
+
+var comparisonOperators = map[string]bool{
+	"==": true, "!=": true, "<": true, ">": true, "<=": true, ">=": true,
+	"eq": true, "ne": true, "lt": true, "gt": true, "le": true, "ge": true,
+	"matches": true, "instanceof": true,
+}
+
+// Parse : Checks the syntax of an expression and returns its tokens and the identifiers it uses. The error, if any,
+// is a *SyntaxError.
+func Parse(expression string) (*Expression, error) {
+	tokens, err := Tokenize(expression)
+	if err != nil {
+		return nil, err
+	}
+	parser := &parser{expression: &Expression{Source: expression, Tokens: tokens}}
+	if len(tokens) == 0 {
+		return nil, &SyntaxError{Offset: 0, Message: "empty expression"}
+	}
+	if err = parser.parseExpression(); err != nil {
+		return nil, err
+	}
+	if parser.position < len(tokens) {
+		return nil, parser.unexpected()
+	}
+	return parser.expression, nil
+}
+
+// parser : A recursive descent parser of the expression language
+type parser struct {
+	expression *Expression
+	position   int
+}
+
+// peek : Returns the current token, or a token of no kind at the end of the expression
+func (parser *parser) peek() Token {
+	if parser.position < len(parser.expression.Tokens) {
+		return parser.expression.Tokens[parser.position]
+	}
+	return Token{Offset: len(parser.expression.Source)}
+}
+
+// peekAt : Returns the token after the current one by distance, or a token of no kind past the end
+func (parser *parser) peekAt(distance int) Token {
+	if parser.position+distance < len(parser.expression.Tokens) {
+		return parser.expression.Tokens[parser.position+distance]
+	}
+	return Token{Offset: len(parser.expression.Source)}
+}
+
+// is : Tells whether the current token is an operator or a keyword with one of the texts
+func (parser *parser) is(texts ...string) bool {
+	token := parser.peek()
+	if token.Kind != TokenKindOperatorConst && (token.Kind != TokenKindIdentifierConst || !keywords[token.Text]) {
+		return false
+	}
+	for _, text := range texts {
+		if token.Text == text {
+			return true
+		}
+	}
+	return false
+}
+
+func (parser *parser) next() Token {
+	token := parser.peek()
+	parser.position++
+	return token
+}
+
+// expect : Consumes an operator, or returns an error if the current token is another one
+func (parser *parser) expect(text string) error {
+	if !parser.is(text) {
+		token := parser.peek()
+		if token.Kind == "" {
+			return &SyntaxError{Offset: token.Offset, Message: fmt.Sprintf("missing %q at the end of the expression", text)}
+		}
+		return &SyntaxError{Offset: token.Offset, Message: fmt.Sprintf("expected %q, found %q", text, token.Text)}
+	}
+	parser.position++
+	return nil
+}
+
+func (parser *parser) unexpected() error {
+	token := parser.peek()
+	if token.Kind == "" {
+		return &SyntaxError{Offset: token.Offset, Message: "unexpected end of the expression"}
+	}
+	return &SyntaxError{Offset: token.Offset, Message: fmt.Sprintf("unexpected %q", token.Text)}
+}
+
+// parseExpression : expression = or [ "?" expression ":" expression | "?:" expression ]
+func (parser *parser) parseExpression() error {
+	if err := parser.parseBinary(0); err != nil {
+		return err
+	}
+	switch {
+	case parser.is("?"):
+		parser.next()
+		if err := parser.parseExpression(); err != nil {
+			return err
+		}
+		if err := parser.expect(":"); err != nil {
+			return err
+		}
+		return parser.parseExpression()
+	case parser.is("?:"):
+		parser.next()
+		return parser.parseExpression()
+	}
+	return nil
+}
+
+// binaryLevels are the binary operators by increasing precedence
+var binaryLevels = [][]string{
+	{"||", "or"},
+	{"&&", "and"},
+	{"==", "!=", "<", ">", "<=", ">=", "eq", "ne", "lt", "gt", "le", "ge", "matches", "instanceof"},
+	{"+", "-"},
+	{"*", "/", "%"},
+}
+
+// parseBinary : level = next-level { operator next-level }
+func (parser *parser) parseBinary(level int) error {
+	if level == len(binaryLevels) {
+		return parser.parseUnary()
+	}
+	if err := parser.parseBinary(level + 1); err != nil {
+		return err
+	}
+	for parser.is(binaryLevels[level]...) {
+		comparison := comparisonOperators[parser.peek().Text]
+		parser.next()
+		if err := parser.parseBinary(level + 1); err != nil {
+			return err
+		}
+		if comparison && parser.is(binaryLevels[level]...) {
+			return &SyntaxError{Offset: parser.peek().Offset, Message: "comparisons cannot be chained"}
+		}
+	}
+	return nil
+}
+
+// parseUnary : unary = ( "!" | "not" | "-" | "+" ) unary | postfix
+func (parser *parser) parseUnary() error {
+	if parser.is("!", "not", "-", "+") {
+		parser.next()
+		return parser.parseUnary()
+	}
+	return parser.parsePostfix()
+}
+
+// parsePostfix : postfix = primary { ( "." | "?." ) name [ arguments ] | ".?[" expression "]" | ".![" expression "]"
+// | "[" expression "]" }
+func (parser *parser) parsePostfix() error {
+	if err := parser.parsePrimary(); err != nil {
+		return err
+	}
+	for {
+		switch {
+		case parser.is(".", "?."):
+			parser.next()
+			if parser.is("?", "!") && parser.peekAt(1).Kind == TokenKindOperatorConst && parser.peekAt(1).Text == "[" {
+				parser.next()
+				if err := parser.parseIndex(); err != nil {
+					return err
+				}
+				continue
+			}
+			name := parser.peek()
+			if name.Kind != TokenKindIdentifierConst {
+				if name.Kind == "" {
+					return &SyntaxError{Offset: name.Offset, Message: "missing property or method name at the end of the expression"}
+				}
+				return &SyntaxError{Offset: name.Offset, Message: fmt.Sprintf("expected a property or method name, found %q", name.Text)}
+			}
+			parser.next()
+			if parser.is("(") {
+				parser.expression.Methods = append(parser.expression.Methods, name)
+				if err := parser.parseArguments(); err != nil {
+					return err
+				}
+			}
+		case parser.is("["):
+			if err := parser.parseIndex(); err != nil {
+				return err
+			}
+		default:
+			return nil
+		}
+	}
+}
+
+// parseIndex : index = "[" expression "]"
+func (parser *parser) parseIndex() error {
+	if err := parser.expect("["); err != nil {
+		return err
+	}
+	if err := parser.parseExpression(); err != nil {
+		return err
+	}
+	return parser.expect("]")
+}
+
+// parseArguments : arguments = "(" [ expression { "," expression } ] ")"
+func (parser *parser) parseArguments() error {
+	if err := parser.expect("("); err != nil {
+		return err
+	}
+	if parser.is(")") {
+		parser.next()
+		return nil
+	}
+	for {
+		if err := parser.parseExpression(); err != nil {
+			return err
+		}
+		if !parser.is(",") {
+			return parser.expect(")")
+		}
+		parser.next()
+	}
+}
+
+// parsePrimary : primary = literal | reference | "(" expression ")" | inline-list | inline-map | "T(" type ")" |
+// "new" type arguments | name [ arguments ]
+func (parser *parser) parsePrimary() error {
+	token := parser.peek()
+	switch token.Kind {
+	case TokenKindNumberConst, TokenKindStringConst, TokenKindIntentConst, TokenKindEntityConst, TokenKindContextVariableConst:
+		parser.next()
+		return nil
+
+	case TokenKindIdentifierConst:
+		switch token.Text {
+		case "true", "false", "null":
+			parser.next()
+			return nil
+		case "T":
+			if parser.peekAt(1).Text == "(" {
+				parser.next()
+				parser.next()
+				if err := parser.parseTypeName(); err != nil {
+					return err
+				}
+				return parser.expect(")")
+			}
+		case "new":
+			parser.next()
+			if err := parser.parseTypeName(); err != nil {
+				return err
+			}
+			return parser.parseArguments()
+		}
+		if keywords[token.Text] && token.Text != "T" {
+			return parser.unexpected()
+		}
+		parser.next()
+		if parser.is("(") {
+			parser.expression.Functions = append(parser.expression.Functions, token)
+			return parser.parseArguments()
+		}
+		parser.expression.Roots = append(parser.expression.Roots, token)
+		return nil
+
+	case TokenKindOperatorConst:
+		switch token.Text {
+		case "(":
+			parser.next()
+			if err := parser.parseExpression(); err != nil {
+				return err
+			}
+			return parser.expect(")")
+		case "{":
+			return parser.parseInline()
+		}
+	}
+	return parser.unexpected()
+}
+
+// parseInline : inline-list = "{" [ expression { "," expression } ] "}"; inline-map = "{" ( ":" | entry { ","
+// entry } ) "}" with entry = expression ":" expression
+func (parser *parser) parseInline() error {
+	if err := parser.expect("{"); err != nil {
+		return err
+	}
+	if parser.is(":") && parser.peekAt(1).Text == "}" {
+		parser.next()
+		parser.next()
+		return nil
+	}
+	if parser.is("}") {
+		parser.next()
+		return nil
+	}
+	isMap := false
+	for first := true; ; first = false {
+		if err := parser.parseExpression(); err != nil {
+			return err
+		}
+		if first {
+			isMap = parser.is(":")
+		}
+		if isMap {
+			if err := parser.expect(":"); err != nil {
+				return err
+			}
+			if err := parser.parseExpression(); err != nil {
+				return err
+			}
+		}
+		if !parser.is(",") {
+			return parser.expect("}")
+		}
+		parser.next()
+	}
+}
+
+// parseTypeName : type = name { "." name }
+func (parser *parser) parseTypeName() error {
+	for {
+		token := parser.peek()
+		if token.Kind != TokenKindIdentifierConst {
+			return &SyntaxError{Offset: token.Offset, Message: "expected a type name"}
+		}
+		parser.next()
+		if !parser.is(".") {
+			return nil
+		}
+		parser.next()
+	}
+}
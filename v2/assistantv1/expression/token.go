@@ -0,0 +1,238 @@
+/**
+ * (C) Copyright IBM Corp. 2021.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package expression checks the Watson Assistant expression language of Assistant v1 dialog nodes offline.
+//
+// Dialog node conditions, slot variables and the text of responses mix the shorthand syntax of the service (#intent,
+// @entity:value, $context_variable) with Spring Expression Language (SpEL), and the service only reports mistakes in
+// them at run time. Tokenize and Parse check the syntax of an expression and report the offset of the first mistake;
+// a Scope, built from a workspace, checks the intents, entities and context variables that expressions refer to and
+// the methods they call. ValidateWorkspace runs the checks over every dialog node of a workspace, such as one read by
+// the workspace package.
+package expression
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Kinds of Token
+const (
+	// An intent reference, `#name`.
+	TokenKindIntentConst = "intent"
+
+	// An entity reference, `@name`, `@name:value` or `@name:(value)`.
+	TokenKindEntityConst = "entity"
+
+	// A context variable reference, `$name`.
+	TokenKindContextVariableConst = "context_variable"
+
+	// An identifier, such as a root object, a property, a method or a keyword.
+	TokenKindIdentifierConst = "identifier"
+
+	// A number literal.
+	TokenKindNumberConst = "number"
+
+	// A string literal between single or double quotes.
+	TokenKindStringConst = "string"
+
+	// An operator or punctuation, such as `&&`, `==`, `(` or `.`.
+	TokenKindOperatorConst = "operator"
+)
+
+// Token : A token of an expression
+type Token struct {
+	Kind string `json:"kind"`
+
+	// The text of the token in the expression.
+	Text string `json:"text"`
+
+	// The name of the intent, entity or context variable that the token refers to.
+	Name string `json:"name,omitempty"`
+
+	// The entity value of an entity reference, if any.
+	Value string `json:"value,omitempty"`
+
+	// The byte offset of the token in the expression.
+	Offset int `json:"offset"`
+}
+
+// SyntaxError : A mistake in the syntax of an expression
+type SyntaxError struct {
+	// The byte offset of the mistake in the expression.
+	Offset int
+
+	Message string
+}
+
+func (err *SyntaxError) Error() string {
+	return fmt.Sprintf("offset %d: %s", err.Offset, err.Message)
+}
+
+// operators are the operators and punctuation of the expression language, longest first
+var operators = []string{
+	"&&", "||", "==", "!=", "<=", ">=", "?.", "?:",
+	"!", "<", ">", "+", "-", "*", "/", "%", "?", ":", ".", ",", "(", ")", "[", "]", "{", "}",
+}
+
+// Tokenize : Splits an expression into tokens
+func Tokenize(expression string) ([]Token, error) {
+	var tokens []Token
+	for offset := 0; offset < len(expression); {
+		r, size := utf8.DecodeRuneInString(expression[offset:])
+		if unicode.IsSpace(r) {
+			offset += size
+			continue
+		}
+		token, err := nextToken(expression, offset)
+		if err != nil {
+			return nil, err
+		}
+		tokens = append(tokens, token)
+		offset += len(token.Text)
+	}
+	return tokens, nil
+}
+
+// nextToken : Reads the token that starts at an offset of an expression
+func nextToken(expression string, offset int) (Token, error) {
+	rest := expression[offset:]
+	r, _ := utf8.DecodeRuneInString(rest)
+	switch {
+	case r == '#':
+		name := scanName(rest[1:], isIntentRune)
+		if name == "" {
+			return Token{}, &SyntaxError{Offset: offset, Message: "missing intent name after #"}
+		}
+		return Token{Kind: TokenKindIntentConst, Text: rest[:1+len(name)], Name: name, Offset: offset}, nil
+
+	case r == '@':
+		return entityToken(expression, offset)
+
+	case r == '$':
+		name := scanName(rest[1:], isIdentifierRune)
+		if name == "" {
+			return Token{}, &SyntaxError{Offset: offset, Message: "missing context variable name after $"}
+		}
+		return Token{Kind: TokenKindContextVariableConst, Text: rest[:1+len(name)], Name: name, Offset: offset}, nil
+
+	case r == '\'' || r == '"':
+		for i := 1; i < len(rest); i++ {
+			switch rest[i] {
+			case '\\':
+				i++
+			case byte(r):
+				if r == '\'' && i+1 < len(rest) && rest[i+1] == '\'' {
+					// SpEL escapes a single quote by doubling it
+					i++
+					continue
+				}
+				return Token{Kind: TokenKindStringConst, Text: rest[:i+1], Offset: offset}, nil
+			}
+		}
+		return Token{}, &SyntaxError{Offset: offset, Message: "unterminated string"}
+
+	case r >= '0' && r <= '9':
+		end := 0
+		for end < len(rest) && (rest[end] >= '0' && rest[end] <= '9' || rest[end] == '.' && end+1 < len(rest) && rest[end+1] >= '0' && rest[end+1] <= '9') {
+			end++
+		}
+		for end < len(rest) && strings.ContainsRune("lLdDfF", rune(rest[end])) {
+			end++
+		}
+		return Token{Kind: TokenKindNumberConst, Text: rest[:end], Offset: offset}, nil
+
+	case isIdentifierStart(r):
+		name := scanName(rest, isIdentifierRune)
+		return Token{Kind: TokenKindIdentifierConst, Text: name, Name: name, Offset: offset}, nil
+	}
+
+	for _, operator := range operators {
+		if strings.HasPrefix(rest, operator) {
+			return Token{Kind: TokenKindOperatorConst, Text: operator, Offset: offset}, nil
+		}
+	}
+	return Token{}, &SyntaxError{Offset: offset, Message: fmt.Sprintf("unexpected character %q", r)}
+}
+
+// entityToken : Reads an entity reference, with its optional value
+func entityToken(expression string, offset int) (Token, error) {
+	rest := expression[offset:]
+	name := scanName(rest[1:], isEntityRune)
+	if name == "" {
+		return Token{}, &SyntaxError{Offset: offset, Message: "missing entity name after @"}
+	}
+	token := Token{Kind: TokenKindEntityConst, Text: rest[:1+len(name)], Name: name, Offset: offset}
+	after := rest[len(token.Text):]
+	if !strings.HasPrefix(after, ":") {
+		return token, nil
+	}
+	if strings.HasPrefix(after, ":(") {
+		end := strings.IndexByte(after, ')')
+		if end < 0 {
+			return Token{}, &SyntaxError{Offset: offset + len(token.Text) + 1, Message: "unterminated entity value"}
+		}
+		token.Value = after[2:end]
+		if strings.TrimSpace(token.Value) == "" {
+			return Token{}, &SyntaxError{Offset: offset + len(token.Text) + 1, Message: "empty entity value"}
+		}
+		token.Text += after[:end+1]
+		return token, nil
+	}
+	value := scanName(after[1:], isEntityValueRune)
+	if value == "" {
+		// A colon that does not start a value belongs to a conditional expression, as in `a ? @x : @y`
+		return token, nil
+	}
+	token.Value = value
+	token.Text += after[:1+len(value)]
+	return token, nil
+}
+
+// scanName : Returns the longest prefix of s whose runes satisfy accept
+func scanName(s string, accept func(r rune) bool) string {
+	end := 0
+	for end < len(s) {
+		r, size := utf8.DecodeRuneInString(s[end:])
+		if !accept(r) {
+			break
+		}
+		end += size
+	}
+	return s[:end]
+}
+
+func isIdentifierStart(r rune) bool {
+	return r == '_' || unicode.IsLetter(r)
+}
+
+func isIdentifierRune(r rune) bool {
+	return r == '_' || unicode.IsLetter(r) || unicode.IsDigit(r)
+}
+
+func isIntentRune(r rune) bool {
+	return isIdentifierRune(r) || r == '-' || r == '.'
+}
+
+func isEntityRune(r rune) bool {
+	return isIdentifierRune(r) || r == '-'
+}
+
+func isEntityValueRune(r rune) bool {
+	return isIdentifierRune(r) || r == '-'
+}
@@ -0,0 +1,401 @@
+/**
+ * (C) Copyright IBM Corp. 2021.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package expression
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/IBM/go-sdk-core/v5/core"
+	"github.com/watson-developer-cloud/go-sdk/v2/assistantv1"
+)
+
+// Kinds of Issue
+const (
+	// The expression is not well formed.
+	IssueKindSyntaxConst = "syntax"
+
+	// The expression refers to an intent that the workspace does not have.
+	IssueKindUnknownIntentConst = "unknown_intent"
+
+	// The expression refers to an entity that the workspace does not have.
+	IssueKindUnknownEntityConst = "unknown_entity"
+
+	// The expression refers to a value that the entity does not have.
+	IssueKindUnknownEntityValueConst = "unknown_entity_value"
+
+	// The expression refers to a context variable that no dialog node sets.
+	IssueKindUnknownContextVariableConst = "unknown_context_variable"
+
+	// The expression calls a method that the expression language does not support.
+	IssueKindUnsupportedMethodConst = "unsupported_method"
+
+	// The expression uses a function or a root object that the expression language does not have.
+	IssueKindUnknownIdentifierConst = "unknown_identifier"
+
+	// The variable of a slot is not a context variable reference.
+	IssueKindInvalidVariableConst = "invalid_variable"
+)
+
+// Issue : A problem of an expression
+type Issue struct {
+	Kind string `json:"kind"`
+
+	// The dialog node of the expression, when validating a workspace.
+	DialogNode string `json:"dialog_node,omitempty"`
+
+	// The field of the dialog node that holds the expression, such as `conditions`, `variable` or
+	// `output.generic[0].values[1].text`, when validating a workspace.
+	Field string `json:"field,omitempty"`
+
+	// The byte offset of the problem in the field.
+	Offset int `json:"offset"`
+
+	Message string `json:"message"`
+}
+
+func (issue Issue) String() string {
+	if issue.DialogNode == "" {
+		return fmt.Sprintf("offset %d: %s: %s", issue.Offset, issue.Kind, issue.Message)
+	}
+	return fmt.Sprintf("%s: %s: offset %d: %s: %s", issue.DialogNode, issue.Field, issue.Offset, issue.Kind, issue.Message)
+}
+
+// DefaultMethods are the methods of the expression language, on strings, numbers, dates, arrays and objects
+var DefaultMethods = []string{
+	// Strings
+	"append", "charAt", "concat", "contains", "endsWith", "equals", "equalsIgnoreCase", "extract", "find", "getMatch",
+	"indexOf", "isEmpty", "isNumber", "lastIndexOf", "length", "matches", "replace", "replaceAll", "split",
+	"startsWith", "substring", "toJson", "toLowerCase", "toString", "toUpperCase", "trim",
+	// Numbers and java.lang.Math
+	"abs", "ceil", "floor", "format", "max", "min", "pow", "random", "round", "sqrt", "toDouble", "toInt", "toLong",
+	// Dates and times
+	"after", "before", "minusDays", "minusHours", "minusMinutes", "minusMonths", "minusSeconds", "minusYears",
+	"plusDays", "plusHours", "plusMinutes", "plusMonths", "plusSeconds", "plusYears", "reformatDateTime",
+	"sameMoment", "sameOrAfter", "sameOrBefore",
+	// Arrays
+	"add", "addAll", "clear", "containsIntent", "filter", "get", "getRandomItem", "join", "joinToArray", "remove",
+	"removeValue", "set", "size", "sort", "transform",
+	// Objects
+	"containsKey", "has", "keySet", "put",
+}
+
+// DefaultFunctions are the functions of the expression language that are called without an object
+var DefaultFunctions = []string{"now", "today"}
+
+// DefaultRoots are the objects and special conditions that a path of the expression language can start with
+var DefaultRoots = []string{
+	"anything_else", "context", "conversation_start", "entities", "input", "intents", "irrelevant", "output",
+	"welcome",
+}
+
+// DefaultContextVariables are the context variables that the service or the integrations set
+var DefaultContextVariables = []string{"conversation_id", "integrations", "metadata", "private", "system", "timezone"}
+
+// SystemEntities are the system entities of the service
+var SystemEntities = []string{
+	"sys-currency", "sys-date", "sys-location", "sys-number", "sys-percentage", "sys-person", "sys-time",
+}
+
+// Scope : The names that expressions can refer to. The maps can be changed, for example to add the context
+// variables that the client application sets.
+type Scope struct {
+	Intents map[string]bool
+
+	// The entities, with the names of their values.
+	Entities map[string]map[string]bool
+
+	ContextVariables map[string]bool
+	Methods          map[string]bool
+	Functions        map[string]bool
+	Roots            map[string]bool
+}
+
+// NewScope : Returns the scope of the expressions of a workspace: its intents and entities, the system entities, the
+// context variables that its dialog nodes set, and the defaults. The workspace may be nil.
+func NewScope(workspace *assistantv1.Workspace) *Scope {
+	scope := &Scope{
+		Intents:          map[string]bool{},
+		Entities:         map[string]map[string]bool{},
+		ContextVariables: set(DefaultContextVariables),
+		Methods:          set(DefaultMethods),
+		Functions:        set(DefaultFunctions),
+		Roots:            set(DefaultRoots),
+	}
+	for _, entity := range SystemEntities {
+		scope.Entities[entity] = nil
+	}
+	if workspace == nil {
+		return scope
+	}
+	for _, intent := range workspace.Intents {
+		scope.Intents[core.StringNilMapper(intent.Intent)] = true
+	}
+	for _, entity := range workspace.Entities {
+		values := map[string]bool{}
+		for _, value := range entity.Values {
+			values[core.StringNilMapper(value.Value)] = true
+		}
+		scope.Entities[core.StringNilMapper(entity.Entity)] = values
+	}
+	for _, node := range workspace.DialogNodes {
+		if node.Context != nil {
+			for name := range node.Context.GetProperties() {
+				scope.ContextVariables[name] = true
+			}
+		}
+		if variable := strings.TrimPrefix(core.StringNilMapper(node.Variable), "$"); variable != "" {
+			scope.ContextVariables[variable] = true
+		}
+		for _, action := range node.Actions {
+			variable := strings.TrimPrefix(strings.TrimPrefix(core.StringNilMapper(action.ResultVariable), "$"), "context.")
+			if variable != "" {
+				scope.ContextVariables[variable] = true
+			}
+		}
+	}
+	return scope
+}
+
+// CheckCondition : Returns the problems of the condition of a dialog node
+func (scope *Scope) CheckCondition(condition string) []Issue {
+	return scope.checkExpression(condition, 0)
+}
+
+// CheckVariable : Returns the problems of the variable of a slot, which must be a context variable reference
+func (scope *Scope) CheckVariable(variable string) []Issue {
+	tokens, err := Tokenize(variable)
+	if err != nil {
+		return []Issue{syntaxIssue(err, 0)}
+	}
+	if len(tokens) != 1 || tokens[0].Kind != TokenKindContextVariableConst {
+		return []Issue{{Kind: IssueKindInvalidVariableConst, Message: fmt.Sprintf("the variable of a slot must be a context variable, such as $name, not %q", variable)}}
+	}
+	return nil
+}
+
+// CheckText : Returns the problems of the text of a response, in which `$name` shows a context variable, `@name` an
+// entity and `<? ... ?>` the value of an expression
+func (scope *Scope) CheckText(text string) (issues []Issue) {
+	for offset := 0; offset < len(text); {
+		switch {
+		case strings.HasPrefix(text[offset:], "<?"):
+			end := strings.Index(text[offset+2:], "?>")
+			if end < 0 {
+				return append(issues, Issue{Kind: IssueKindSyntaxConst, Offset: offset, Message: "unterminated <? expression"})
+			}
+			issues = append(issues, scope.checkExpression(text[offset+2:offset+2+end], offset+2)...)
+			offset += end + 4
+
+		case (text[offset] == '$' || text[offset] == '@') && startsReference(text, offset):
+			token, err := nextToken(text, offset)
+			if err != nil {
+				offset++
+				continue
+			}
+			issues = append(issues, scope.checkToken(token, 0)...)
+			offset += len(token.Text)
+
+		default:
+			offset++
+		}
+	}
+	return
+}
+
+// startsReference : Tells whether the $ or @ at an offset of a text starts a reference, rather than being part of a
+// word, as in an email address, or of an amount, as in $5
+func startsReference(text string, offset int) bool {
+	if offset > 0 && (isIdentifierRune(rune(text[offset-1])) || text[offset-1] == '.') {
+		return false
+	}
+	return offset+1 < len(text) && isIdentifierStart(rune(text[offset+1]))
+}
+
+// checkExpression : Returns the problems of an expression found at a base offset of its field
+func (scope *Scope) checkExpression(source string, base int) (issues []Issue) {
+	expression, err := Parse(source)
+	if err != nil {
+		return []Issue{syntaxIssue(err, base)}
+	}
+	for _, token := range expression.Tokens {
+		issues = append(issues, scope.checkToken(token, base)...)
+	}
+	for _, method := range expression.Methods {
+		if !scope.Methods[method.Name] {
+			issues = append(issues, Issue{Kind: IssueKindUnsupportedMethodConst, Offset: base + method.Offset, Message: fmt.Sprintf("unsupported method %s", method.Name)})
+		}
+	}
+	for _, function := range expression.Functions {
+		if !scope.Functions[function.Name] {
+			issues = append(issues, Issue{Kind: IssueKindUnknownIdentifierConst, Offset: base + function.Offset, Message: fmt.Sprintf("unknown function %s", function.Name)})
+		}
+	}
+	for _, root := range expression.Roots {
+		if !scope.Roots[root.Name] {
+			issues = append(issues, Issue{Kind: IssueKindUnknownIdentifierConst, Offset: base + root.Offset, Message: fmt.Sprintf("unknown identifier %s", root.Name)})
+		}
+	}
+	sort.SliceStable(issues, func(i, j int) bool {
+		return issues[i].Offset < issues[j].Offset
+	})
+	return
+}
+
+// checkToken : Returns the problems of an intent, entity or context variable reference
+func (scope *Scope) checkToken(token Token, base int) []Issue {
+	offset := base + token.Offset
+	switch token.Kind {
+	case TokenKindIntentConst:
+		if !scope.Intents[token.Name] {
+			return []Issue{{Kind: IssueKindUnknownIntentConst, Offset: offset, Message: fmt.Sprintf("unknown intent #%s", token.Name)}}
+		}
+	case TokenKindEntityConst:
+		values, found := scope.Entities[token.Name]
+		if !found {
+			return []Issue{{Kind: IssueKindUnknownEntityConst, Offset: offset, Message: fmt.Sprintf("unknown entity @%s", token.Name)}}
+		}
+		if token.Value != "" && values != nil && !values[token.Value] {
+			return []Issue{{Kind: IssueKindUnknownEntityValueConst, Offset: offset, Message: fmt.Sprintf("entity @%s has no value %q", token.Name, token.Value)}}
+		}
+	case TokenKindContextVariableConst:
+		if !scope.ContextVariables[token.Name] {
+			return []Issue{{Kind: IssueKindUnknownContextVariableConst, Offset: offset, Message: fmt.Sprintf("no dialog node sets context variable $%s", token.Name)}}
+		}
+	}
+	return nil
+}
+
+// ValidateWorkspace : Returns the problems of the conditions, slot variables and response texts of the dialog nodes
+// of a workspace, in dialog node order. The scope may be nil, to use the one that NewScope returns for the workspace.
+func ValidateWorkspace(workspace *assistantv1.Workspace, scope *Scope) (issues []Issue) {
+	if scope == nil {
+		scope = NewScope(workspace)
+	}
+	if workspace == nil {
+		return nil
+	}
+	nodes := append([]assistantv1.DialogNode{}, workspace.DialogNodes...)
+	sort.SliceStable(nodes, func(i, j int) bool {
+		return core.StringNilMapper(nodes[i].DialogNode) < core.StringNilMapper(nodes[j].DialogNode)
+	})
+	for i := range nodes {
+		issues = append(issues, scope.CheckDialogNode(&nodes[i])...)
+	}
+	return
+}
+
+// CheckDialogNode : Returns the problems of the condition, slot variable and response texts of a dialog node, such
+// as one that a workspace diff adds or modifies
+func (scope *Scope) CheckDialogNode(node *assistantv1.DialogNode) (issues []Issue) {
+	add := func(field string, found []Issue) {
+		for _, issue := range found {
+			issue.DialogNode, issue.Field = core.StringNilMapper(node.DialogNode), field
+			issues = append(issues, issue)
+		}
+	}
+	if node.Conditions != nil {
+		add("conditions", scope.CheckCondition(*node.Conditions))
+	}
+	if node.Variable != nil {
+		add("variable", scope.CheckVariable(*node.Variable))
+	}
+	if node.Output != nil {
+		for _, text := range outputTexts(node.Output) {
+			add(text.field, scope.CheckText(text.text))
+		}
+	}
+	return
+}
+
+// outputText : A text of a dialog node output, with its path in the output
+type outputText struct {
+	field string
+	text  string
+}
+
+// outputTexts : Returns the texts of a dialog node output that the service shows to users: the values, titles,
+// descriptions and option labels of the generic responses, and the legacy text output
+func outputTexts(output *assistantv1.DialogNodeOutput) (texts []outputText) {
+	data, err := json.Marshal(output)
+	if err != nil {
+		return nil
+	}
+	var raw map[string]interface{}
+	if err = json.Unmarshal(data, &raw); err != nil {
+		return nil
+	}
+
+	addString := func(field string, value interface{}) {
+		if text, ok := value.(string); ok {
+			texts = append(texts, outputText{field: field, text: text})
+		}
+	}
+	addValues := func(field string, values interface{}) {
+		list, _ := values.([]interface{})
+		for i, value := range list {
+			if object, ok := value.(map[string]interface{}); ok {
+				addString(fmt.Sprintf("%s[%d].text", field, i), object["text"])
+			} else {
+				addString(fmt.Sprintf("%s[%d]", field, i), value)
+			}
+		}
+	}
+
+	generic, _ := raw["generic"].([]interface{})
+	for i, item := range generic {
+		response, ok := item.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		field := fmt.Sprintf("output.generic[%d]", i)
+		addValues(field+".values", response["values"])
+		addString(field+".title", response["title"])
+		addString(field+".description", response["description"])
+		options, _ := response["options"].([]interface{})
+		for j, option := range options {
+			if object, ok := option.(map[string]interface{}); ok {
+				addString(fmt.Sprintf("%s.options[%d].label", field, j), object["label"])
+			}
+		}
+	}
+
+	switch legacy := raw["text"].(type) {
+	case string:
+		addString("output.text", legacy)
+	case map[string]interface{}:
+		addValues("output.text.values", legacy["values"])
+	}
+	return
+}
+
+func syntaxIssue(err error, base int) Issue {
+	if syntaxError, ok := err.(*SyntaxError); ok {
+		return Issue{Kind: IssueKindSyntaxConst, Offset: base + syntaxError.Offset, Message: syntaxError.Message}
+	}
+	return Issue{Kind: IssueKindSyntaxConst, Offset: base, Message: err.Error()}
+}
+
+func set(names []string) map[string]bool {
+	members := make(map[string]bool, len(names))
+	for _, name := range names {
+		members[name] = true
+	}
+	return members
+}
@@ -0,0 +1,210 @@
+/**
+ * (C) Copyright IBM Corp. 2021.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package assistantv1
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// Constants associated with the fields of log events that filters commonly use.
+const (
+	LogFilterFieldLanguageConst          = "language"
+	LogFilterFieldWorkspaceIDConst       = "workspace_id"
+	LogFilterFieldAssistantIDConst       = "request.context.system.assistant_id"
+	LogFilterFieldDeploymentConst        = "request.context.metadata.deployment"
+	LogFilterFieldRequestTimestampConst  = "request_timestamp"
+	LogFilterFieldResponseTimestampConst = "response_timestamp"
+	LogFilterFieldInputTextConst         = "request.input.text"
+	LogFilterFieldTopIntentConst         = "response.top_intent"
+)
+
+// Constants associated with the operators of the log filter query language.
+const (
+	LogFilterOperatorEqualsConst         = "::"
+	LogFilterOperatorNotEqualsConst      = "::!"
+	LogFilterOperatorContainsConst       = ":"
+	LogFilterOperatorNotContainsConst    = ":!"
+	LogFilterOperatorLessConst           = "<"
+	LogFilterOperatorLessOrEqualConst    = "<="
+	LogFilterOperatorGreaterConst        = ">"
+	LogFilterOperatorGreaterOrEqualConst = ">="
+)
+
+// logFilterTimestampLayout : The layout of timestamps in log filters
+const logFilterTimestampLayout = "2006-01-02T15:04:05.000Z"
+
+var logFilterOperators = map[string]bool{
+	LogFilterOperatorEqualsConst:         true,
+	LogFilterOperatorNotEqualsConst:      true,
+	LogFilterOperatorContainsConst:       true,
+	LogFilterOperatorNotContainsConst:    true,
+	LogFilterOperatorLessConst:           true,
+	LogFilterOperatorLessOrEqualConst:    true,
+	LogFilterOperatorGreaterConst:        true,
+	LogFilterOperatorGreaterOrEqualConst: true,
+}
+
+var logFilterFieldPattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*(\.[A-Za-z_][A-Za-z0-9_]*)*$`)
+
+// logFilterEscaper : Escapes the characters that have a meaning in the filter query language
+var logFilterEscaper = strings.NewReplacer(`\`, `\\`, `,`, `\,`, `|`, `\|`, `"`, `\"`, `(`, `\(`, `)`, `\)`)
+
+// LogFilter : Builds a filter for ListLogs and ListAllLogs in the filter query language, as in
+// `language::en,request.context.metadata.deployment::prod`. The conditions are joined with AND; AnyOf joins the
+// alternatives of a condition with OR. Mistakes, such as an unknown operator or a malformed field name, are kept and
+// returned by Build.
+type LogFilter struct {
+	conditions []string
+	scoped     bool
+	language   bool
+	err        error
+}
+
+// NewLogFilter : Returns an empty log filter
+func NewLogFilter() *LogFilter {
+	return &LogFilter{}
+}
+
+// Where : Adds a condition on a field of the log events
+func (filter *LogFilter) Where(field string, operator string, value string) *LogFilter {
+	return filter.AnyOf(field, operator, value)
+}
+
+// AnyOf : Adds a condition that holds when the field matches any of the values
+func (filter *LogFilter) AnyOf(field string, operator string, values ...string) *LogFilter {
+	if filter.err != nil {
+		return filter
+	}
+	if !logFilterFieldPattern.MatchString(field) {
+		filter.err = fmt.Errorf("invalid log filter field %q", field)
+		return filter
+	}
+	if !logFilterOperators[operator] {
+		filter.err = fmt.Errorf("invalid log filter operator %q for field %s", operator, field)
+		return filter
+	}
+	if len(values) == 0 {
+		filter.err = fmt.Errorf("no value for log filter field %s", field)
+		return filter
+	}
+	alternatives := make([]string, len(values))
+	for i, value := range values {
+		if value == "" {
+			filter.err = fmt.Errorf("empty value for log filter field %s", field)
+			return filter
+		}
+		alternatives[i] = field + operator + logFilterEscaper.Replace(value)
+	}
+	filter.conditions = append(filter.conditions, strings.Join(alternatives, "|"))
+	if operator == LogFilterOperatorEqualsConst {
+		switch field {
+		case LogFilterFieldLanguageConst:
+			filter.language = true
+		case LogFilterFieldWorkspaceIDConst, LogFilterFieldAssistantIDConst, LogFilterFieldDeploymentConst:
+			filter.scoped = true
+		}
+	}
+	return filter
+}
+
+// Language : Keeps the events of workspaces in one of the languages
+func (filter *LogFilter) Language(languages ...string) *LogFilter {
+	return filter.AnyOf(LogFilterFieldLanguageConst, LogFilterOperatorEqualsConst, languages...)
+}
+
+// Workspace : Keeps the events of one of the workspaces
+func (filter *LogFilter) Workspace(workspaceIDs ...string) *LogFilter {
+	return filter.AnyOf(LogFilterFieldWorkspaceIDConst, LogFilterOperatorEqualsConst, workspaceIDs...)
+}
+
+// Assistant : Keeps the events of one of the assistants
+func (filter *LogFilter) Assistant(assistantIDs ...string) *LogFilter {
+	return filter.AnyOf(LogFilterFieldAssistantIDConst, LogFilterOperatorEqualsConst, assistantIDs...)
+}
+
+// Deployment : Keeps the events whose requests carry one of the deployments in their context metadata
+func (filter *LogFilter) Deployment(deployments ...string) *LogFilter {
+	return filter.AnyOf(LogFilterFieldDeploymentConst, LogFilterOperatorEqualsConst, deployments...)
+}
+
+// Since : Keeps the events answered at or after a time
+func (filter *LogFilter) Since(since time.Time) *LogFilter {
+	return filter.Where(LogFilterFieldResponseTimestampConst, LogFilterOperatorGreaterOrEqualConst, FormatLogFilterTime(since))
+}
+
+// Until : Keeps the events answered before a time
+func (filter *LogFilter) Until(until time.Time) *LogFilter {
+	return filter.Where(LogFilterFieldResponseTimestampConst, LogFilterOperatorLessConst, FormatLogFilterTime(until))
+}
+
+// Between : Keeps the events answered at or after from and before to
+func (filter *LogFilter) Between(from time.Time, to time.Time) *LogFilter {
+	if !from.Before(to) && filter.err == nil {
+		filter.err = fmt.Errorf("empty log filter time range from %s to %s", FormatLogFilterTime(from), FormatLogFilterTime(to))
+		return filter
+	}
+	return filter.Since(from).Until(to)
+}
+
+// Build : Returns the filter in the filter query language, or the first mistake made while building it
+func (filter *LogFilter) Build() (string, error) {
+	if filter.err != nil {
+		return "", filter.err
+	}
+	if len(filter.conditions) == 0 {
+		return "", fmt.Errorf("empty log filter")
+	}
+	return strings.Join(filter.conditions, ","), nil
+}
+
+// BuildForAllLogs : Returns the filter like Build, checking that it has the conditions that ListAllLogs requires: an
+// exact match on the language and on the workspace, assistant or deployment
+func (filter *LogFilter) BuildForAllLogs() (string, error) {
+	built, err := filter.Build()
+	if err != nil {
+		return "", err
+	}
+	if !filter.language || !filter.scoped {
+		return "", fmt.Errorf("a filter for ListAllLogs needs an exact match on %s and on %s, %s or %s",
+			LogFilterFieldLanguageConst, LogFilterFieldWorkspaceIDConst, LogFilterFieldAssistantIDConst, LogFilterFieldDeploymentConst)
+	}
+	return built, nil
+}
+
+// String : Returns the filter in the filter query language, or a description of the mistake made while building it
+func (filter *LogFilter) String() string {
+	built, err := filter.Build()
+	if err != nil {
+		return fmt.Sprintf("invalid log filter: %s", err)
+	}
+	return built
+}
+
+// clone : Returns a copy of the filter that further conditions do not affect
+func (filter *LogFilter) clone() *LogFilter {
+	copied := *filter
+	copied.conditions = append([]string{}, filter.conditions...)
+	return &copied
+}
+
+// FormatLogFilterTime : Returns a time in the form that log filters use for timestamps
+func FormatLogFilterTime(t time.Time) string {
+	return t.UTC().Format(logFilterTimestampLayout)
+}
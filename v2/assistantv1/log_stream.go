@@ -0,0 +1,190 @@
+/**
+ * (C) Copyright IBM Corp. 2021.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package assistantv1
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"time"
+
+	"github.com/IBM/go-sdk-core/v5/core"
+)
+
+// The defaults of the fields of LogStream.
+const (
+	DefaultLogStreamPollInterval = 10 * time.Second
+	DefaultLogStreamOverlap      = 30 * time.Second
+	DefaultLogStreamPageLimit    = 100
+)
+
+// LogStream : Tails the log events of all the workspaces that match a filter, as returned by StreamLogs. Set its
+// fields before the first call to Next.
+type LogStream struct {
+	// How long to wait between queries once all the known events were returned.
+	PollInterval time.Duration
+
+	// How far before the latest event seen to query again, to catch events that the service logs late. The events of
+	// the overlap already returned are recognized by their LogID and skipped. No query starts before since.
+	Overlap time.Duration
+
+	// The number of events to get per call.
+	PageLimit int64
+
+	ctx        context.Context
+	service    *AssistantV1
+	filter     *LogFilter
+	since      time.Time
+	lowerBound time.Time
+	seen       map[string]time.Time
+	pending    []Log
+	current    *Log
+	polled     bool
+	err        error
+}
+
+// StreamLogs : Returns a stream of the log events of all the workspaces that match the filter and were answered at or
+// after since. The filter must have the conditions that ListAllLogs requires; the stream adds the lower bound on
+// response_timestamp itself, advancing it as events arrive. The stream ends when the context ends or a call fails.
+func (assistant *AssistantV1) StreamLogs(ctx context.Context, filter *LogFilter, since time.Time) *LogStream {
+	stream := &LogStream{
+		PollInterval: DefaultLogStreamPollInterval,
+		Overlap:      DefaultLogStreamOverlap,
+		PageLimit:    DefaultLogStreamPageLimit,
+		ctx:          ctx,
+		service:      assistant,
+		since:        since,
+		lowerBound:   since,
+		seen:         map[string]time.Time{},
+	}
+	if stream.err = core.ValidateNotNil(filter, "filter cannot be nil"); stream.err == nil {
+		stream.filter = filter.clone()
+		_, stream.err = stream.filter.BuildForAllLogs()
+	}
+	return stream
+}
+
+// Next : Waits for the next new log event. Returns false when the context ends or a call fails; Err tells why.
+func (stream *LogStream) Next() bool {
+	for len(stream.pending) == 0 {
+		if stream.err != nil {
+			return false
+		}
+		if stream.polled {
+			timer := time.NewTimer(stream.PollInterval)
+			select {
+			case <-stream.ctx.Done():
+				timer.Stop()
+				stream.err = stream.ctx.Err()
+				return false
+			case <-timer.C:
+			}
+		}
+		stream.err = stream.poll()
+		stream.polled = true
+	}
+	stream.current = &stream.pending[0]
+	stream.pending = stream.pending[1:]
+	return true
+}
+
+// Value : Returns the current log event; valid after Next returns true
+func (stream *LogStream) Value() *Log {
+	return stream.current
+}
+
+// Err : Returns the error that ended the stream
+func (stream *LogStream) Err() error {
+	return stream.err
+}
+
+// ExportJSONLines : Writes the events of the stream to a writer as JSON Lines, one event per line, until the stream
+// ends, and returns the number of events written. The context ending is the normal end of an export and is not
+// returned as an error.
+func (stream *LogStream) ExportJSONLines(writer io.Writer) (count int, err error) {
+	encoder := json.NewEncoder(writer)
+	for stream.Next() {
+		if err = encoder.Encode(stream.Value()); err != nil {
+			return
+		}
+		count++
+	}
+	if err = stream.Err(); stream.ctx.Err() != nil {
+		err = nil
+	}
+	return
+}
+
+// poll : Queries the events answered since the lower bound, less the overlap but not before since, and queues the new
+// ones
+func (stream *LogStream) poll() error {
+	from := stream.lowerBound.Add(-stream.Overlap)
+	if from.Before(stream.since) {
+		from = stream.since
+	}
+	filter, err := stream.filter.clone().Since(from).Build()
+	if err != nil {
+		return err
+	}
+	options := &ListAllLogsOptions{
+		Filter:    core.StringPtr(filter),
+		Sort:      core.StringPtr("request_timestamp"),
+		PageLimit: core.Int64Ptr(stream.PageLimit),
+	}
+	latest := stream.lowerBound
+	iterator := stream.service.NewInstanceLogIterator(stream.ctx, options)
+	for iterator.Next() {
+		event := iterator.Value()
+		if event.LogID == nil {
+			continue
+		}
+		responded, err := time.Parse(time.RFC3339Nano, core.StringNilMapper(event.ResponseTimestamp))
+		if err != nil {
+			responded = latest
+		}
+		if _, seen := stream.seen[*event.LogID]; seen {
+			continue
+		}
+		stream.seen[*event.LogID] = responded
+		stream.pending = append(stream.pending, *event)
+		if responded.After(latest) {
+			latest = responded
+		}
+	}
+	if err := iterator.Err(); err != nil {
+		return err
+	}
+
+	stream.lowerBound = latest
+	for logID, responded := range stream.seen {
+		if responded.Before(latest.Add(-stream.Overlap)) {
+			delete(stream.seen, logID)
+		}
+	}
+	return nil
+}
+
+// WriteLogsJSONLines : Writes log events to a writer as JSON Lines, one event per line
+func WriteLogsJSONLines(writer io.Writer, logs []Log) error {
+	encoder := json.NewEncoder(writer)
+	for i := range logs {
+		if err := encoder.Encode(&logs[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
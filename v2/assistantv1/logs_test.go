@@ -0,0 +1,185 @@
+/**
+ * (C) Copyright IBM Corp. 2021.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package assistantv1_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/IBM/go-sdk-core/v5/core"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/watson-developer-cloud/go-sdk/v2/assistantv1"
+)
+
+var _ = Describe(`LogFilter`, func() {
+	It(`Build filters`, func() {
+		from := time.Date(2021, 6, 1, 0, 0, 0, 0, time.UTC)
+		filter := assistantv1.NewLogFilter().
+			Language("en").
+			Deployment("prod", "staging").
+			Where(assistantv1.LogFilterFieldInputTextConst, assistantv1.LogFilterOperatorContainsConst, "hi, there").
+			Between(from, from.Add(24*time.Hour))
+		built, err := filter.BuildForAllLogs()
+		Expect(err).To(BeNil())
+		Expect(built).To(Equal(`language::en,` +
+			`request.context.metadata.deployment::prod|request.context.metadata.deployment::staging,` +
+			`request.input.text:hi\, there,` +
+			`response_timestamp>=2021-06-01T00:00:00.000Z,response_timestamp<2021-06-02T00:00:00.000Z`))
+		Expect(filter.String()).To(Equal(built))
+	})
+
+	It(`Report mistakes`, func() {
+		_, err := assistantv1.NewLogFilter().Where("language", "=", "en").Build()
+		Expect(err).To(MatchError(ContainSubstring(`operator "="`)))
+		_, err = assistantv1.NewLogFilter().Where("request..input", "::", "en").Language("en").Build()
+		Expect(err).To(MatchError(ContainSubstring(`field "request..input"`)))
+		_, err = assistantv1.NewLogFilter().Build()
+		Expect(err).ToNot(BeNil())
+		now := time.Now()
+		_, err = assistantv1.NewLogFilter().Language("en").Between(now, now).Build()
+		Expect(err).To(MatchError(ContainSubstring("empty log filter time range")))
+		_, err = assistantv1.NewLogFilter().Language("en").BuildForAllLogs()
+		Expect(err).To(MatchError(ContainSubstring("needs an exact match")))
+		_, err = assistantv1.NewLogFilter().Language("en").Where("workspace_id", ":", "ws").BuildForAllLogs()
+		Expect(err).To(MatchError(ContainSubstring("needs an exact match")))
+	})
+})
+
+// loggedEvents : A stand-in for ListAllLogs that serves its events answered at or after the lower bound of the filter
+type loggedEvents struct {
+	mutex   sync.Mutex
+	events  []string
+	filters []string
+}
+
+var lowerBoundPattern = regexp.MustCompile(`response_timestamp>=([^,]+)`)
+
+func (l *loggedEvents) add(logID string, responded time.Time) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	l.events = append(l.events, fmt.Sprintf(`{"log_id": "%s", "request_timestamp": "%s", "response_timestamp": "%s", "workspace_id": "ws1", "language": "en"}`,
+		logID, responded.Format(time.RFC3339Nano), responded.Format(time.RFC3339Nano)))
+}
+
+func (l *loggedEvents) ServeHTTP(w http.ResponseWriter, request *http.Request) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	filter := request.URL.Query().Get("filter")
+	l.filters = append(l.filters, filter)
+	lowerBound, _ := time.Parse(time.RFC3339Nano, lowerBoundPattern.FindStringSubmatch(filter)[1])
+	var matching []string
+	for _, event := range l.events {
+		var fields map[string]string
+		_ = json.Unmarshal([]byte(event), &fields)
+		responded, _ := time.Parse(time.RFC3339Nano, fields["response_timestamp"])
+		if !responded.Before(lowerBound) {
+			matching = append(matching, event)
+		}
+	}
+	w.Header().Set("Content-Type", "application/json")
+	fmt.Fprintf(w, `{"logs": [%s], "pagination": {}}`, strings.Join(matching, ","))
+}
+
+var _ = Describe(`StreamLogs`, func() {
+	var standIn *loggedEvents
+	var testServer *httptest.Server
+	var assistantService *assistantv1.AssistantV1
+	start := time.Date(2021, 6, 1, 12, 0, 0, 0, time.UTC)
+
+	BeforeEach(func() {
+		standIn = &loggedEvents{}
+		testServer = httptest.NewServer(standIn)
+		var err error
+		assistantService, err = assistantv1.NewAssistantV1(&assistantv1.AssistantV1Options{
+			URL:           testServer.URL,
+			Version:       core.StringPtr("2021-06-14"),
+			Authenticator: &core.NoAuthAuthenticator{},
+		})
+		Expect(err).To(BeNil())
+	})
+	AfterEach(func() {
+		testServer.Close()
+	})
+
+	It(`Tail new events once each`, func() {
+		standIn.add("a", start.Add(-time.Minute))
+		standIn.add("early", start.Add(-2*time.Second))
+		standIn.add("b", start.Add(time.Second))
+		standIn.add("c", start.Add(2*time.Second))
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		stream := assistantService.StreamLogs(ctx, assistantv1.NewLogFilter().Language("en").Workspace("ws1"), start)
+		stream.PollInterval = 10 * time.Millisecond
+		stream.Overlap = time.Second
+
+		var logIDs []string
+		next := func() {
+			Expect(stream.Next()).To(BeTrue())
+			logIDs = append(logIDs, *stream.Value().LogID)
+		}
+		next()
+		next()
+		standIn.add("d", start.Add(time.Second))
+		standIn.add("e", start.Add(3*time.Second))
+		next()
+		next()
+		Expect(logIDs).To(Equal([]string{"b", "c", "d", "e"}))
+
+		cancel()
+		Expect(stream.Next()).To(BeFalse())
+		Expect(stream.Err()).To(Equal(context.Canceled))
+		Expect(standIn.filters[0]).To(Equal("language::en,workspace_id::ws1,response_timestamp>=2021-06-01T12:00:00.000Z"))
+		Expect(standIn.filters[len(standIn.filters)-1]).To(HaveSuffix("response_timestamp>=2021-06-01T12:00:01.000Z"))
+	})
+
+	It(`Refuse filters that ListAllLogs does not accept`, func() {
+		stream := assistantService.StreamLogs(context.Background(), assistantv1.NewLogFilter().Language("en"), start)
+		Expect(stream.Next()).To(BeFalse())
+		Expect(stream.Err()).ToNot(BeNil())
+		Expect(standIn.filters).To(BeEmpty())
+	})
+
+	It(`Export events as JSON Lines`, func() {
+		standIn.add("a", start)
+		standIn.add("b", start.Add(time.Second))
+		ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+		defer cancel()
+		stream := assistantService.StreamLogs(ctx, assistantv1.NewLogFilter().Language("en").Assistant("as1"), start)
+		stream.PollInterval = 20 * time.Millisecond
+		var buffer bytes.Buffer
+		count, err := stream.ExportJSONLines(&buffer)
+		Expect(err).To(BeNil())
+		Expect(count).To(Equal(2))
+		lines := strings.Split(strings.TrimSpace(buffer.String()), "\n")
+		Expect(lines).To(HaveLen(2))
+		Expect(lines[1]).To(ContainSubstring(`"log_id":"b"`))
+
+		buffer.Reset()
+		logs := []assistantv1.Log{{LogID: core.StringPtr("x")}, {LogID: core.StringPtr("y")}}
+		Expect(assistantv1.WriteLogsJSONLines(&buffer, logs)).To(Succeed())
+		Expect(strings.Count(buffer.String(), "\n")).To(Equal(2))
+	})
+})
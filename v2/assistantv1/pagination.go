@@ -0,0 +1,537 @@
+/**
+ * (C) Copyright IBM Corp. 2021.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package assistantv1
+
+import (
+	"context"
+
+	"github.com/IBM/go-sdk-core/v5/core"
+)
+
+// pager : Follows the pagination cursor of a list method, getting one page of results per call. The iterators below
+// embed it, one per paginated list method; ListMentions takes no cursor and has none.
+type pager struct {
+	ctx    context.Context
+	fetch  func(ctx context.Context, cursor *string) (count int, nextCursor *string, err error)
+	cursor *string
+	index  int
+	count  int
+	last   bool
+	err    error
+}
+
+func newPager(ctx context.Context, cursor *string, fetch func(ctx context.Context, cursor *string) (int, *string, error)) pager {
+	return pager{ctx: ctx, fetch: fetch, cursor: cursor, index: -1}
+}
+
+// Next : Advances to the next element, getting the next page of results once the current one is used up. Returns false
+// when there are no more elements, or when the context ends or a call fails; Err tells the two apart.
+func (pager *pager) Next() bool {
+	if pager.err != nil {
+		return false
+	}
+	pager.index++
+	for pager.index >= pager.count {
+		if pager.last {
+			return false
+		}
+		if err := pager.ctx.Err(); err != nil {
+			pager.err = err
+			return false
+		}
+		count, nextCursor, err := pager.fetch(pager.ctx, pager.cursor)
+		if err != nil {
+			pager.err = err
+			return false
+		}
+		pager.index, pager.count, pager.cursor = 0, count, nextCursor
+		pager.last = nextCursor == nil || *nextCursor == ""
+	}
+	return true
+}
+
+// Err : Returns the error that stopped the iteration, or nil once all the elements were visited
+func (pager *pager) Err() error {
+	return pager.err
+}
+
+func nextCursor(pagination *Pagination) *string {
+	if pagination == nil {
+		return nil
+	}
+	return pagination.NextCursor
+}
+
+func nextLogCursor(pagination *LogPagination) *string {
+	if pagination == nil {
+		return nil
+	}
+	return pagination.NextCursor
+}
+
+// WorkspaceIterator : Iterates over the workspaces of the service instance, following the pagination cursor of
+// ListWorkspaces
+type WorkspaceIterator struct {
+	pager
+	page []Workspace
+}
+
+// NewWorkspaceIterator : Returns an iterator over the workspaces of the service instance listed with the options,
+// starting from their cursor, if any, and getting PageLimit workspaces per call. The options may be nil.
+func (assistant *AssistantV1) NewWorkspaceIterator(ctx context.Context, listWorkspacesOptions *ListWorkspacesOptions) *WorkspaceIterator {
+	iterator := &WorkspaceIterator{}
+	if listWorkspacesOptions == nil {
+		listWorkspacesOptions = &ListWorkspacesOptions{}
+	}
+	options := *listWorkspacesOptions
+	iterator.pager = newPager(ctx, options.Cursor, func(ctx context.Context, cursor *string) (int, *string, error) {
+		options.Cursor = cursor
+		result, _, err := assistant.ListWorkspacesWithContext(ctx, &options)
+		if err != nil {
+			return 0, nil, err
+		}
+		iterator.page = result.Workspaces
+		return len(result.Workspaces), nextCursor(result.Pagination), nil
+	})
+	return iterator
+}
+
+// Value : Returns the current element; valid after Next returns true
+func (iterator *WorkspaceIterator) Value() *Workspace {
+	return &iterator.page[iterator.index]
+}
+
+// ListAllWorkspaces : Returns all the workspaces of the service instance listed with the options, following the
+// pagination cursor of ListWorkspaces
+func (assistant *AssistantV1) ListAllWorkspaces(ctx context.Context, listWorkspacesOptions *ListWorkspacesOptions) (result []Workspace, err error) {
+	iterator := assistant.NewWorkspaceIterator(ctx, listWorkspacesOptions)
+	for iterator.Next() {
+		result = append(result, *iterator.Value())
+	}
+	if err = iterator.Err(); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// IntentIterator : Iterates over the intents of a workspace, following the pagination cursor of ListIntents
+type IntentIterator struct {
+	pager
+	page []Intent
+}
+
+// NewIntentIterator : Returns an iterator over the intents of a workspace listed with the options, starting from their
+// cursor, if any, and getting PageLimit intents per call.
+func (assistant *AssistantV1) NewIntentIterator(ctx context.Context, listIntentsOptions *ListIntentsOptions) *IntentIterator {
+	iterator := &IntentIterator{}
+	if err := core.ValidateNotNil(listIntentsOptions, "listIntentsOptions cannot be nil"); err != nil {
+		iterator.err = err
+		return iterator
+	}
+	options := *listIntentsOptions
+	iterator.pager = newPager(ctx, options.Cursor, func(ctx context.Context, cursor *string) (int, *string, error) {
+		options.Cursor = cursor
+		result, _, err := assistant.ListIntentsWithContext(ctx, &options)
+		if err != nil {
+			return 0, nil, err
+		}
+		iterator.page = result.Intents
+		return len(result.Intents), nextCursor(result.Pagination), nil
+	})
+	return iterator
+}
+
+// Value : Returns the current element; valid after Next returns true
+func (iterator *IntentIterator) Value() *Intent {
+	return &iterator.page[iterator.index]
+}
+
+// ListAllIntents : Returns all the intents of a workspace listed with the options, following the pagination cursor of
+// ListIntents
+func (assistant *AssistantV1) ListAllIntents(ctx context.Context, listIntentsOptions *ListIntentsOptions) (result []Intent, err error) {
+	iterator := assistant.NewIntentIterator(ctx, listIntentsOptions)
+	for iterator.Next() {
+		result = append(result, *iterator.Value())
+	}
+	if err = iterator.Err(); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// ExampleIterator : Iterates over the user input examples of an intent, following the pagination cursor of ListExamples
+type ExampleIterator struct {
+	pager
+	page []Example
+}
+
+// NewExampleIterator : Returns an iterator over the user input examples of an intent listed with the options, starting
+// from their cursor, if any, and getting PageLimit examples per call.
+func (assistant *AssistantV1) NewExampleIterator(ctx context.Context, listExamplesOptions *ListExamplesOptions) *ExampleIterator {
+	iterator := &ExampleIterator{}
+	if err := core.ValidateNotNil(listExamplesOptions, "listExamplesOptions cannot be nil"); err != nil {
+		iterator.err = err
+		return iterator
+	}
+	options := *listExamplesOptions
+	iterator.pager = newPager(ctx, options.Cursor, func(ctx context.Context, cursor *string) (int, *string, error) {
+		options.Cursor = cursor
+		result, _, err := assistant.ListExamplesWithContext(ctx, &options)
+		if err != nil {
+			return 0, nil, err
+		}
+		iterator.page = result.Examples
+		return len(result.Examples), nextCursor(result.Pagination), nil
+	})
+	return iterator
+}
+
+// Value : Returns the current element; valid after Next returns true
+func (iterator *ExampleIterator) Value() *Example {
+	return &iterator.page[iterator.index]
+}
+
+// ListAllExamples : Returns all the user input examples of an intent listed with the options, following the pagination
+// cursor of ListExamples
+func (assistant *AssistantV1) ListAllExamples(ctx context.Context, listExamplesOptions *ListExamplesOptions) (result []Example, err error) {
+	iterator := assistant.NewExampleIterator(ctx, listExamplesOptions)
+	for iterator.Next() {
+		result = append(result, *iterator.Value())
+	}
+	if err = iterator.Err(); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// CounterexampleIterator : Iterates over the counterexamples of a workspace, following the pagination cursor of
+// ListCounterexamples
+type CounterexampleIterator struct {
+	pager
+	page []Counterexample
+}
+
+// NewCounterexampleIterator : Returns an iterator over the counterexamples of a workspace listed with the options,
+// starting from their cursor, if any, and getting PageLimit counterexamples per call.
+func (assistant *AssistantV1) NewCounterexampleIterator(ctx context.Context, listCounterexamplesOptions *ListCounterexamplesOptions) *CounterexampleIterator {
+	iterator := &CounterexampleIterator{}
+	if err := core.ValidateNotNil(listCounterexamplesOptions, "listCounterexamplesOptions cannot be nil"); err != nil {
+		iterator.err = err
+		return iterator
+	}
+	options := *listCounterexamplesOptions
+	iterator.pager = newPager(ctx, options.Cursor, func(ctx context.Context, cursor *string) (int, *string, error) {
+		options.Cursor = cursor
+		result, _, err := assistant.ListCounterexamplesWithContext(ctx, &options)
+		if err != nil {
+			return 0, nil, err
+		}
+		iterator.page = result.Counterexamples
+		return len(result.Counterexamples), nextCursor(result.Pagination), nil
+	})
+	return iterator
+}
+
+// Value : Returns the current element; valid after Next returns true
+func (iterator *CounterexampleIterator) Value() *Counterexample {
+	return &iterator.page[iterator.index]
+}
+
+// ListAllCounterexamples : Returns all the counterexamples of a workspace listed with the options, following the
+// pagination cursor of ListCounterexamples
+func (assistant *AssistantV1) ListAllCounterexamples(ctx context.Context, listCounterexamplesOptions *ListCounterexamplesOptions) (result []Counterexample, err error) {
+	iterator := assistant.NewCounterexampleIterator(ctx, listCounterexamplesOptions)
+	for iterator.Next() {
+		result = append(result, *iterator.Value())
+	}
+	if err = iterator.Err(); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// EntityIterator : Iterates over the entities of a workspace, following the pagination cursor of ListEntities
+type EntityIterator struct {
+	pager
+	page []Entity
+}
+
+// NewEntityIterator : Returns an iterator over the entities of a workspace listed with the options, starting from their
+// cursor, if any, and getting PageLimit entities per call.
+func (assistant *AssistantV1) NewEntityIterator(ctx context.Context, listEntitiesOptions *ListEntitiesOptions) *EntityIterator {
+	iterator := &EntityIterator{}
+	if err := core.ValidateNotNil(listEntitiesOptions, "listEntitiesOptions cannot be nil"); err != nil {
+		iterator.err = err
+		return iterator
+	}
+	options := *listEntitiesOptions
+	iterator.pager = newPager(ctx, options.Cursor, func(ctx context.Context, cursor *string) (int, *string, error) {
+		options.Cursor = cursor
+		result, _, err := assistant.ListEntitiesWithContext(ctx, &options)
+		if err != nil {
+			return 0, nil, err
+		}
+		iterator.page = result.Entities
+		return len(result.Entities), nextCursor(result.Pagination), nil
+	})
+	return iterator
+}
+
+// Value : Returns the current element; valid after Next returns true
+func (iterator *EntityIterator) Value() *Entity {
+	return &iterator.page[iterator.index]
+}
+
+// ListAllEntities : Returns all the entities of a workspace listed with the options, following the pagination cursor of
+// ListEntities
+func (assistant *AssistantV1) ListAllEntities(ctx context.Context, listEntitiesOptions *ListEntitiesOptions) (result []Entity, err error) {
+	iterator := assistant.NewEntityIterator(ctx, listEntitiesOptions)
+	for iterator.Next() {
+		result = append(result, *iterator.Value())
+	}
+	if err = iterator.Err(); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// ValueIterator : Iterates over the values of an entity, following the pagination cursor of ListValues
+type ValueIterator struct {
+	pager
+	page []Value
+}
+
+// NewValueIterator : Returns an iterator over the values of an entity listed with the options, starting from their
+// cursor, if any, and getting PageLimit values per call.
+func (assistant *AssistantV1) NewValueIterator(ctx context.Context, listValuesOptions *ListValuesOptions) *ValueIterator {
+	iterator := &ValueIterator{}
+	if err := core.ValidateNotNil(listValuesOptions, "listValuesOptions cannot be nil"); err != nil {
+		iterator.err = err
+		return iterator
+	}
+	options := *listValuesOptions
+	iterator.pager = newPager(ctx, options.Cursor, func(ctx context.Context, cursor *string) (int, *string, error) {
+		options.Cursor = cursor
+		result, _, err := assistant.ListValuesWithContext(ctx, &options)
+		if err != nil {
+			return 0, nil, err
+		}
+		iterator.page = result.Values
+		return len(result.Values), nextCursor(result.Pagination), nil
+	})
+	return iterator
+}
+
+// Value : Returns the current element; valid after Next returns true
+func (iterator *ValueIterator) Value() *Value {
+	return &iterator.page[iterator.index]
+}
+
+// ListAllValues : Returns all the values of an entity listed with the options, following the pagination cursor of
+// ListValues
+func (assistant *AssistantV1) ListAllValues(ctx context.Context, listValuesOptions *ListValuesOptions) (result []Value, err error) {
+	iterator := assistant.NewValueIterator(ctx, listValuesOptions)
+	for iterator.Next() {
+		result = append(result, *iterator.Value())
+	}
+	if err = iterator.Err(); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// SynonymIterator : Iterates over the synonyms of an entity value, following the pagination cursor of ListSynonyms
+type SynonymIterator struct {
+	pager
+	page []Synonym
+}
+
+// NewSynonymIterator : Returns an iterator over the synonyms of an entity value listed with the options, starting from
+// their cursor, if any, and getting PageLimit synonyms per call.
+func (assistant *AssistantV1) NewSynonymIterator(ctx context.Context, listSynonymsOptions *ListSynonymsOptions) *SynonymIterator {
+	iterator := &SynonymIterator{}
+	if err := core.ValidateNotNil(listSynonymsOptions, "listSynonymsOptions cannot be nil"); err != nil {
+		iterator.err = err
+		return iterator
+	}
+	options := *listSynonymsOptions
+	iterator.pager = newPager(ctx, options.Cursor, func(ctx context.Context, cursor *string) (int, *string, error) {
+		options.Cursor = cursor
+		result, _, err := assistant.ListSynonymsWithContext(ctx, &options)
+		if err != nil {
+			return 0, nil, err
+		}
+		iterator.page = result.Synonyms
+		return len(result.Synonyms), nextCursor(result.Pagination), nil
+	})
+	return iterator
+}
+
+// Value : Returns the current element; valid after Next returns true
+func (iterator *SynonymIterator) Value() *Synonym {
+	return &iterator.page[iterator.index]
+}
+
+// ListAllSynonyms : Returns all the synonyms of an entity value listed with the options, following the pagination
+// cursor of ListSynonyms
+func (assistant *AssistantV1) ListAllSynonyms(ctx context.Context, listSynonymsOptions *ListSynonymsOptions) (result []Synonym, err error) {
+	iterator := assistant.NewSynonymIterator(ctx, listSynonymsOptions)
+	for iterator.Next() {
+		result = append(result, *iterator.Value())
+	}
+	if err = iterator.Err(); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// DialogNodeIterator : Iterates over the dialog nodes of a workspace, following the pagination cursor of
+// ListDialogNodes
+type DialogNodeIterator struct {
+	pager
+	page []DialogNode
+}
+
+// NewDialogNodeIterator : Returns an iterator over the dialog nodes of a workspace listed with the options, starting
+// from their cursor, if any, and getting PageLimit dialog nodes per call.
+func (assistant *AssistantV1) NewDialogNodeIterator(ctx context.Context, listDialogNodesOptions *ListDialogNodesOptions) *DialogNodeIterator {
+	iterator := &DialogNodeIterator{}
+	if err := core.ValidateNotNil(listDialogNodesOptions, "listDialogNodesOptions cannot be nil"); err != nil {
+		iterator.err = err
+		return iterator
+	}
+	options := *listDialogNodesOptions
+	iterator.pager = newPager(ctx, options.Cursor, func(ctx context.Context, cursor *string) (int, *string, error) {
+		options.Cursor = cursor
+		result, _, err := assistant.ListDialogNodesWithContext(ctx, &options)
+		if err != nil {
+			return 0, nil, err
+		}
+		iterator.page = result.DialogNodes
+		return len(result.DialogNodes), nextCursor(result.Pagination), nil
+	})
+	return iterator
+}
+
+// Value : Returns the current element; valid after Next returns true
+func (iterator *DialogNodeIterator) Value() *DialogNode {
+	return &iterator.page[iterator.index]
+}
+
+// ListAllDialogNodes : Returns all the dialog nodes of a workspace listed with the options, following the pagination
+// cursor of ListDialogNodes
+func (assistant *AssistantV1) ListAllDialogNodes(ctx context.Context, listDialogNodesOptions *ListDialogNodesOptions) (result []DialogNode, err error) {
+	iterator := assistant.NewDialogNodeIterator(ctx, listDialogNodesOptions)
+	for iterator.Next() {
+		result = append(result, *iterator.Value())
+	}
+	if err = iterator.Err(); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// LogIterator : Iterates over the logged events of a workspace, following the pagination cursor of ListLogs
+type LogIterator struct {
+	pager
+	page []Log
+}
+
+// NewLogIterator : Returns an iterator over the logged events of a workspace listed with the options, starting from
+// their cursor, if any, and getting PageLimit logged events per call.
+func (assistant *AssistantV1) NewLogIterator(ctx context.Context, listLogsOptions *ListLogsOptions) *LogIterator {
+	iterator := &LogIterator{}
+	if err := core.ValidateNotNil(listLogsOptions, "listLogsOptions cannot be nil"); err != nil {
+		iterator.err = err
+		return iterator
+	}
+	options := *listLogsOptions
+	iterator.pager = newPager(ctx, options.Cursor, func(ctx context.Context, cursor *string) (int, *string, error) {
+		options.Cursor = cursor
+		result, _, err := assistant.ListLogsWithContext(ctx, &options)
+		if err != nil {
+			return 0, nil, err
+		}
+		iterator.page = result.Logs
+		return len(result.Logs), nextLogCursor(result.Pagination), nil
+	})
+	return iterator
+}
+
+// Value : Returns the current element; valid after Next returns true
+func (iterator *LogIterator) Value() *Log {
+	return &iterator.page[iterator.index]
+}
+
+// ListAllWorkspaceLogs : Returns all the logged events of a workspace listed with the options, following the pagination
+// cursor of ListLogs. It is named so as not to clash with ListAllLogs, which lists the events of all the workspaces.
+func (assistant *AssistantV1) ListAllWorkspaceLogs(ctx context.Context, listLogsOptions *ListLogsOptions) (result []Log, err error) {
+	iterator := assistant.NewLogIterator(ctx, listLogsOptions)
+	for iterator.Next() {
+		result = append(result, *iterator.Value())
+	}
+	if err = iterator.Err(); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// InstanceLogIterator : Iterates over the logged events of all the workspaces of the service instance, following the
+// pagination cursor of ListAllLogs
+type InstanceLogIterator struct {
+	pager
+	page []Log
+}
+
+// NewInstanceLogIterator : Returns an iterator over the logged events of all the workspaces of the service instance
+// listed with the options, starting from their cursor, if any, and getting PageLimit logged events per call.
+func (assistant *AssistantV1) NewInstanceLogIterator(ctx context.Context, listAllLogsOptions *ListAllLogsOptions) *InstanceLogIterator {
+	iterator := &InstanceLogIterator{}
+	if err := core.ValidateNotNil(listAllLogsOptions, "listAllLogsOptions cannot be nil"); err != nil {
+		iterator.err = err
+		return iterator
+	}
+	options := *listAllLogsOptions
+	iterator.pager = newPager(ctx, options.Cursor, func(ctx context.Context, cursor *string) (int, *string, error) {
+		options.Cursor = cursor
+		result, _, err := assistant.ListAllLogsWithContext(ctx, &options)
+		if err != nil {
+			return 0, nil, err
+		}
+		iterator.page = result.Logs
+		return len(result.Logs), nextLogCursor(result.Pagination), nil
+	})
+	return iterator
+}
+
+// Value : Returns the current element; valid after Next returns true
+func (iterator *InstanceLogIterator) Value() *Log {
+	return &iterator.page[iterator.index]
+}
+
+// ListAllInstanceLogs : Returns all the logged events of all the workspaces of the service instance listed with the
+// options, following the pagination cursor of ListAllLogs. ListAllLogs itself returns a single page.
+func (assistant *AssistantV1) ListAllInstanceLogs(ctx context.Context, listAllLogsOptions *ListAllLogsOptions) (result []Log, err error) {
+	iterator := assistant.NewInstanceLogIterator(ctx, listAllLogsOptions)
+	for iterator.Next() {
+		result = append(result, *iterator.Value())
+	}
+	if err = iterator.Err(); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
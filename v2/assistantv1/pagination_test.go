@@ -0,0 +1,155 @@
+/**
+ * (C) Copyright IBM Corp. 2021.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package assistantv1_test
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+
+	"github.com/IBM/go-sdk-core/v5/core"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/watson-developer-cloud/go-sdk/v2/assistantv1"
+)
+
+// pagedIntents : A stand-in for ListIntents that serves intents i0 to i<total-1> in pages, the cursor being the
+// index of the first intent of the page
+type pagedIntents struct {
+	mutex      sync.Mutex
+	total      int
+	failAt     string
+	pageLimits []string
+	cursors    []string
+}
+
+func (p *pagedIntents) ServeHTTP(w http.ResponseWriter, request *http.Request) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	query := request.URL.Query()
+	p.pageLimits = append(p.pageLimits, query.Get("page_limit"))
+	p.cursors = append(p.cursors, query.Get("cursor"))
+	w.Header().Set("Content-Type", "application/json")
+	if p.failAt != "" && query.Get("cursor") == p.failAt {
+		w.WriteHeader(500)
+		fmt.Fprint(w, `{"error": "Internal error", "code": 500}`)
+		return
+	}
+	start, _ := strconv.Atoi(query.Get("cursor"))
+	limit, _ := strconv.Atoi(query.Get("page_limit"))
+	if limit == 0 {
+		limit = 100
+	}
+	end := start + limit
+	if end > p.total {
+		end = p.total
+	}
+	fmt.Fprint(w, `{"intents": [`)
+	for i := start; i < end; i++ {
+		if i > start {
+			fmt.Fprint(w, `,`)
+		}
+		fmt.Fprintf(w, `{"intent": "i%d"}`, i)
+	}
+	fmt.Fprint(w, `], "pagination": {"refresh_url": "/"`)
+	if end < p.total {
+		fmt.Fprintf(w, `, "next_cursor": "%d"`, end)
+	}
+	fmt.Fprint(w, `}}`)
+}
+
+var _ = Describe(`Pagination`, func() {
+	var standIn *pagedIntents
+	var testServer *httptest.Server
+	var assistantService *assistantv1.AssistantV1
+
+	BeforeEach(func() {
+		standIn = &pagedIntents{total: 5}
+		testServer = httptest.NewServer(standIn)
+		var err error
+		assistantService, err = assistantv1.NewAssistantV1(&assistantv1.AssistantV1Options{
+			URL:           testServer.URL,
+			Version:       core.StringPtr("2021-06-14"),
+			Authenticator: &core.NoAuthAuthenticator{},
+		})
+		Expect(err).To(BeNil())
+	})
+	AfterEach(func() {
+		testServer.Close()
+	})
+
+	It(`Iterate over every page`, func() {
+		options := assistantService.NewListIntentsOptions("ws1").SetPageLimit(2)
+		iterator := assistantService.NewIntentIterator(context.Background(), options)
+		var names []string
+		for iterator.Next() {
+			names = append(names, *iterator.Value().Intent)
+		}
+		Expect(iterator.Err()).To(BeNil())
+		Expect(names).To(Equal([]string{"i0", "i1", "i2", "i3", "i4"}))
+		Expect(standIn.pageLimits).To(Equal([]string{"2", "2", "2"}))
+		Expect(standIn.cursors).To(Equal([]string{"", "2", "4"}))
+		Expect(options.Cursor).To(BeNil())
+		Expect(iterator.Next()).To(BeFalse())
+	})
+
+	It(`List everything from a starting cursor`, func() {
+		options := assistantService.NewListIntentsOptions("ws1").SetPageLimit(2).SetCursor("3")
+		intents, err := assistantService.ListAllIntents(context.Background(), options)
+		Expect(err).To(BeNil())
+		Expect(intents).To(HaveLen(2))
+		Expect(*intents[0].Intent).To(Equal("i3"))
+	})
+
+	It(`Stop on the first failed call`, func() {
+		standIn.failAt = "2"
+		iterator := assistantService.NewIntentIterator(context.Background(), assistantService.NewListIntentsOptions("ws1").SetPageLimit(2))
+		count := 0
+		for iterator.Next() {
+			count++
+		}
+		Expect(count).To(Equal(2))
+		Expect(iterator.Err()).ToNot(BeNil())
+		Expect(iterator.Next()).To(BeFalse())
+		Expect(standIn.cursors).To(HaveLen(2))
+
+		intents, err := assistantService.ListAllIntents(context.Background(), assistantService.NewListIntentsOptions("ws1").SetPageLimit(2))
+		Expect(err).ToNot(BeNil())
+		Expect(intents).To(BeNil())
+	})
+
+	It(`Stop when the context ends`, func() {
+		ctx, cancel := context.WithCancel(context.Background())
+		iterator := assistantService.NewIntentIterator(ctx, assistantService.NewListIntentsOptions("ws1").SetPageLimit(2))
+		Expect(iterator.Next()).To(BeTrue())
+		cancel()
+		Expect(iterator.Next()).To(BeTrue())
+		Expect(iterator.Next()).To(BeFalse())
+		Expect(iterator.Err()).To(Equal(context.Canceled))
+		Expect(standIn.cursors).To(HaveLen(1))
+	})
+
+	It(`Reject nil options`, func() {
+		iterator := assistantService.NewIntentIterator(context.Background(), nil)
+		Expect(iterator.Next()).To(BeFalse())
+		Expect(iterator.Err()).ToNot(BeNil())
+		Expect(standIn.cursors).To(BeEmpty())
+	})
+})
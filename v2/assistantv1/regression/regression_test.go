@@ -0,0 +1,247 @@
+/**
+ * (C) Copyright IBM Corp. 2021.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package regression_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+
+	"github.com/IBM/go-sdk-core/v5/core"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/watson-developer-cloud/go-sdk/v2/assistantv1"
+	"github.com/watson-developer-cloud/go-sdk/v2/assistantv1/regression"
+)
+
+const testSet = "\xef\xbb\xbf" +
+	"what is my balance,#balance\n" +
+	"balance of my savings account,balance,@account:savings\n" +
+	"balance of my checking account,balance,account:checking\n" +
+	"transfer money,transfer\n" +
+	"how much money do I have,balance\n" +
+	"what is the weather,\n"
+
+// classifier : A stand-in for Message and BulkClassify that detects intents and entities from keywords
+type classifier struct {
+	mutex sync.Mutex
+	paths []string
+	sizes []int
+}
+
+func classify(text string) string {
+	var intents []string
+	switch {
+	case strings.Contains(text, "balance"):
+		intents = append(intents, `{"intent": "balance", "confidence": 0.9}`, `{"intent": "transfer", "confidence": 0.05}`)
+	case strings.Contains(text, "transfer"):
+		intents = append(intents, `{"intent": "transfer", "confidence": 0.75}`)
+	case strings.Contains(text, "rate"):
+		intents = append(intents, `{"intent": "rates", "confidence": 0.15}`)
+	case strings.Contains(text, "money"):
+		intents = append(intents, `{"intent": "balance", "confidence": 0.1}`, `{"intent": "transfer", "confidence": 0.45}`)
+	}
+	var entities []string
+	if strings.Contains(text, "savings") {
+		entities = append(entities, `{"entity": "account", "value": "savings", "location": [0, 1]}`)
+	}
+	return fmt.Sprintf(`"intents": [%s], "entities": [%s]`, strings.Join(intents, ","), strings.Join(entities, ","))
+}
+
+func (c *classifier) ServeHTTP(w http.ResponseWriter, request *http.Request) {
+	var body struct {
+		Input json.RawMessage `json:"input"`
+	}
+	data, _ := ioutil.ReadAll(request.Body)
+	_ = json.Unmarshal(data, &body)
+	c.mutex.Lock()
+	c.paths = append(c.paths, request.URL.Path)
+	c.mutex.Unlock()
+
+	w.Header().Set("Content-Type", "application/json")
+	if strings.HasSuffix(request.URL.Path, "/bulk_classify") {
+		var inputs []struct {
+			Text string `json:"text"`
+		}
+		_ = json.Unmarshal(body.Input, &inputs)
+		c.mutex.Lock()
+		c.sizes = append(c.sizes, len(inputs))
+		c.mutex.Unlock()
+		var outputs []string
+		for _, input := range inputs {
+			outputs = append(outputs, fmt.Sprintf(`{"input": {"text": %q}, %s}`, input.Text, classify(input.Text)))
+		}
+		fmt.Fprintf(w, `{"output": [%s]}`, strings.Join(outputs, ","))
+		return
+	}
+	var input struct {
+		Text string `json:"text"`
+	}
+	_ = json.Unmarshal(body.Input, &input)
+	if input.Text == "fail" {
+		w.WriteHeader(500)
+		fmt.Fprint(w, `{"error": "Internal error", "code": 500}`)
+		return
+	}
+	fmt.Fprintf(w, `{"input": {"text": %q}, %s, "output": {}, "context": {}}`, input.Text, classify(input.Text))
+}
+
+var _ = Describe(`Regression`, func() {
+	var standIn *classifier
+	var testServer *httptest.Server
+	var assistantService *assistantv1.AssistantV1
+	var testCases []regression.TestCase
+
+	BeforeEach(func() {
+		standIn = &classifier{}
+		testServer = httptest.NewServer(standIn)
+		var err error
+		assistantService, err = assistantv1.NewAssistantV1(&assistantv1.AssistantV1Options{
+			URL:           testServer.URL,
+			Version:       core.StringPtr("2021-06-14"),
+			Authenticator: &core.NoAuthAuthenticator{},
+		})
+		Expect(err).To(BeNil())
+		testCases, err = regression.ReadTestSetCSV(strings.NewReader(testSet))
+		Expect(err).To(BeNil())
+	})
+	AfterEach(func() {
+		testServer.Close()
+	})
+
+	It(`Read test sets`, func() {
+		Expect(testCases).To(HaveLen(6))
+		Expect(testCases[0].Intent).To(Equal("balance"))
+		Expect(testCases[1].Entities).To(Equal([]regression.Entity{{Entity: "account", Value: "savings"}}))
+		Expect(testCases[5].Intent).To(Equal(""))
+
+		fromJSON, err := regression.ReadTestSetJSON(strings.NewReader(`[{"text": "hi", "intent": "greeting", "entities": [{"entity": "e", "value": "v"}]}]`))
+		Expect(err).To(BeNil())
+		Expect(fromJSON[0].Entities[0].String()).To(Equal("@e:v"))
+
+		_, err = regression.ReadTestSetCSV(strings.NewReader("hi,greeting\nhello,greeting,account\n"))
+		Expect(err).To(MatchError(ContainSubstring("record 2")))
+	})
+
+	It(`Run a test set with Message and evaluate it`, func() {
+		report, err := regression.Run(context.Background(), assistantService, "ws1", testCases, &regression.Options{Concurrency: 3})
+		Expect(err).To(BeNil())
+		Expect(standIn.paths).To(HaveLen(6))
+		Expect(standIn.paths[0]).To(Equal("/v1/workspaces/ws1/message"))
+
+		Expect(report.Total).To(Equal(6))
+		Expect(report.Passed).To(Equal(4))
+		Expect(report.Accuracy).To(BeNumerically("~", 5.0/6, 1e-9))
+		Expect(report.Cases[2].Passed).To(BeFalse())
+		Expect(report.Cases[2].MissingEntities).To(HaveLen(1))
+		Expect(report.Cases[4].PredictedIntent).To(Equal("transfer"))
+		Expect(report.Cases[4].Intents[0].Intent).To(Equal("transfer"))
+
+		Expect(report.Intents).To(HaveLen(2))
+		balance, transfer := report.Intents[0], report.Intents[1]
+		Expect(balance.Support).To(Equal(4))
+		Expect(balance.Precision).To(Equal(1.0))
+		Expect(balance.Recall).To(Equal(0.75))
+		Expect(balance.F1).To(BeNumerically("~", 6.0/7, 1e-9))
+		Expect(transfer.Precision).To(Equal(0.5))
+		Expect(transfer.Recall).To(Equal(1.0))
+
+		Expect(report.ConfusionMatrix.Labels).To(Equal([]string{regression.NoIntentLabel, "balance", "transfer"}))
+		Expect(report.ConfusionMatrix.Count("balance", "transfer")).To(Equal(1))
+		Expect(report.ConfusionMatrix.Count("balance", "balance")).To(Equal(3))
+		Expect(report.ConfusionMatrix.Count(regression.NoIntentLabel, regression.NoIntentLabel)).To(Equal(1))
+
+		Expect(report.CorrectConfidence.Buckets).To(HaveLen(10))
+		Expect(report.CorrectConfidence.Buckets[9].Count).To(Equal(3))
+		Expect(report.CorrectConfidence.Buckets[0].Count).To(Equal(1))
+		Expect(report.CorrectConfidence.Buckets[7].Count).To(Equal(1))
+		Expect(report.IncorrectConfidence.Buckets[4].Count).To(Equal(1))
+	})
+
+	It(`Run a test set with BulkClassify in batches`, func() {
+		report, err := regression.Run(context.Background(), assistantService, "ws1", testCases, &regression.Options{Mode: regression.ModeBulkClassifyConst, BatchSize: 4})
+		Expect(err).To(BeNil())
+		Expect(standIn.sizes).To(Equal([]int{4, 2}))
+		Expect(report.Passed).To(Equal(4))
+		Expect(report.Cases[5].Text).To(Equal("what is the weather"))
+	})
+
+	It(`Apply the confidence threshold`, func() {
+		testCases = []regression.TestCase{{Text: "interest rate", Intent: "rates"}}
+		report, err := regression.Run(context.Background(), assistantService, "ws1", testCases, nil)
+		Expect(err).To(BeNil())
+		Expect(report.Cases[0].PredictedIntent).To(Equal(""))
+		Expect(report.Passed).To(Equal(0))
+
+		report, err = regression.Run(context.Background(), assistantService, "ws1", testCases, &regression.Options{Threshold: core.Float64Ptr(0)})
+		Expect(err).To(BeNil())
+		Expect(report.Cases[0].PredictedIntent).To(Equal("rates"))
+		Expect(report.Passed).To(Equal(1))
+	})
+
+	It(`Stop on a failed call`, func() {
+		testCases = append(testCases, regression.TestCase{Text: "fail", Intent: "balance"})
+		_, err := regression.Run(context.Background(), assistantService, "ws1", testCases, nil)
+		Expect(err).To(MatchError(ContainSubstring("test case 7")))
+	})
+
+	It(`Write JSON and JUnit XML`, func() {
+		report, err := regression.Run(context.Background(), assistantService, "ws1", testCases, nil)
+		Expect(err).To(BeNil())
+
+		var buffer bytes.Buffer
+		Expect(report.WriteJSON(&buffer)).To(Succeed())
+		var decoded map[string]interface{}
+		Expect(json.Unmarshal(buffer.Bytes(), &decoded)).To(Succeed())
+		Expect(decoded["failed"]).To(Equal(2.0))
+		Expect(decoded["confusion_matrix"]).To(HaveKey("counts"))
+
+		buffer.Reset()
+		Expect(report.WriteJUnit(&buffer, "bank intents")).To(Succeed())
+		Expect(buffer.String()).To(HavePrefix(xml.Header))
+		var suites struct {
+			Suites []struct {
+				Name     string `xml:"name,attr"`
+				Tests    int    `xml:"tests,attr"`
+				Failures int    `xml:"failures,attr"`
+				Cases    []struct {
+					Name      string `xml:"name,attr"`
+					ClassName string `xml:"classname,attr"`
+					Failure   *struct {
+						Message string `xml:"message,attr"`
+					} `xml:"failure"`
+				} `xml:"testcase"`
+			} `xml:"testsuite"`
+		}
+		Expect(xml.Unmarshal(buffer.Bytes(), &suites)).To(Succeed())
+		suite := suites.Suites[0]
+		Expect(suite.Name).To(Equal("bank intents"))
+		Expect(suite.Tests).To(Equal(6))
+		Expect(suite.Failures).To(Equal(2))
+		Expect(suite.Cases[2].Failure.Message).To(Equal("missing entities @account:checking"))
+		Expect(suite.Cases[4].Failure.Message).To(Equal("expected #balance, detected #transfer with confidence 0.450"))
+		Expect(suite.Cases[5].ClassName).To(Equal(regression.NoIntentLabel))
+		Expect(suite.Cases[5].Failure).To(BeNil())
+	})
+})
@@ -0,0 +1,321 @@
+/**
+ * (C) Copyright IBM Corp. 2021.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package regression
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+)
+
+// NoIntentLabel is the label of utterances that match no intent in confusion matrices
+const NoIntentLabel = "(irrelevant)"
+
+// Report : The evaluation of a test run
+type Report struct {
+	WorkspaceID string `json:"workspace_id,omitempty"`
+
+	Total  int `json:"total"`
+	Passed int `json:"passed"`
+	Failed int `json:"failed"`
+
+	// The share of test cases whose intent was detected correctly, entities aside.
+	Accuracy float64 `json:"accuracy"`
+
+	// The unweighted averages of the metrics of the intents.
+	MacroPrecision float64 `json:"macro_precision"`
+	MacroRecall    float64 `json:"macro_recall"`
+	MacroF1        float64 `json:"macro_f1"`
+
+	// The metrics of each intent that was expected or detected, in intent order.
+	Intents []IntentMetrics `json:"intents"`
+
+	ConfusionMatrix ConfusionMatrix `json:"confusion_matrix"`
+
+	// The confidence of the top intent of the test cases whose intent was detected correctly, and of the others.
+	CorrectConfidence   Histogram `json:"correct_confidence"`
+	IncorrectConfidence Histogram `json:"incorrect_confidence"`
+
+	Cases []CaseResult `json:"cases"`
+}
+
+// IntentMetrics : The classification metrics of an intent
+type IntentMetrics struct {
+	Intent string `json:"intent"`
+
+	// The number of test cases that expect the intent.
+	Support int `json:"support"`
+
+	TruePositives  int `json:"true_positives"`
+	FalsePositives int `json:"false_positives"`
+	FalseNegatives int `json:"false_negatives"`
+
+	Precision float64 `json:"precision"`
+	Recall    float64 `json:"recall"`
+	F1        float64 `json:"f1"`
+}
+
+// ConfusionMatrix : The number of test cases by expected intent (rows) and detected intent (columns)
+type ConfusionMatrix struct {
+	// The intents of the rows and columns, with NoIntentLabel for no intent.
+	Labels []string `json:"labels"`
+
+	Counts [][]int `json:"counts"`
+}
+
+// Count : Returns the number of test cases that expect an intent and detect another
+func (matrix ConfusionMatrix) Count(expected string, predicted string) int {
+	row, column := -1, -1
+	for i, label := range matrix.Labels {
+		if label == expected {
+			row = i
+		}
+		if label == predicted {
+			column = i
+		}
+	}
+	if row < 0 || column < 0 {
+		return 0
+	}
+	return matrix.Counts[row][column]
+}
+
+// Histogram : A histogram of confidences between 0 and 1
+type Histogram struct {
+	Buckets []HistogramBucket `json:"buckets"`
+}
+
+// HistogramBucket : The number of confidences from Min, included, to Max, excluded unless it is 1
+type HistogramBucket struct {
+	Min   float64 `json:"min"`
+	Max   float64 `json:"max"`
+	Count int     `json:"count"`
+}
+
+// Evaluate : Computes the report of test case results. The options may be nil; only HistogramBuckets is used.
+func Evaluate(results []CaseResult, options *Options) *Report {
+	options = withDefaults(options)
+	report := &Report{
+		Total:               len(results),
+		Cases:               results,
+		CorrectConfidence:   newHistogram(options.HistogramBuckets),
+		IncorrectConfidence: newHistogram(options.HistogramBuckets),
+	}
+
+	labelSet := map[string]bool{}
+	metrics := map[string]*IntentMetrics{}
+	metricsOf := func(intent string) *IntentMetrics {
+		if metrics[intent] == nil {
+			metrics[intent] = &IntentMetrics{Intent: intent}
+		}
+		return metrics[intent]
+	}
+	correct := 0
+	for _, result := range results {
+		if result.Passed {
+			report.Passed++
+		}
+		labelSet[label(result.Intent)] = true
+		labelSet[label(result.PredictedIntent)] = true
+		if result.Intent != "" {
+			metricsOf(result.Intent).Support++
+		}
+		if result.PredictedIntent == result.Intent {
+			correct++
+			report.CorrectConfidence.add(result.Confidence)
+			if result.Intent != "" {
+				metricsOf(result.Intent).TruePositives++
+			}
+			continue
+		}
+		report.IncorrectConfidence.add(result.Confidence)
+		if result.Intent != "" {
+			metricsOf(result.Intent).FalseNegatives++
+		}
+		if result.PredictedIntent != "" {
+			metricsOf(result.PredictedIntent).FalsePositives++
+		}
+	}
+	report.Failed = report.Total - report.Passed
+	report.Accuracy = ratio(correct, report.Total)
+
+	for _, intent := range metrics {
+		intent.Precision = ratio(intent.TruePositives, intent.TruePositives+intent.FalsePositives)
+		intent.Recall = ratio(intent.TruePositives, intent.TruePositives+intent.FalseNegatives)
+		if intent.Precision+intent.Recall > 0 {
+			intent.F1 = 2 * intent.Precision * intent.Recall / (intent.Precision + intent.Recall)
+		}
+		report.Intents = append(report.Intents, *intent)
+		report.MacroPrecision += intent.Precision
+		report.MacroRecall += intent.Recall
+		report.MacroF1 += intent.F1
+	}
+	sort.Slice(report.Intents, func(i, j int) bool {
+		return report.Intents[i].Intent < report.Intents[j].Intent
+	})
+	if len(report.Intents) > 0 {
+		report.MacroPrecision /= float64(len(report.Intents))
+		report.MacroRecall /= float64(len(report.Intents))
+		report.MacroF1 /= float64(len(report.Intents))
+	}
+
+	for name := range labelSet {
+		report.ConfusionMatrix.Labels = append(report.ConfusionMatrix.Labels, name)
+	}
+	sort.Strings(report.ConfusionMatrix.Labels)
+	index := map[string]int{}
+	for i, name := range report.ConfusionMatrix.Labels {
+		index[name] = i
+		report.ConfusionMatrix.Counts = append(report.ConfusionMatrix.Counts, make([]int, len(report.ConfusionMatrix.Labels)))
+	}
+	for _, result := range results {
+		report.ConfusionMatrix.Counts[index[label(result.Intent)]][index[label(result.PredictedIntent)]]++
+	}
+	return report
+}
+
+// WriteJSON : Writes the report as indented JSON
+func (report *Report) WriteJSON(writer io.Writer) error {
+	encoder := json.NewEncoder(writer)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(report)
+}
+
+// junitTestSuites : The root element of a JUnit XML report
+type junitTestSuites struct {
+	XMLName xml.Name         `xml:"testsuites"`
+	Suites  []junitTestSuite `xml:"testsuite"`
+}
+
+type junitTestSuite struct {
+	Name       string          `xml:"name,attr"`
+	Tests      int             `xml:"tests,attr"`
+	Failures   int             `xml:"failures,attr"`
+	Properties []junitProperty `xml:"properties>property,omitempty"`
+	Cases      []junitTestCase `xml:"testcase"`
+}
+
+type junitProperty struct {
+	Name  string `xml:"name,attr"`
+	Value string `xml:"value,attr"`
+}
+
+type junitTestCase struct {
+	Name      string        `xml:"name,attr"`
+	ClassName string        `xml:"classname,attr"`
+	Failure   *junitFailure `xml:"failure,omitempty"`
+}
+
+type junitFailure struct {
+	Message string `xml:"message,attr"`
+	Type    string `xml:"type,attr"`
+}
+
+// WriteJUnit : Writes the report as JUnit XML, with a test case per utterance, named after it, and classed by its
+// expected intent. The suite carries the accuracy and the macro averages as properties.
+func (report *Report) WriteJUnit(writer io.Writer, suiteName string) error {
+	suite := junitTestSuite{
+		Name:     suiteName,
+		Tests:    report.Total,
+		Failures: report.Failed,
+		Properties: []junitProperty{
+			{Name: "workspace_id", Value: report.WorkspaceID},
+			{Name: "accuracy", Value: formatRatio(report.Accuracy)},
+			{Name: "macro_precision", Value: formatRatio(report.MacroPrecision)},
+			{Name: "macro_recall", Value: formatRatio(report.MacroRecall)},
+			{Name: "macro_f1", Value: formatRatio(report.MacroF1)},
+		},
+	}
+	for _, result := range report.Cases {
+		testCase := junitTestCase{Name: result.Text, ClassName: label(result.Intent)}
+		if !result.Passed {
+			testCase.Failure = &junitFailure{Message: failureMessage(result), Type: "mismatch"}
+		}
+		suite.Cases = append(suite.Cases, testCase)
+	}
+
+	if _, err := io.WriteString(writer, xml.Header); err != nil {
+		return err
+	}
+	encoder := xml.NewEncoder(writer)
+	encoder.Indent("", "  ")
+	if err := encoder.Encode(junitTestSuites{Suites: []junitTestSuite{suite}}); err != nil {
+		return err
+	}
+	_, err := io.WriteString(writer, "\n")
+	return err
+}
+
+// failureMessage : Describes why a test case failed
+func failureMessage(result CaseResult) string {
+	if result.PredictedIntent != result.Intent {
+		return fmt.Sprintf("expected %s, detected %s with confidence %.3f", intentName(result.Intent), intentName(result.PredictedIntent), result.Confidence)
+	}
+	message := "missing entities"
+	for _, entity := range result.MissingEntities {
+		message += " " + entity.String()
+	}
+	return message
+}
+
+func newHistogram(buckets int) Histogram {
+	histogram := Histogram{Buckets: make([]HistogramBucket, buckets)}
+	for i := range histogram.Buckets {
+		histogram.Buckets[i].Min = float64(i) / float64(buckets)
+		histogram.Buckets[i].Max = float64(i+1) / float64(buckets)
+	}
+	return histogram
+}
+
+func (histogram *Histogram) add(confidence float64) {
+	i := int(math.Floor(confidence * float64(len(histogram.Buckets))))
+	if i >= len(histogram.Buckets) {
+		i = len(histogram.Buckets) - 1
+	}
+	if i < 0 {
+		i = 0
+	}
+	histogram.Buckets[i].Count++
+}
+
+func label(intent string) string {
+	if intent == "" {
+		return NoIntentLabel
+	}
+	return intent
+}
+
+func intentName(intent string) string {
+	if intent == "" {
+		return "no intent"
+	}
+	return "#" + intent
+}
+
+func ratio(numerator int, denominator int) float64 {
+	if denominator == 0 {
+		return 0
+	}
+	return float64(numerator) / float64(denominator)
+}
+
+func formatRatio(value float64) string {
+	return fmt.Sprintf("%.4f", value)
+}
@@ -0,0 +1,253 @@
+/**
+ * (C) Copyright IBM Corp. 2021.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package regression
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/IBM/go-sdk-core/v5/core"
+	"github.com/watson-developer-cloud/go-sdk/v2/assistantv1"
+)
+
+// Constants associated with the Options.Mode property.
+const (
+	// Classify each utterance with Message, with alternate intents on.
+	ModeMessageConst = "message"
+
+	// Classify the utterances in batches with BulkClassify.
+	ModeBulkClassifyConst = "bulk_classify"
+)
+
+// The defaults of the fields of Options.
+const (
+	DefaultBatchSize        = 50
+	DefaultConcurrency      = 1
+	DefaultThreshold        = 0.2
+	DefaultHistogramBuckets = 10
+)
+
+// Options : Options for Run
+type Options struct {
+	// How to classify the utterances; ModeMessageConst by default.
+	Mode string
+
+	// The number of utterances per BulkClassify call.
+	BatchSize int
+
+	// The number of Message calls made at once.
+	Concurrency int
+
+	// The confidence under which the top intent does not count, and the utterance matches no intent; DefaultThreshold,
+	// the threshold of the service, when nil. Zero counts every top intent.
+	Threshold *float64
+
+	// The number of buckets of the confidence histograms.
+	HistogramBuckets int
+}
+
+// IntentScore : An intent with its confidence
+type IntentScore struct {
+	Intent     string  `json:"intent"`
+	Confidence float64 `json:"confidence"`
+}
+
+// CaseResult : The outcome of a test case
+type CaseResult struct {
+	TestCase
+
+	// The intent detected, or empty if no intent reached the threshold.
+	PredictedIntent string `json:"predicted_intent"`
+
+	// The confidence of the top intent, even under the threshold.
+	Confidence float64 `json:"confidence"`
+
+	// The intents returned, by decreasing confidence.
+	Intents []IntentScore `json:"intents,omitempty"`
+
+	// The entities detected.
+	PredictedEntities []Entity `json:"predicted_entities,omitempty"`
+
+	// The expected entities that were not detected.
+	MissingEntities []Entity `json:"missing_entities,omitempty"`
+
+	// Whether the expected intent and entities were detected.
+	Passed bool `json:"passed"`
+}
+
+// Run : Classifies the utterances of a test set with a workspace and evaluates the results. The options may be nil. A
+// failed call stops the run.
+func Run(ctx context.Context, service *assistantv1.AssistantV1, workspaceID string, testCases []TestCase, options *Options) (*Report, error) {
+	options = withDefaults(options)
+	var results []CaseResult
+	var err error
+	switch options.Mode {
+	case ModeMessageConst:
+		results, err = runMessage(ctx, service, workspaceID, testCases, options)
+	case ModeBulkClassifyConst:
+		results, err = runBulkClassify(ctx, service, workspaceID, testCases, options)
+	default:
+		err = fmt.Errorf("unknown mode %q", options.Mode)
+	}
+	if err != nil {
+		return nil, err
+	}
+	report := Evaluate(results, options)
+	report.WorkspaceID = workspaceID
+	return report, nil
+}
+
+// runMessage : Classifies each utterance with Message, Concurrency at a time
+func runMessage(ctx context.Context, service *assistantv1.AssistantV1, workspaceID string, testCases []TestCase, options *Options) ([]CaseResult, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	results := make([]CaseResult, len(testCases))
+	indexes := make(chan int)
+	var waitGroup sync.WaitGroup
+	var once sync.Once
+	var firstErr error
+	for worker := 0; worker < options.Concurrency; worker++ {
+		waitGroup.Add(1)
+		go func() {
+			defer waitGroup.Done()
+			for i := range indexes {
+				messageOptions := service.NewMessageOptions(workspaceID).
+					SetInput(&assistantv1.MessageInput{Text: core.StringPtr(testCases[i].Text)}).
+					SetAlternateIntents(true)
+				response, _, err := service.MessageWithContext(ctx, messageOptions)
+				if err != nil {
+					once.Do(func() {
+						firstErr = fmt.Errorf("test case %d: %s", i+1, err)
+						cancel()
+					})
+					continue
+				}
+				results[i] = newCaseResult(testCases[i], response.Intents, response.Entities, *options.Threshold)
+			}
+		}()
+	}
+feed:
+	for i := range testCases {
+		select {
+		case indexes <- i:
+		case <-ctx.Done():
+			break feed
+		}
+	}
+	close(indexes)
+	waitGroup.Wait()
+	if firstErr != nil {
+		return nil, firstErr
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return results, nil
+}
+
+// runBulkClassify : Classifies the utterances with BulkClassify, BatchSize at a time
+func runBulkClassify(ctx context.Context, service *assistantv1.AssistantV1, workspaceID string, testCases []TestCase, options *Options) ([]CaseResult, error) {
+	results := make([]CaseResult, 0, len(testCases))
+	for start := 0; start < len(testCases); start += options.BatchSize {
+		end := start + options.BatchSize
+		if end > len(testCases) {
+			end = len(testCases)
+		}
+		batch := make([]assistantv1.BulkClassifyUtterance, 0, end-start)
+		for _, testCase := range testCases[start:end] {
+			batch = append(batch, assistantv1.BulkClassifyUtterance{Text: core.StringPtr(testCase.Text)})
+		}
+		response, _, err := service.BulkClassifyWithContext(ctx, service.NewBulkClassifyOptions(workspaceID).SetInput(batch))
+		if err != nil {
+			return nil, fmt.Errorf("test cases %d to %d: %s", start+1, end, err)
+		}
+		if len(response.Output) != len(batch) {
+			return nil, fmt.Errorf("test cases %d to %d: %d outputs for %d utterances", start+1, end, len(response.Output), len(batch))
+		}
+		for i, output := range response.Output {
+			results = append(results, newCaseResult(testCases[start+i], output.Intents, output.Entities, *options.Threshold))
+		}
+	}
+	return results, nil
+}
+
+// newCaseResult : Compares the intents and entities detected in an utterance with the expected ones
+func newCaseResult(testCase TestCase, intents []assistantv1.RuntimeIntent, entities []assistantv1.RuntimeEntity, threshold float64) CaseResult {
+	result := CaseResult{TestCase: testCase}
+	for _, intent := range intents {
+		result.Intents = append(result.Intents, IntentScore{Intent: core.StringNilMapper(intent.Intent), Confidence: float64Value(intent.Confidence)})
+	}
+	sort.SliceStable(result.Intents, func(i, j int) bool {
+		return result.Intents[i].Confidence > result.Intents[j].Confidence
+	})
+	if len(result.Intents) > 0 {
+		result.Confidence = result.Intents[0].Confidence
+		if result.Confidence >= threshold {
+			result.PredictedIntent = result.Intents[0].Intent
+		}
+	}
+
+	detected := map[Entity]bool{}
+	for _, entity := range entities {
+		predicted := Entity{Entity: core.StringNilMapper(entity.Entity), Value: core.StringNilMapper(entity.Value)}
+		if !detected[predicted] {
+			detected[predicted] = true
+			result.PredictedEntities = append(result.PredictedEntities, predicted)
+		}
+	}
+	for _, entity := range testCase.Entities {
+		if !detected[entity] {
+			result.MissingEntities = append(result.MissingEntities, entity)
+		}
+	}
+	result.Passed = result.PredictedIntent == testCase.Intent && len(result.MissingEntities) == 0
+	return result
+}
+
+// withDefaults : Returns a copy of the options with the defaults filled in
+func withDefaults(options *Options) *Options {
+	filled := Options{}
+	if options != nil {
+		filled = *options
+	}
+	if filled.Mode == "" {
+		filled.Mode = ModeMessageConst
+	}
+	if filled.BatchSize <= 0 {
+		filled.BatchSize = DefaultBatchSize
+	}
+	if filled.Concurrency <= 0 {
+		filled.Concurrency = DefaultConcurrency
+	}
+	if filled.Threshold == nil {
+		filled.Threshold = core.Float64Ptr(DefaultThreshold)
+	}
+	if filled.HistogramBuckets <= 0 {
+		filled.HistogramBuckets = DefaultHistogramBuckets
+	}
+	return &filled
+}
+
+func float64Value(value *float64) float64 {
+	if value == nil {
+		return 0
+	}
+	return *value
+}
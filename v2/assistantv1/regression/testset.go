@@ -0,0 +1,116 @@
+/**
+ * (C) Copyright IBM Corp. 2021.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package regression runs utterance regression tests against a Watson Assistant v1 workspace.
+//
+// A test set lists utterances with the intent, and optionally the entities, that the workspace should detect. Run
+// classifies every utterance, with Message or in batches with BulkClassify, and Evaluate turns the results into a
+// Report: per-intent precision, recall and F1, a confusion matrix and confidence histograms. A report is written as
+// JSON for tooling and as JUnit XML for continuous integration.
+package regression
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// TestCase : An utterance with the intent and entities that the workspace should detect in it
+type TestCase struct {
+	Text string `json:"text"`
+
+	// The expected intent; empty if the utterance should not match any intent.
+	Intent string `json:"intent,omitempty"`
+
+	// Entities that must be detected; others may be detected as well.
+	Entities []Entity `json:"entities,omitempty"`
+}
+
+// Entity : An entity value
+type Entity struct {
+	Entity string `json:"entity"`
+	Value  string `json:"value"`
+}
+
+func (entity Entity) String() string {
+	return "@" + entity.Entity + ":" + entity.Value
+}
+
+// ReadTestSetCSV : Reads a test set in CSV, one `utterance,intent[,entity:value...]` record per test case, so that an
+// intents file of the Watson Assistant tooling is a test set as well. A leading `#` on intents and `@` on entities
+// is dropped; an empty intent expects no intent.
+func ReadTestSetCSV(reader io.Reader) ([]TestCase, error) {
+	buffered := bufio.NewReader(reader)
+	if prefix, err := buffered.Peek(3); err == nil && string(prefix) == "\xef\xbb\xbf" {
+		_, _ = buffered.Discard(3)
+	}
+	csvReader := csv.NewReader(buffered)
+	csvReader.FieldsPerRecord = -1
+
+	var testCases []TestCase
+	for recordNumber := 1; ; recordNumber++ {
+		record, err := csvReader.Read()
+		if err == io.EOF {
+			return testCases, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		testCase := TestCase{Text: strings.TrimSpace(record[0])}
+		if testCase.Text == "" {
+			return nil, fmt.Errorf("record %d: missing utterance", recordNumber)
+		}
+		if len(record) > 1 {
+			testCase.Intent = strings.TrimPrefix(strings.TrimSpace(record[1]), "#")
+		}
+		for _, field := range recordTail(record, 2) {
+			field = strings.TrimPrefix(strings.TrimSpace(field), "@")
+			if field == "" {
+				continue
+			}
+			separator := strings.Index(field, ":")
+			if separator <= 0 || separator == len(field)-1 {
+				return nil, fmt.Errorf("record %d: expected entity:value, found %q", recordNumber, field)
+			}
+			testCase.Entities = append(testCase.Entities, Entity{Entity: field[:separator], Value: field[separator+1:]})
+		}
+		testCases = append(testCases, testCase)
+	}
+}
+
+// ReadTestSetJSON : Reads a test set in JSON, an array of test cases
+func ReadTestSetJSON(reader io.Reader) ([]TestCase, error) {
+	var testCases []TestCase
+	if err := json.NewDecoder(reader).Decode(&testCases); err != nil {
+		return nil, err
+	}
+	for i, testCase := range testCases {
+		if strings.TrimSpace(testCase.Text) == "" {
+			return nil, fmt.Errorf("test case %d: missing utterance", i+1)
+		}
+	}
+	return testCases, nil
+}
+
+func recordTail(record []string, from int) []string {
+	if len(record) <= from {
+		return nil
+	}
+	return record[from:]
+}
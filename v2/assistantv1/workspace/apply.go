@@ -0,0 +1,494 @@
+/**
+ * (C) Copyright IBM Corp. 2021.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package workspace
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/IBM/go-sdk-core/v5/core"
+	"github.com/watson-developer-cloud/go-sdk/v2/assistantv1"
+)
+
+// ApplyOptions : Options for Apply
+type ApplyOptions struct {
+	// Apply the diff with one UpdateWorkspace call that replaces the sections that changed, instead of a call per
+	// changed element.
+	SingleUpdate bool
+}
+
+// Apply : Makes the changes of a diff to a workspace of the service. The options may be nil.
+//
+// By default each change is made with the matching fine-grained method: intents, examples, entities, values,
+// synonyms, counterexamples and dialog nodes are created, updated and deleted one by one, and the workspace-level
+// settings are updated with UpdateWorkspace. Dialog nodes are created and updated parents and previous siblings
+// first, and deleted last, so that nodes moved out of a deleted node survive. Examples and values whose fields other
+// than synonyms changed are deleted and created again.
+//
+// With SingleUpdate, one UpdateWorkspace call replaces each section that has changes with its content in the target
+// workspace. Sections that the target leaves empty cannot be replaced, since the API drops empty lists, so their
+// elements are deleted one by one.
+//
+// Some changes have no API equivalent, such as removing the metadata of a workspace or, in fine-grained mode, the
+// actions of a dialog node; Apply returns an error for them before making any call. A failed call stops Apply and
+// leaves the changes made so far in place.
+func Apply(ctx context.Context, service *assistantv1.AssistantV1, workspaceID string, diff *Diff, applyOptions *ApplyOptions) error {
+	if applyOptions == nil {
+		applyOptions = &ApplyOptions{}
+	}
+	if diff.target == nil {
+		return fmt.Errorf("the diff was not computed with Compute")
+	}
+	if err := checkApplicable(diff, applyOptions.SingleUpdate); err != nil {
+		return err
+	}
+	applier := &applier{ctx: ctx, service: service, workspaceID: workspaceID}
+	if applyOptions.SingleUpdate {
+		return applier.singleUpdate(diff)
+	}
+
+	steps := []func() error{
+		func() error { return applier.settings(diff, nil) },
+		func() error { return applier.counterexamples(diff.Counterexamples) },
+		func() error { return applier.entities(diff.Entities) },
+		func() error { return applier.intents(diff.Intents) },
+		func() error { return applier.dialogNodes(diff.DialogNodes) },
+	}
+	for _, step := range steps {
+		if err := step(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// checkApplicable : Returns an error for the changes that the API cannot make
+func checkApplicable(diff *Diff, singleUpdate bool) error {
+	var problems []string
+	for _, setting := range diff.Settings {
+		if (setting.Field == "metadata" || setting.Field == "webhooks") && jsonEqual(setting.To, nil) {
+			problems = append(problems, fmt.Sprintf("the %s of the workspace cannot be removed", setting.Field))
+		}
+	}
+	if !singleUpdate {
+		for _, entity := range diff.Entities {
+			if entity.Kind == ChangeKindModifiedConst && entity.From.Metadata != nil && len(entity.To.Metadata) == 0 {
+				problems = append(problems, fmt.Sprintf("the metadata of entity @%s cannot be removed", entity.Entity))
+			}
+		}
+		for _, node := range diff.DialogNodes {
+			if node.Kind != ChangeKindModifiedConst {
+				continue
+			}
+			for _, field := range node.Fields {
+				if unclearableDialogNodeFields[field] && dialogNodeFieldRemoved(node, field) {
+					problems = append(problems, fmt.Sprintf("the %s of dialog node %s cannot be removed", field, node.DialogNode))
+				}
+				if field == "disabled" {
+					problems = append(problems, fmt.Sprintf("dialog node %s cannot be enabled or disabled", node.DialogNode))
+				}
+			}
+		}
+	}
+	if len(problems) > 0 {
+		return fmt.Errorf("the diff cannot be applied: %s", strings.Join(problems, "; "))
+	}
+	return nil
+}
+
+// unclearableDialogNodeFields are the dialog node fields that UpdateDialogNode drops when empty
+var unclearableDialogNodeFields = map[string]bool{"metadata": true, "next_step": true, "actions": true}
+
+func dialogNodeFieldRemoved(node DialogNodeChange, field string) bool {
+	switch field {
+	case "metadata":
+		return len(node.To.Metadata) == 0
+	case "next_step":
+		return node.To.NextStep == nil
+	case "actions":
+		return len(node.To.Actions) == 0
+	}
+	return false
+}
+
+type applier struct {
+	ctx         context.Context
+	service     *assistantv1.AssistantV1
+	workspaceID string
+}
+
+// settings : Updates the workspace-level settings that changed, together with the sections of the options
+func (applier *applier) settings(diff *Diff, options *assistantv1.UpdateWorkspaceOptions) error {
+	if len(diff.Settings) == 0 && options == nil {
+		return nil
+	}
+	if options == nil {
+		options = applier.service.NewUpdateWorkspaceOptions(applier.workspaceID)
+	}
+	target := diff.target
+	for _, setting := range diff.Settings {
+		switch setting.Field {
+		case "name":
+			options.Name = emptyIfNil(target.Name)
+		case "description":
+			options.Description = emptyIfNil(target.Description)
+		case "language":
+			options.Language = target.Language
+		case "learning_opt_out":
+			options.LearningOptOut = core.BoolPtr(target.LearningOptOut != nil && *target.LearningOptOut)
+		case "metadata":
+			options.Metadata = target.Metadata
+		case "system_settings":
+			options.SystemSettings = target.SystemSettings
+			if options.SystemSettings == nil {
+				options.SystemSettings = &assistantv1.WorkspaceSystemSettings{}
+			}
+		case "webhooks":
+			options.Webhooks = target.Webhooks
+		}
+	}
+	options.SetAppend(false)
+	_, _, err := applier.service.UpdateWorkspaceWithContext(applier.ctx, options)
+	return err
+}
+
+// singleUpdate : Applies the diff with one UpdateWorkspace call, deleting the elements of sections that become empty
+func (applier *applier) singleUpdate(diff *Diff) error {
+	target := diff.target
+	options := applier.service.NewUpdateWorkspaceOptions(applier.workspaceID)
+	var emptied []func() error
+
+	if len(diff.Intents) > 0 {
+		if len(target.Intents) == 0 {
+			emptied = append(emptied, func() error { return applier.intents(diff.Intents) })
+		}
+		for _, intent := range target.Intents {
+			options.Intents = append(options.Intents, createIntent(intent))
+		}
+	}
+	if len(diff.Entities) > 0 {
+		if len(target.Entities) == 0 {
+			emptied = append(emptied, func() error { return applier.entities(diff.Entities) })
+		}
+		for _, entity := range target.Entities {
+			options.Entities = append(options.Entities, createEntity(entity))
+		}
+	}
+	if len(diff.Counterexamples) > 0 {
+		if len(target.Counterexamples) == 0 {
+			emptied = append(emptied, func() error { return applier.counterexamples(diff.Counterexamples) })
+		}
+		options.Counterexamples = target.Counterexamples
+	}
+	if len(diff.DialogNodes) > 0 {
+		if len(target.DialogNodes) == 0 {
+			emptied = append(emptied, func() error { return applier.dialogNodes(diff.DialogNodes) })
+		}
+		options.DialogNodes = target.DialogNodes
+	}
+
+	if len(diff.Settings) > 0 || len(emptied) < countSections(diff) {
+		if err := applier.settings(diff, options); err != nil {
+			return err
+		}
+	}
+	for _, step := range emptied {
+		if err := step(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// countSections : Returns the number of sections of the diff with changes
+func countSections(diff *Diff) (count int) {
+	for _, changes := range []int{len(diff.Intents), len(diff.Entities), len(diff.Counterexamples), len(diff.DialogNodes)} {
+		if changes > 0 {
+			count++
+		}
+	}
+	return
+}
+
+func (applier *applier) counterexamples(changes []CounterexampleChange) (err error) {
+	service := applier.service
+	for _, change := range changes {
+		switch change.Kind {
+		case ChangeKindAddedConst:
+			_, _, err = service.CreateCounterexampleWithContext(applier.ctx, service.NewCreateCounterexampleOptions(applier.workspaceID, change.Text))
+		case ChangeKindRemovedConst:
+			_, err = service.DeleteCounterexampleWithContext(applier.ctx, service.NewDeleteCounterexampleOptions(applier.workspaceID, change.Text))
+		}
+		if err != nil {
+			return
+		}
+	}
+	return
+}
+
+func (applier *applier) intents(changes []IntentChange) (err error) {
+	service := applier.service
+	for _, change := range changes {
+		switch change.Kind {
+		case ChangeKindAddedConst:
+			options := service.NewCreateIntentOptions(applier.workspaceID, change.Intent)
+			options.Description = change.To.Description
+			options.Examples = change.To.Examples
+			_, _, err = service.CreateIntentWithContext(applier.ctx, options)
+		case ChangeKindRemovedConst:
+			_, err = service.DeleteIntentWithContext(applier.ctx, service.NewDeleteIntentOptions(applier.workspaceID, change.Intent))
+		case ChangeKindModifiedConst:
+			if len(change.Fields) > 0 {
+				options := service.NewUpdateIntentOptions(applier.workspaceID, change.Intent)
+				options.NewDescription = emptyIfNil(change.To.Description)
+				_, _, err = service.UpdateIntentWithContext(applier.ctx, options)
+			}
+			for _, example := range change.Examples {
+				if err != nil {
+					break
+				}
+				err = applier.example(change.Intent, example)
+			}
+		}
+		if err != nil {
+			return
+		}
+	}
+	return
+}
+
+func (applier *applier) example(intent string, change ExampleChange) (err error) {
+	service := applier.service
+	if change.Kind != ChangeKindAddedConst {
+		_, err = service.DeleteExampleWithContext(applier.ctx, service.NewDeleteExampleOptions(applier.workspaceID, intent, change.Text))
+		if err != nil || change.Kind == ChangeKindRemovedConst {
+			return
+		}
+	}
+	options := service.NewCreateExampleOptions(applier.workspaceID, intent, change.Text)
+	options.Mentions = change.To.Mentions
+	_, _, err = service.CreateExampleWithContext(applier.ctx, options)
+	return
+}
+
+func (applier *applier) entities(changes []EntityChange) (err error) {
+	service := applier.service
+	for _, change := range changes {
+		switch change.Kind {
+		case ChangeKindAddedConst:
+			entity := createEntity(*change.To)
+			options := service.NewCreateEntityOptions(applier.workspaceID, change.Entity)
+			options.Description, options.Metadata, options.FuzzyMatch, options.Values = entity.Description, entity.Metadata, entity.FuzzyMatch, entity.Values
+			_, _, err = service.CreateEntityWithContext(applier.ctx, options)
+		case ChangeKindRemovedConst:
+			_, err = service.DeleteEntityWithContext(applier.ctx, service.NewDeleteEntityOptions(applier.workspaceID, change.Entity))
+		case ChangeKindModifiedConst:
+			if len(change.Fields) > 0 {
+				options := service.NewUpdateEntityOptions(applier.workspaceID, change.Entity)
+				options.NewDescription = emptyIfNil(change.To.Description)
+				options.NewMetadata = change.To.Metadata
+				options.NewFuzzyMatch = core.BoolPtr(change.To.FuzzyMatch != nil && *change.To.FuzzyMatch)
+				_, _, err = service.UpdateEntityWithContext(applier.ctx, options)
+			}
+			for _, value := range change.Values {
+				if err != nil {
+					break
+				}
+				err = applier.value(change.Entity, value)
+			}
+		}
+		if err != nil {
+			return
+		}
+	}
+	return
+}
+
+func (applier *applier) value(entity string, change ValueChange) (err error) {
+	service := applier.service
+	if change.Kind == ChangeKindModifiedConst && len(change.Fields) == 0 {
+		for _, synonym := range change.Synonyms {
+			if synonym.Kind == ChangeKindAddedConst {
+				_, _, err = service.CreateSynonymWithContext(applier.ctx, service.NewCreateSynonymOptions(applier.workspaceID, entity, change.Value, synonym.Synonym))
+			} else {
+				_, err = service.DeleteSynonymWithContext(applier.ctx, service.NewDeleteSynonymOptions(applier.workspaceID, entity, change.Value, synonym.Synonym))
+			}
+			if err != nil {
+				return
+			}
+		}
+		return
+	}
+
+	if change.Kind != ChangeKindAddedConst {
+		_, err = service.DeleteValueWithContext(applier.ctx, service.NewDeleteValueOptions(applier.workspaceID, entity, change.Value))
+		if err != nil || change.Kind == ChangeKindRemovedConst {
+			return
+		}
+	}
+	value := createValue(*change.To)
+	options := service.NewCreateValueOptions(applier.workspaceID, entity, change.Value)
+	options.Metadata, options.Type, options.Synonyms, options.Patterns = value.Metadata, value.Type, value.Synonyms, value.Patterns
+	_, _, err = service.CreateValueWithContext(applier.ctx, options)
+	return
+}
+
+func (applier *applier) dialogNodes(changes []DialogNodeChange) (err error) {
+	service := applier.service
+	var added, modified []DialogNodeChange
+	removed := map[string]bool{}
+	for _, change := range changes {
+		switch change.Kind {
+		case ChangeKindAddedConst:
+			added = append(added, change)
+		case ChangeKindModifiedConst:
+			modified = append(modified, change)
+		case ChangeKindRemovedConst:
+			removed[change.DialogNode] = true
+		}
+	}
+
+	for _, change := range dependencyOrder(added) {
+		node := change.To
+		options := service.NewCreateDialogNodeOptions(applier.workspaceID, change.DialogNode)
+		options.Description, options.Conditions, options.Parent, options.PreviousSibling = node.Description, node.Conditions, node.Parent, node.PreviousSibling
+		options.Output, options.Context, options.Metadata, options.NextStep = node.Output, node.Context, node.Metadata, node.NextStep
+		options.Title, options.Type, options.EventName, options.Variable = node.Title, node.Type, node.EventName, node.Variable
+		options.Actions, options.DigressIn, options.DigressOut, options.DigressOutSlots = node.Actions, node.DigressIn, node.DigressOut, node.DigressOutSlots
+		options.UserLabel, options.DisambiguationOptOut = node.UserLabel, node.DisambiguationOptOut
+		if _, _, err = service.CreateDialogNodeWithContext(applier.ctx, options); err != nil {
+			return
+		}
+	}
+
+	for _, change := range dependencyOrder(modified) {
+		node, old := change.To, change.From
+		options := service.NewUpdateDialogNodeOptions(applier.workspaceID, change.DialogNode)
+		// Fields that the node no longer has are sent empty, which clears them
+		options.NewDescription = clearedString(node.Description, old.Description)
+		options.NewConditions = clearedString(node.Conditions, old.Conditions)
+		options.NewParent = clearedString(node.Parent, old.Parent)
+		options.NewPreviousSibling = clearedString(node.PreviousSibling, old.PreviousSibling)
+		options.NewTitle = clearedString(node.Title, old.Title)
+		options.NewType = node.Type
+		options.NewEventName = node.EventName
+		options.NewVariable = clearedString(node.Variable, old.Variable)
+		options.NewDigressIn = node.DigressIn
+		options.NewDigressOut = node.DigressOut
+		options.NewDigressOutSlots = node.DigressOutSlots
+		options.NewUserLabel = clearedString(node.UserLabel, old.UserLabel)
+		options.NewOutput, options.NewContext = node.Output, node.Context
+		if node.Output == nil && old.Output != nil {
+			options.NewOutput = &assistantv1.DialogNodeOutput{}
+		}
+		if node.Context == nil && old.Context != nil {
+			options.NewContext = &assistantv1.DialogNodeContext{}
+		}
+		options.NewMetadata, options.NewNextStep, options.NewActions = node.Metadata, node.NextStep, node.Actions
+		options.NewDisambiguationOptOut = node.DisambiguationOptOut
+		if node.DisambiguationOptOut == nil && old.DisambiguationOptOut != nil {
+			options.NewDisambiguationOptOut = core.BoolPtr(false)
+		}
+		if _, _, err = service.UpdateDialogNodeWithContext(applier.ctx, options); err != nil {
+			return
+		}
+	}
+
+	// Deleting a node deletes its descendants, so only the topmost removed nodes are deleted
+	for _, change := range changes {
+		if change.Kind != ChangeKindRemovedConst || removed[core.StringNilMapper(change.From.Parent)] {
+			continue
+		}
+		if _, err = service.DeleteDialogNodeWithContext(applier.ctx, service.NewDeleteDialogNodeOptions(applier.workspaceID, change.DialogNode)); err != nil {
+			return
+		}
+	}
+	return
+}
+
+// dependencyOrder : Orders dialog node changes so that a node comes after its parent and previous sibling when they
+// are among the changes. Nodes in a cycle keep their order.
+func dependencyOrder(changes []DialogNodeChange) []DialogNodeChange {
+	byID := map[string]int{}
+	for i, change := range changes {
+		byID[change.DialogNode] = i
+	}
+	visited := make([]int, len(changes))
+	var ordered []DialogNodeChange
+	var visit func(i int)
+	visit = func(i int) {
+		if visited[i] != 0 {
+			return
+		}
+		visited[i] = 1
+		for _, dependency := range []*string{changes[i].To.Parent, changes[i].To.PreviousSibling} {
+			if j, ok := byID[core.StringNilMapper(dependency)]; ok && dependency != nil {
+				visit(j)
+			}
+		}
+		visited[i] = 2
+		ordered = append(ordered, changes[i])
+	}
+	for i := range changes {
+		visit(i)
+	}
+	return ordered
+}
+
+func createIntent(intent assistantv1.Intent) assistantv1.CreateIntent {
+	return assistantv1.CreateIntent{Intent: intent.Intent, Description: intent.Description, Examples: intent.Examples}
+}
+
+func createEntity(entity assistantv1.Entity) assistantv1.CreateEntity {
+	created := assistantv1.CreateEntity{
+		Entity:      entity.Entity,
+		Description: entity.Description,
+		Metadata:    entity.Metadata,
+		FuzzyMatch:  entity.FuzzyMatch,
+	}
+	for _, value := range entity.Values {
+		created.Values = append(created.Values, createValue(value))
+	}
+	return created
+}
+
+func createValue(value assistantv1.Value) assistantv1.CreateValue {
+	return assistantv1.CreateValue{
+		Value:    value.Value,
+		Metadata: value.Metadata,
+		Type:     value.Type,
+		Synonyms: value.Synonyms,
+		Patterns: value.Patterns,
+	}
+}
+
+// emptyIfNil : Returns the value, or an empty string to clear the field when there is none
+func emptyIfNil(value *string) *string {
+	if value == nil {
+		return core.StringPtr("")
+	}
+	return value
+}
+
+// clearedString : Returns the new value of a field, or an empty string when the field had a value and no longer has
+func clearedString(value *string, old *string) *string {
+	if value == nil && old != nil {
+		return core.StringPtr("")
+	}
+	return value
+}
@@ -0,0 +1,230 @@
+/**
+ * (C) Copyright IBM Corp. 2021.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package workspace
+
+import (
+	"bufio"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/IBM/go-sdk-core/v5/core"
+	"github.com/watson-developer-cloud/go-sdk/v2/assistantv1"
+)
+
+// byteOrderMark is written by spreadsheet applications at the start of UTF-8 CSV files
+const byteOrderMark = "\xef\xbb\xbf"
+
+// ReadIntentsCSV : Reads intents in the CSV format of the Watson Assistant tooling, one `example,intent` record per
+// user input example. Examples are grouped by intent; a leading `#` on intent names is dropped, as are duplicate
+// examples. Intents and examples are returned sorted.
+func ReadIntentsCSV(reader io.Reader) ([]assistantv1.Intent, error) {
+	examples := map[string]map[string]bool{}
+	err := readToolingCSV(reader, func(recordNumber int, record []string) error {
+		if len(record) < 2 {
+			return fmt.Errorf("record %d: expected an example and an intent", recordNumber)
+		}
+		text := strings.TrimSpace(record[0])
+		intent := strings.TrimPrefix(strings.TrimSpace(record[1]), "#")
+		if text == "" || intent == "" {
+			return fmt.Errorf("record %d: missing example or intent", recordNumber)
+		}
+		if examples[intent] == nil {
+			examples[intent] = map[string]bool{}
+		}
+		examples[intent][text] = true
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	names := make(map[string]bool, len(examples))
+	for name := range examples {
+		names[name] = true
+	}
+	intents := make([]assistantv1.Intent, 0, len(examples))
+	for _, name := range sortedSet(names) {
+		intent := assistantv1.Intent{Intent: core.StringPtr(name)}
+		for _, text := range sortedSet(examples[name]) {
+			intent.Examples = append(intent.Examples, assistantv1.Example{Text: core.StringPtr(text)})
+		}
+		intents = append(intents, intent)
+	}
+	return intents, nil
+}
+
+// WriteIntentsCSV : Writes intents in the CSV format of the Watson Assistant tooling, sorted by intent and example.
+// Intent descriptions and example mentions have no place in the format and are not written.
+func WriteIntentsCSV(writer io.Writer, intents []assistantv1.Intent) error {
+	var records [][]string
+	for _, intent := range intents {
+		for _, example := range intent.Examples {
+			records = append(records, []string{core.StringNilMapper(example.Text), core.StringNilMapper(intent.Intent)})
+		}
+	}
+	sort.Slice(records, func(i, j int) bool {
+		if records[i][1] != records[j][1] {
+			return records[i][1] < records[j][1]
+		}
+		return records[i][0] < records[j][0]
+	})
+	csvWriter := csv.NewWriter(writer)
+	if err := csvWriter.WriteAll(records); err != nil {
+		return err
+	}
+	return csvWriter.Error()
+}
+
+// ReadEntitiesCSV : Reads entities in the CSV format of the Watson Assistant tooling: `entity,value,synonym...`
+// records for values with synonyms and `entity,value,/pattern/` records for values with patterns, the pattern
+// between slashes. Records for the same value are merged; a leading `@` on entity names is dropped. A value cannot
+// have both synonyms and patterns. Entities, values and synonyms are returned sorted.
+func ReadEntitiesCSV(reader io.Reader) ([]assistantv1.Entity, error) {
+	type valueContent struct {
+		synonyms map[string]bool
+		patterns []string
+	}
+	entities := map[string]map[string]*valueContent{}
+	err := readToolingCSV(reader, func(recordNumber int, record []string) error {
+		if len(record) < 2 {
+			return fmt.Errorf("record %d: expected an entity and a value", recordNumber)
+		}
+		entity := strings.TrimPrefix(strings.TrimSpace(record[0]), "@")
+		value := strings.TrimSpace(record[1])
+		if entity == "" || value == "" {
+			return fmt.Errorf("record %d: missing entity or value", recordNumber)
+		}
+		if entities[entity] == nil {
+			entities[entity] = map[string]*valueContent{}
+		}
+		content := entities[entity][value]
+		if content == nil {
+			content = &valueContent{synonyms: map[string]bool{}}
+			entities[entity][value] = content
+		}
+		for _, field := range record[2:] {
+			field = strings.TrimSpace(field)
+			switch {
+			case field == "":
+			case len(field) > 1 && strings.HasPrefix(field, "/") && strings.HasSuffix(field, "/"):
+				content.patterns = append(content.patterns, field[1:len(field)-1])
+			default:
+				content.synonyms[field] = true
+			}
+		}
+		if len(content.patterns) > 0 && len(content.synonyms) > 0 {
+			return fmt.Errorf("record %d: value %q of entity %q has both synonyms and patterns", recordNumber, value, entity)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	names := make(map[string]bool, len(entities))
+	for name := range entities {
+		names[name] = true
+	}
+	result := make([]assistantv1.Entity, 0, len(entities))
+	for _, name := range sortedSet(names) {
+		entity := assistantv1.Entity{Entity: core.StringPtr(name)}
+		values := map[string]bool{}
+		for valueName := range entities[name] {
+			values[valueName] = true
+		}
+		for _, valueName := range sortedSet(values) {
+			content := entities[name][valueName]
+			value := assistantv1.Value{Value: core.StringPtr(valueName), Type: core.StringPtr(assistantv1.ValueTypeSynonymsConst)}
+			if len(content.patterns) > 0 {
+				value.Type = core.StringPtr(assistantv1.ValueTypePatternsConst)
+				value.Patterns = content.patterns
+			} else {
+				value.Synonyms = sortedSet(content.synonyms)
+			}
+			entity.Values = append(entity.Values, value)
+		}
+		result = append(result, entity)
+	}
+	return result, nil
+}
+
+// WriteEntitiesCSV : Writes entities in the CSV format of the Watson Assistant tooling, one record per value, sorted
+// by entity and value, with patterns between slashes. Values without synonyms or patterns are written on their own.
+func WriteEntitiesCSV(writer io.Writer, entities []assistantv1.Entity) error {
+	var records [][]string
+	for _, entity := range entities {
+		for _, value := range entity.Values {
+			record := []string{core.StringNilMapper(entity.Entity), core.StringNilMapper(value.Value)}
+			if core.StringNilMapper(value.Type) == assistantv1.ValueTypePatternsConst {
+				for _, pattern := range value.Patterns {
+					record = append(record, "/"+pattern+"/")
+				}
+			} else {
+				synonyms := append([]string{}, value.Synonyms...)
+				sort.Strings(synonyms)
+				record = append(record, synonyms...)
+			}
+			records = append(records, record)
+		}
+	}
+	sort.SliceStable(records, func(i, j int) bool {
+		if records[i][0] != records[j][0] {
+			return records[i][0] < records[j][0]
+		}
+		return records[i][1] < records[j][1]
+	})
+	csvWriter := csv.NewWriter(writer)
+	if err := csvWriter.WriteAll(records); err != nil {
+		return err
+	}
+	return csvWriter.Error()
+}
+
+// readToolingCSV : Calls fn for each record of a CSV file, numbered from 1, skipping a byte order mark
+func readToolingCSV(reader io.Reader, fn func(recordNumber int, record []string) error) error {
+	buffered := bufio.NewReader(reader)
+	if prefix, err := buffered.Peek(len(byteOrderMark)); err == nil && string(prefix) == byteOrderMark {
+		_, _ = buffered.Discard(len(byteOrderMark))
+	}
+	csvReader := csv.NewReader(buffered)
+	csvReader.FieldsPerRecord = -1
+	for recordNumber := 1; ; recordNumber++ {
+		record, err := csvReader.Read()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if err = fn(recordNumber, record); err != nil {
+			return err
+		}
+	}
+}
+
+// sortedSet : Returns the members of a set of strings, sorted
+func sortedSet(set map[string]bool) []string {
+	members := make([]string, 0, len(set))
+	for member := range set {
+		members = append(members, member)
+	}
+	sort.Strings(members)
+	return members
+}
@@ -0,0 +1,150 @@
+/**
+ * (C) Copyright IBM Corp. 2021.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package workspace_test
+
+import (
+	"bytes"
+	"context"
+	"net/http/httptest"
+	"strings"
+
+	"github.com/IBM/go-sdk-core/v5/core"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/watson-developer-cloud/go-sdk/v2/assistantv1"
+	"github.com/watson-developer-cloud/go-sdk/v2/assistantv1/workspace"
+)
+
+var _ = Describe(`CSV`, func() {
+	It(`Read and write intents`, func() {
+		intents, err := workspace.ReadIntentsCSV(strings.NewReader("\xef\xbb\xbf" +
+			"what is my balance,#balance\n" +
+			"\"send money, please\",transfer\n" +
+			"how much money,balance\n" +
+			"what is my balance,balance\n"))
+		Expect(err).To(BeNil())
+		Expect(intents).To(HaveLen(2))
+		Expect(*intents[0].Intent).To(Equal("balance"))
+		Expect(intents[0].Examples).To(HaveLen(2))
+		Expect(*intents[0].Examples[0].Text).To(Equal("how much money"))
+		Expect(*intents[1].Examples[0].Text).To(Equal("send money, please"))
+
+		var buffer bytes.Buffer
+		Expect(workspace.WriteIntentsCSV(&buffer, intents)).To(Succeed())
+		Expect(buffer.String()).To(Equal("how much money,balance\n" +
+			"what is my balance,balance\n" +
+			"\"send money, please\",transfer\n"))
+	})
+
+	It(`Read and write entities`, func() {
+		entities, err := workspace.ReadEntitiesCSV(strings.NewReader(
+			"@account,savings,saving,deposit\n" +
+				"account,checking,current\n" +
+				"account,savings,rainy day\n" +
+				"number,iban,/[A-Z]{2}[0-9]{2}[A-Z0-9]+/\n"))
+		Expect(err).To(BeNil())
+		Expect(entities).To(HaveLen(2))
+		Expect(*entities[0].Entity).To(Equal("account"))
+		Expect(*entities[0].Values[1].Value).To(Equal("savings"))
+		Expect(entities[0].Values[1].Synonyms).To(Equal([]string{"deposit", "rainy day", "saving"}))
+		Expect(*entities[1].Values[0].Type).To(Equal(assistantv1.ValueTypePatternsConst))
+		Expect(entities[1].Values[0].Patterns).To(Equal([]string{"[A-Z]{2}[0-9]{2}[A-Z0-9]+"}))
+
+		var buffer bytes.Buffer
+		Expect(workspace.WriteEntitiesCSV(&buffer, entities)).To(Succeed())
+		Expect(buffer.String()).To(Equal("account,checking,current\n" +
+			"account,savings,deposit,rainy day,saving\n" +
+			"number,iban,/[A-Z]{2}[0-9]{2}[A-Z0-9]+/\n"))
+	})
+
+	It(`Reject malformed records`, func() {
+		_, err := workspace.ReadIntentsCSV(strings.NewReader("hello,greeting\nno intent\n"))
+		Expect(err).To(MatchError(ContainSubstring("record 2")))
+		_, err = workspace.ReadEntitiesCSV(strings.NewReader("number,iban,/[A-Z]+/,bank number\n"))
+		Expect(err).To(MatchError(ContainSubstring("both synonyms and patterns")))
+	})
+})
+
+var _ = Describe(`Upload`, func() {
+	var standIn *recorder
+	var testServer *httptest.Server
+	var assistantService *assistantv1.AssistantV1
+
+	BeforeEach(func() {
+		standIn = &recorder{workspace: current}
+		testServer = httptest.NewServer(standIn)
+		var err error
+		assistantService, err = assistantv1.NewAssistantV1(&assistantv1.AssistantV1Options{
+			URL:           testServer.URL,
+			Version:       core.StringPtr("2021-06-14"),
+			Authenticator: &core.NoAuthAuthenticator{},
+		})
+		Expect(err).To(BeNil())
+	})
+	AfterEach(func() {
+		testServer.Close()
+	})
+
+	readContent := func() ([]assistantv1.Intent, []assistantv1.Entity) {
+		intents, err := workspace.ReadIntentsCSV(strings.NewReader("my balance,balance\nbalance please,balance\nsend money,transfer\n"))
+		Expect(err).To(BeNil())
+		entities, err := workspace.ReadEntitiesCSV(strings.NewReader("account,savings,saving,rainy day\naccount,loan,/L[0-9]+/\n"))
+		Expect(err).To(BeNil())
+		return intents, entities
+	}
+
+	It(`Add the missing content`, func() {
+		intents, entities := readContent()
+		diff, err := workspace.Upload(context.Background(), assistantService, "ws1", intents, entities, nil)
+		Expect(err).To(BeNil())
+		Expect(diff.String()).ToNot(BeEmpty())
+		Expect(standIn.requests).To(Equal([]string{
+			"GET ",
+			"POST /entities/account/values",
+			"POST /entities/account/values/savings/synonyms",
+			"POST /intents/balance/examples",
+			"POST /intents",
+		}))
+		Expect(standIn.bodies[1]["patterns"]).To(Equal([]interface{}{"L[0-9]+"}))
+		Expect(standIn.bodies[4]["intent"]).To(Equal("transfer"))
+	})
+
+	It(`Remove the content that was not uploaded when pruning`, func() {
+		intents, entities := readContent()
+		_, err := workspace.Upload(context.Background(), assistantService, "ws1", intents, entities, &workspace.UploadOptions{Prune: true})
+		Expect(err).To(BeNil())
+		Expect(standIn.requests).To(ContainElement("DELETE /intents/balance/examples/how much money"))
+		Expect(standIn.requests).To(ContainElement("DELETE /entities/account/values/savings/synonyms/deposit"))
+		Expect(standIn.requests).To(ContainElement("DELETE /entities/account/values/checking"))
+		Expect(standIn.requests).ToNot(ContainElement("DELETE /intents/goodbye"))
+	})
+
+	It(`Make no changes for content the workspace already has`, func() {
+		diff, err := workspace.Upload(context.Background(), assistantService, "ws1", mustUnmarshal(current).Intents, mustUnmarshal(current).Entities, nil)
+		Expect(err).To(BeNil())
+		Expect(diff.IsEmpty()).To(BeTrue())
+		Expect(standIn.requests).To(Equal([]string{"GET "}))
+	})
+
+	It(`Make no calls on a dry run`, func() {
+		intents, entities := readContent()
+		diff, err := workspace.Upload(context.Background(), assistantService, "ws1", intents, entities, &workspace.UploadOptions{DryRun: true})
+		Expect(err).To(BeNil())
+		Expect(diff.IsEmpty()).To(BeFalse())
+		Expect(standIn.requests).To(Equal([]string{"GET "}))
+	})
+})
@@ -0,0 +1,514 @@
+/**
+ * (C) Copyright IBM Corp. 2021.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package workspace
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/IBM/go-sdk-core/v5/core"
+	"github.com/watson-developer-cloud/go-sdk/v2/assistantv1"
+)
+
+// Kinds of change
+const (
+	ChangeKindAddedConst    = "added"
+	ChangeKindModifiedConst = "modified"
+	ChangeKindRemovedConst  = "removed"
+)
+
+// Diff : What differs between two workspaces, as computed by Compute. The changes of each section are sorted by
+// name; From is nil for additions and To for removals.
+type Diff struct {
+	// The workspace-level fields that differ: name, description, language, learning_opt_out, metadata,
+	// system_settings and webhooks.
+	Settings []FieldChange `json:"settings,omitempty"`
+
+	Intents         []IntentChange         `json:"intents,omitempty"`
+	Entities        []EntityChange         `json:"entities,omitempty"`
+	Counterexamples []CounterexampleChange `json:"counterexamples,omitempty"`
+	DialogNodes     []DialogNodeChange     `json:"dialog_nodes,omitempty"`
+
+	// The normalized target workspace, for Apply.
+	target *assistantv1.Workspace
+}
+
+// FieldChange : A workspace-level field that differs
+type FieldChange struct {
+	Field string      `json:"field"`
+	From  interface{} `json:"from,omitempty"`
+	To    interface{} `json:"to,omitempty"`
+}
+
+// IntentChange : An intent that was added, removed or modified
+type IntentChange struct {
+	Kind   string `json:"kind"`
+	Intent string `json:"intent"`
+
+	// The intent fields that differ, other than its examples.
+	Fields   []string            `json:"fields,omitempty"`
+	Examples []ExampleChange     `json:"examples,omitempty"`
+	From     *assistantv1.Intent `json:"-"`
+	To       *assistantv1.Intent `json:"-"`
+}
+
+// ExampleChange : A user input example of an intent that was added, removed or modified; examples are modified
+// when their mentions differ
+type ExampleChange struct {
+	Kind string               `json:"kind"`
+	Text string               `json:"text"`
+	From *assistantv1.Example `json:"-"`
+	To   *assistantv1.Example `json:"-"`
+}
+
+// EntityChange : An entity that was added, removed or modified
+type EntityChange struct {
+	Kind   string `json:"kind"`
+	Entity string `json:"entity"`
+
+	// The entity fields that differ, other than its values.
+	Fields []string            `json:"fields,omitempty"`
+	Values []ValueChange       `json:"values,omitempty"`
+	From   *assistantv1.Entity `json:"-"`
+	To     *assistantv1.Entity `json:"-"`
+}
+
+// ValueChange : An entity value that was added, removed or modified
+type ValueChange struct {
+	Kind  string `json:"kind"`
+	Value string `json:"value"`
+
+	// The value fields that differ, other than its synonyms.
+	Fields   []string           `json:"fields,omitempty"`
+	Synonyms []SynonymChange    `json:"synonyms,omitempty"`
+	From     *assistantv1.Value `json:"-"`
+	To       *assistantv1.Value `json:"-"`
+}
+
+// SynonymChange : A synonym of an entity value that was added or removed
+type SynonymChange struct {
+	Kind    string `json:"kind"`
+	Synonym string `json:"synonym"`
+}
+
+// CounterexampleChange : A counterexample that was added or removed
+type CounterexampleChange struct {
+	Kind string `json:"kind"`
+	Text string `json:"text"`
+}
+
+// DialogNodeChange : A dialog node that was added, removed or modified
+type DialogNodeChange struct {
+	Kind       string `json:"kind"`
+	DialogNode string `json:"dialog_node"`
+
+	// The dialog node fields that differ, by their JSON names.
+	Fields []string                `json:"fields,omitempty"`
+	From   *assistantv1.DialogNode `json:"-"`
+	To     *assistantv1.DialogNode `json:"-"`
+}
+
+// IsEmpty : Reports whether the workspaces are the same
+func (diff *Diff) IsEmpty() bool {
+	return len(diff.Settings) == 0 && len(diff.Intents) == 0 && len(diff.Entities) == 0 &&
+		len(diff.Counterexamples) == 0 && len(diff.DialogNodes) == 0
+}
+
+// String : Returns the changes one per line, in the style of a patch summary: `+` for additions, `-` for removals
+// and `~` for modifications, with the fields that differ in brackets
+func (diff *Diff) String() string {
+	var lines []string
+	add := func(indent string, kind string, format string, args ...interface{}) {
+		symbol := map[string]string{ChangeKindAddedConst: "+", ChangeKindModifiedConst: "~", ChangeKindRemovedConst: "-"}[kind]
+		lines = append(lines, indent+symbol+" "+fmt.Sprintf(format, args...))
+	}
+	fields := func(names []string) string {
+		if len(names) == 0 {
+			return ""
+		}
+		return " [" + strings.Join(names, ", ") + "]"
+	}
+
+	for _, change := range diff.Settings {
+		add("", ChangeKindModifiedConst, "%s", change.Field)
+	}
+	for _, intent := range diff.Intents {
+		add("", intent.Kind, "intent #%s%s", intent.Intent, fields(intent.Fields))
+		for _, example := range intent.Examples {
+			add("  ", example.Kind, "example %q", example.Text)
+		}
+	}
+	for _, entity := range diff.Entities {
+		add("", entity.Kind, "entity @%s%s", entity.Entity, fields(entity.Fields))
+		for _, value := range entity.Values {
+			add("  ", value.Kind, "value %q%s", value.Value, fields(value.Fields))
+			for _, synonym := range value.Synonyms {
+				add("    ", synonym.Kind, "synonym %q", synonym.Synonym)
+			}
+		}
+	}
+	for _, counterexample := range diff.Counterexamples {
+		add("", counterexample.Kind, "counterexample %q", counterexample.Text)
+	}
+	for _, node := range diff.DialogNodes {
+		add("", node.Kind, "dialog node %s%s", node.DialogNode, fields(node.Fields))
+	}
+	if len(lines) == 0 {
+		return ""
+	}
+	return strings.Join(lines, "\n") + "\n"
+}
+
+// Compute : Returns what has to change to turn the workspace from into the workspace to. Both are normalized first,
+// so timestamps, status and order do not count. Intents, entities, values and dialog nodes are matched by name and
+// examples, synonyms and counterexamples by text; a renamed element is a removal and an addition.
+func Compute(from *assistantv1.Workspace, to *assistantv1.Workspace) (*Diff, error) {
+	from, err := Normalize(from)
+	if err != nil {
+		return nil, err
+	}
+	to, err = Normalize(to)
+	if err != nil {
+		return nil, err
+	}
+	diff := &Diff{target: to}
+
+	settings := []struct {
+		field    string
+		from, to interface{}
+	}{
+		{"name", from.Name, to.Name},
+		{"description", from.Description, to.Description},
+		{"language", from.Language, to.Language},
+		{"learning_opt_out", from.LearningOptOut, to.LearningOptOut},
+		{"metadata", from.Metadata, to.Metadata},
+		{"system_settings", from.SystemSettings, to.SystemSettings},
+		{"webhooks", from.Webhooks, to.Webhooks},
+	}
+	for _, setting := range settings {
+		if !jsonEqual(setting.from, setting.to) {
+			diff.Settings = append(diff.Settings, FieldChange{Field: setting.field, From: setting.from, To: setting.to})
+		}
+	}
+
+	diff.Intents = diffIntents(from.Intents, to.Intents)
+	diff.Entities = diffEntities(from.Entities, to.Entities)
+	diff.Counterexamples = diffCounterexamples(from.Counterexamples, to.Counterexamples)
+	diff.DialogNodes = diffDialogNodes(from.DialogNodes, to.DialogNodes)
+	return diff, nil
+}
+
+func diffIntents(from []assistantv1.Intent, to []assistantv1.Intent) (changes []IntentChange) {
+	var fromNames, toNames []string
+	fromByName := map[string]*assistantv1.Intent{}
+	for i := range from {
+		fromByName[core.StringNilMapper(from[i].Intent)] = &from[i]
+		fromNames = append(fromNames, core.StringNilMapper(from[i].Intent))
+	}
+	toByName := map[string]*assistantv1.Intent{}
+	for i := range to {
+		toByName[core.StringNilMapper(to[i].Intent)] = &to[i]
+		toNames = append(toNames, core.StringNilMapper(to[i].Intent))
+	}
+	for _, name := range unionKeys(fromNames, toNames) {
+		before, after := fromByName[name], toByName[name]
+		change := IntentChange{Intent: name, From: before, To: after}
+		switch {
+		case before == nil:
+			change.Kind = ChangeKindAddedConst
+		case after == nil:
+			change.Kind = ChangeKindRemovedConst
+		default:
+			change.Kind = ChangeKindModifiedConst
+			if !jsonEqual(before.Description, after.Description) {
+				change.Fields = append(change.Fields, "description")
+			}
+			change.Examples = diffExamples(before.Examples, after.Examples)
+			if len(change.Fields) == 0 && len(change.Examples) == 0 {
+				continue
+			}
+		}
+		changes = append(changes, change)
+	}
+	return
+}
+
+func diffExamples(from []assistantv1.Example, to []assistantv1.Example) (changes []ExampleChange) {
+	var fromNames, toNames []string
+	fromByText := map[string]*assistantv1.Example{}
+	for i := range from {
+		fromByText[core.StringNilMapper(from[i].Text)] = &from[i]
+		fromNames = append(fromNames, core.StringNilMapper(from[i].Text))
+	}
+	toByText := map[string]*assistantv1.Example{}
+	for i := range to {
+		toByText[core.StringNilMapper(to[i].Text)] = &to[i]
+		toNames = append(toNames, core.StringNilMapper(to[i].Text))
+	}
+	for _, text := range unionKeys(fromNames, toNames) {
+		before, after := fromByText[text], toByText[text]
+		change := ExampleChange{Text: text, From: before, To: after}
+		switch {
+		case before == nil:
+			change.Kind = ChangeKindAddedConst
+		case after == nil:
+			change.Kind = ChangeKindRemovedConst
+		case jsonEqual(before.Mentions, after.Mentions):
+			continue
+		default:
+			change.Kind = ChangeKindModifiedConst
+		}
+		changes = append(changes, change)
+	}
+	return
+}
+
+func diffEntities(from []assistantv1.Entity, to []assistantv1.Entity) (changes []EntityChange) {
+	var fromNames, toNames []string
+	fromByName := map[string]*assistantv1.Entity{}
+	for i := range from {
+		fromByName[core.StringNilMapper(from[i].Entity)] = &from[i]
+		fromNames = append(fromNames, core.StringNilMapper(from[i].Entity))
+	}
+	toByName := map[string]*assistantv1.Entity{}
+	for i := range to {
+		toByName[core.StringNilMapper(to[i].Entity)] = &to[i]
+		toNames = append(toNames, core.StringNilMapper(to[i].Entity))
+	}
+	for _, name := range unionKeys(fromNames, toNames) {
+		before, after := fromByName[name], toByName[name]
+		change := EntityChange{Entity: name, From: before, To: after}
+		switch {
+		case before == nil:
+			change.Kind = ChangeKindAddedConst
+		case after == nil:
+			change.Kind = ChangeKindRemovedConst
+		default:
+			change.Kind = ChangeKindModifiedConst
+			change.Fields = changedFields(map[string][2]interface{}{
+				"description": {before.Description, after.Description},
+				"metadata":    {before.Metadata, after.Metadata},
+				"fuzzy_match": {before.FuzzyMatch, after.FuzzyMatch},
+			})
+			change.Values = diffValues(before.Values, after.Values)
+			if len(change.Fields) == 0 && len(change.Values) == 0 {
+				continue
+			}
+		}
+		changes = append(changes, change)
+	}
+	return
+}
+
+func diffValues(from []assistantv1.Value, to []assistantv1.Value) (changes []ValueChange) {
+	var fromNames, toNames []string
+	fromByName := map[string]*assistantv1.Value{}
+	for i := range from {
+		fromByName[core.StringNilMapper(from[i].Value)] = &from[i]
+		fromNames = append(fromNames, core.StringNilMapper(from[i].Value))
+	}
+	toByName := map[string]*assistantv1.Value{}
+	for i := range to {
+		toByName[core.StringNilMapper(to[i].Value)] = &to[i]
+		toNames = append(toNames, core.StringNilMapper(to[i].Value))
+	}
+	for _, name := range unionKeys(fromNames, toNames) {
+		before, after := fromByName[name], toByName[name]
+		change := ValueChange{Value: name, From: before, To: after}
+		switch {
+		case before == nil:
+			change.Kind = ChangeKindAddedConst
+		case after == nil:
+			change.Kind = ChangeKindRemovedConst
+		default:
+			change.Kind = ChangeKindModifiedConst
+			change.Fields = changedFields(map[string][2]interface{}{
+				"type":     {before.Type, after.Type},
+				"metadata": {before.Metadata, after.Metadata},
+				"patterns": {before.Patterns, after.Patterns},
+			})
+			diffStrings(before.Synonyms, after.Synonyms, func(kind string, synonym string) {
+				change.Synonyms = append(change.Synonyms, SynonymChange{Kind: kind, Synonym: synonym})
+			})
+			if len(change.Fields) == 0 && len(change.Synonyms) == 0 {
+				continue
+			}
+		}
+		changes = append(changes, change)
+	}
+	return
+}
+
+func diffCounterexamples(from []assistantv1.Counterexample, to []assistantv1.Counterexample) (changes []CounterexampleChange) {
+	texts := func(counterexamples []assistantv1.Counterexample) []string {
+		result := make([]string, len(counterexamples))
+		for i, counterexample := range counterexamples {
+			result[i] = core.StringNilMapper(counterexample.Text)
+		}
+		return result
+	}
+	diffStrings(texts(from), texts(to), func(kind string, text string) {
+		changes = append(changes, CounterexampleChange{Kind: kind, Text: text})
+	})
+	return
+}
+
+func diffDialogNodes(from []assistantv1.DialogNode, to []assistantv1.DialogNode) (changes []DialogNodeChange) {
+	var fromNames, toNames []string
+	fromByID := map[string]*assistantv1.DialogNode{}
+	for i := range from {
+		fromByID[core.StringNilMapper(from[i].DialogNode)] = &from[i]
+		fromNames = append(fromNames, core.StringNilMapper(from[i].DialogNode))
+	}
+	toByID := map[string]*assistantv1.DialogNode{}
+	for i := range to {
+		toByID[core.StringNilMapper(to[i].DialogNode)] = &to[i]
+		toNames = append(toNames, core.StringNilMapper(to[i].DialogNode))
+	}
+	for _, id := range unionKeys(fromNames, toNames) {
+		before, after := fromByID[id], toByID[id]
+		change := DialogNodeChange{DialogNode: id, From: before, To: after}
+		switch {
+		case before == nil:
+			change.Kind = ChangeKindAddedConst
+		case after == nil:
+			change.Kind = ChangeKindRemovedConst
+		default:
+			change.Kind = ChangeKindModifiedConst
+			change.Fields = changedJSONFields(before, after)
+			if len(change.Fields) == 0 {
+				continue
+			}
+		}
+		changes = append(changes, change)
+	}
+	return
+}
+
+// unionKeys : Returns the names of the elements of two sections, sorted
+func unionKeys(from []string, to []string) []string {
+	seen := map[string]bool{}
+	var keys []string
+	for _, key := range append(append([]string{}, from...), to...) {
+		if !seen[key] {
+			seen[key] = true
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// diffStrings : Calls change, in sorted order, for the strings of to that are not in from and for those of from that
+// are not in to
+func diffStrings(from []string, to []string, change func(kind string, value string)) {
+	inFrom := map[string]bool{}
+	for _, value := range from {
+		inFrom[value] = true
+	}
+	inTo := map[string]bool{}
+	for _, value := range to {
+		inTo[value] = true
+	}
+	for _, value := range unionKeys(from, to) {
+		switch {
+		case !inFrom[value]:
+			change(ChangeKindAddedConst, value)
+		case !inTo[value]:
+			change(ChangeKindRemovedConst, value)
+		}
+	}
+}
+
+// changedFields : Returns the names of the fields whose two values differ, sorted
+func changedFields(fields map[string][2]interface{}) (names []string) {
+	for name, values := range fields {
+		if !jsonEqual(values[0], values[1]) {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return
+}
+
+// changedJSONFields : Returns the JSON names of the fields that differ between two values of the same type, sorted
+func changedJSONFields(from interface{}, to interface{}) []string {
+	fromFields, toFields := jsonFields(from), jsonFields(to)
+	var names []string
+	for _, name := range unionKeys(mapNames(fromFields), mapNames(toFields)) {
+		if string(fromFields[name]) != string(toFields[name]) {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+func jsonFields(value interface{}) map[string]json.RawMessage {
+	fields := map[string]json.RawMessage{}
+	data, err := json.Marshal(value)
+	if err == nil {
+		_ = json.Unmarshal(data, &fields)
+	}
+	// Compact the values so that only their content is compared
+	for name, raw := range fields {
+		fields[name] = canonicalJSON(raw)
+	}
+	return fields
+}
+
+func mapNames(m map[string]json.RawMessage) []string {
+	names := make([]string, 0, len(m))
+	for name := range m {
+		names = append(names, name)
+	}
+	return names
+}
+
+// jsonEqual : Reports whether two values have the same JSON form, with object keys in any order
+func jsonEqual(a interface{}, b interface{}) bool {
+	dataA, errA := json.Marshal(a)
+	dataB, errB := json.Marshal(b)
+	if errA != nil || errB != nil {
+		return false
+	}
+	return string(canonicalJSON(dataA)) == string(canonicalJSON(dataB))
+}
+
+// canonicalJSON : Re-encodes JSON with sorted object keys; empty values, null and [] and {}, become null
+func canonicalJSON(data []byte) []byte {
+	var value interface{}
+	if err := json.Unmarshal(data, &value); err != nil {
+		return data
+	}
+	switch typed := value.(type) {
+	case []interface{}:
+		if len(typed) == 0 {
+			value = nil
+		}
+	case map[string]interface{}:
+		if len(typed) == 0 {
+			value = nil
+		}
+	}
+	canonical, err := json.Marshal(value)
+	if err != nil {
+		return data
+	}
+	return canonical
+}
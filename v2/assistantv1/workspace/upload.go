@@ -0,0 +1,186 @@
+/**
+ * (C) Copyright IBM Corp. 2021.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package workspace
+
+import (
+	"context"
+
+	"github.com/IBM/go-sdk-core/v5/core"
+	"github.com/watson-developer-cloud/go-sdk/v2/assistantv1"
+)
+
+// UploadOptions : Options for Upload
+type UploadOptions struct {
+	// Remove the examples of the uploaded intents, and the values, synonyms and patterns of the uploaded entities, that
+	// the uploaded content does not have. Intents and entities that the content does not mention are never removed.
+	Prune bool
+
+	// Compute the changes without making them.
+	DryRun bool
+}
+
+// Upload : Brings the intents and entities of a workspace of the service in line with uploaded content, such as the
+// content read by ReadIntentsCSV and ReadEntitiesCSV, and returns the changes made. The options may be nil.
+//
+// The uploaded intents and entities are merged into the exported workspace: missing intents, examples, entities,
+// values and synonyms are created, while the descriptions, example mentions and metadata already in the workspace
+// are kept. A value whose type changes between synonyms and patterns is replaced. The changes are made with Apply,
+// so uploading the same content again makes no calls beyond getting the workspace.
+func Upload(ctx context.Context, service *assistantv1.AssistantV1, workspaceID string, intents []assistantv1.Intent, entities []assistantv1.Entity, uploadOptions *UploadOptions) (*Diff, error) {
+	if uploadOptions == nil {
+		uploadOptions = &UploadOptions{}
+	}
+	current, _, err := service.GetWorkspaceWithContext(ctx, service.NewGetWorkspaceOptions(workspaceID).SetExport(true))
+	if err != nil {
+		return nil, err
+	}
+	target, err := clone(current)
+	if err != nil {
+		return nil, err
+	}
+	target.Intents = mergeIntents(target.Intents, intents, uploadOptions.Prune)
+	target.Entities = mergeEntities(target.Entities, entities, uploadOptions.Prune)
+
+	diff, err := Compute(current, target)
+	if err != nil {
+		return nil, err
+	}
+	if uploadOptions.DryRun || diff.IsEmpty() {
+		return diff, nil
+	}
+	return diff, Apply(ctx, service, workspaceID, diff, nil)
+}
+
+// mergeIntents : Merges uploaded intents into existing ones
+func mergeIntents(existing []assistantv1.Intent, uploaded []assistantv1.Intent, prune bool) []assistantv1.Intent {
+	index := map[string]int{}
+	for i := range existing {
+		index[core.StringNilMapper(existing[i].Intent)] = i
+	}
+	for _, intent := range uploaded {
+		i, found := index[core.StringNilMapper(intent.Intent)]
+		if !found {
+			index[core.StringNilMapper(intent.Intent)] = len(existing)
+			existing = append(existing, assistantv1.Intent{Intent: intent.Intent, Examples: intent.Examples})
+			continue
+		}
+		texts := map[string]bool{}
+		for _, example := range intent.Examples {
+			texts[core.StringNilMapper(example.Text)] = true
+		}
+		var examples []assistantv1.Example
+		for _, example := range existing[i].Examples {
+			if !prune || texts[core.StringNilMapper(example.Text)] {
+				examples = append(examples, example)
+			}
+			delete(texts, core.StringNilMapper(example.Text))
+		}
+		for _, example := range intent.Examples {
+			if texts[core.StringNilMapper(example.Text)] {
+				examples = append(examples, assistantv1.Example{Text: example.Text})
+				delete(texts, core.StringNilMapper(example.Text))
+			}
+		}
+		existing[i].Examples = examples
+	}
+	return existing
+}
+
+// mergeEntities : Merges uploaded entities into existing ones
+func mergeEntities(existing []assistantv1.Entity, uploaded []assistantv1.Entity, prune bool) []assistantv1.Entity {
+	index := map[string]int{}
+	for i := range existing {
+		index[core.StringNilMapper(existing[i].Entity)] = i
+	}
+	for _, entity := range uploaded {
+		i, found := index[core.StringNilMapper(entity.Entity)]
+		if !found {
+			index[core.StringNilMapper(entity.Entity)] = len(existing)
+			existing = append(existing, assistantv1.Entity{Entity: entity.Entity, Values: entity.Values})
+			continue
+		}
+		existing[i].Values = mergeValues(existing[i].Values, entity.Values, prune)
+	}
+	return existing
+}
+
+// mergeValues : Merges uploaded entity values into existing ones
+func mergeValues(existing []assistantv1.Value, uploaded []assistantv1.Value, prune bool) []assistantv1.Value {
+	uploadedByName := map[string]*assistantv1.Value{}
+	for i := range uploaded {
+		value := uploaded[i]
+		value.Type = core.StringPtr(valueType(value))
+		uploadedByName[core.StringNilMapper(value.Value)] = &value
+	}
+	var values []assistantv1.Value
+	for _, value := range existing {
+		name := core.StringNilMapper(value.Value)
+		update := uploadedByName[name]
+		delete(uploadedByName, name)
+		switch {
+		case update == nil:
+			if !prune {
+				values = append(values, value)
+			}
+		case valueType(value) != valueType(*update):
+			values = append(values, *update)
+		case valueType(value) == assistantv1.ValueTypePatternsConst:
+			value.Patterns = mergeStrings(value.Patterns, update.Patterns, prune)
+			values = append(values, value)
+		default:
+			value.Synonyms = mergeStrings(value.Synonyms, update.Synonyms, prune)
+			values = append(values, value)
+		}
+	}
+	for _, value := range uploaded {
+		if added := uploadedByName[core.StringNilMapper(value.Value)]; added != nil {
+			values = append(values, *added)
+			delete(uploadedByName, core.StringNilMapper(value.Value))
+		}
+	}
+	return values
+}
+
+// mergeStrings : Returns the existing strings followed by the uploaded ones they lack, without the existing strings
+// that were not uploaded when pruning
+func mergeStrings(existing []string, uploaded []string, prune bool) (merged []string) {
+	remaining := map[string]bool{}
+	for _, s := range uploaded {
+		remaining[s] = true
+	}
+	for _, s := range existing {
+		if !prune || remaining[s] {
+			merged = append(merged, s)
+		}
+		delete(remaining, s)
+	}
+	for _, s := range uploaded {
+		if remaining[s] {
+			merged = append(merged, s)
+			delete(remaining, s)
+		}
+	}
+	return
+}
+
+// valueType : Returns the type of an entity value, which defaults to synonyms
+func valueType(value assistantv1.Value) string {
+	if value.Type == nil {
+		return assistantv1.ValueTypeSynonymsConst
+	}
+	return *value.Type
+}
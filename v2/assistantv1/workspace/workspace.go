@@ -0,0 +1,158 @@
+/**
+ * (C) Copyright IBM Corp. 2021.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package workspace keeps Watson Assistant v1 workspaces as code.
+//
+// Marshal writes a Workspace, as returned by GetWorkspace with export, in a deterministic JSON form that suits
+// version control: intents, examples, entities, values, synonyms, counterexamples and dialog nodes are sorted, and
+// the fields that the service changes on its own, such as the created and updated timestamps and the training
+// status, are left out. Compute finds what differs between two workspaces, and Apply makes those changes to a
+// workspace of the service, either with the fine-grained create, update and delete methods or with a single
+// UpdateWorkspace call.
+//
+// ReadIntentsCSV, ReadEntitiesCSV and the matching writers convert intents and entities to and from the CSV files of
+// the Watson Assistant tooling, and Upload merges such content into a workspace of the service.
+package workspace
+
+import (
+	"encoding/json"
+	"sort"
+
+	"github.com/IBM/go-sdk-core/v5/core"
+	"github.com/watson-developer-cloud/go-sdk/v2/assistantv1"
+)
+
+// Marshal : Returns the deterministic JSON form of a workspace, indented and ending with a newline
+func Marshal(workspace *assistantv1.Workspace) ([]byte, error) {
+	normalized, err := Normalize(workspace)
+	if err != nil {
+		return nil, err
+	}
+	data, err := json.MarshalIndent(normalized, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append(data, '\n'), nil
+}
+
+// Unmarshal : Reads a workspace in its JSON form, as written by Marshal or returned by the service
+func Unmarshal(data []byte) (*assistantv1.Workspace, error) {
+	var raw map[string]json.RawMessage
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, err
+	}
+	var workspace *assistantv1.Workspace
+	if err := core.UnmarshalModel(raw, "", &workspace, assistantv1.UnmarshalWorkspace); err != nil {
+		return nil, err
+	}
+	return workspace, nil
+}
+
+// Normalize : Returns a copy of the workspace without its volatile fields (timestamps and status), with its
+// intents, examples, entities, values, synonyms, counterexamples and dialog nodes sorted. Empty lists are dropped,
+// so that a workspace exported with and without some sections compares the same.
+func Normalize(workspace *assistantv1.Workspace) (*assistantv1.Workspace, error) {
+	copied, err := clone(workspace)
+	if err != nil {
+		return nil, err
+	}
+	copied.Created, copied.Updated, copied.Status = nil, nil, nil
+
+	for i := range copied.Intents {
+		intent := &copied.Intents[i]
+		intent.Created, intent.Updated = nil, nil
+		for j := range intent.Examples {
+			intent.Examples[j].Created, intent.Examples[j].Updated = nil, nil
+		}
+		sort.Slice(intent.Examples, func(a, b int) bool {
+			return core.StringNilMapper(intent.Examples[a].Text) < core.StringNilMapper(intent.Examples[b].Text)
+		})
+		if len(intent.Examples) == 0 {
+			intent.Examples = nil
+		}
+	}
+	sort.Slice(copied.Intents, func(a, b int) bool {
+		return core.StringNilMapper(copied.Intents[a].Intent) < core.StringNilMapper(copied.Intents[b].Intent)
+	})
+
+	for i := range copied.Entities {
+		entity := &copied.Entities[i]
+		entity.Created, entity.Updated = nil, nil
+		for j := range entity.Values {
+			value := &entity.Values[j]
+			value.Created, value.Updated = nil, nil
+			sort.Strings(value.Synonyms)
+			if len(value.Synonyms) == 0 {
+				value.Synonyms = nil
+			}
+			if len(value.Patterns) == 0 {
+				value.Patterns = nil
+			}
+		}
+		sort.Slice(entity.Values, func(a, b int) bool {
+			return core.StringNilMapper(entity.Values[a].Value) < core.StringNilMapper(entity.Values[b].Value)
+		})
+		if len(entity.Values) == 0 {
+			entity.Values = nil
+		}
+	}
+	sort.Slice(copied.Entities, func(a, b int) bool {
+		return core.StringNilMapper(copied.Entities[a].Entity) < core.StringNilMapper(copied.Entities[b].Entity)
+	})
+
+	for i := range copied.Counterexamples {
+		copied.Counterexamples[i].Created, copied.Counterexamples[i].Updated = nil, nil
+	}
+	sort.Slice(copied.Counterexamples, func(a, b int) bool {
+		return core.StringNilMapper(copied.Counterexamples[a].Text) < core.StringNilMapper(copied.Counterexamples[b].Text)
+	})
+
+	for i := range copied.DialogNodes {
+		copied.DialogNodes[i].Created, copied.DialogNodes[i].Updated = nil, nil
+	}
+	sort.Slice(copied.DialogNodes, func(a, b int) bool {
+		return core.StringNilMapper(copied.DialogNodes[a].DialogNode) < core.StringNilMapper(copied.DialogNodes[b].DialogNode)
+	})
+
+	if len(copied.Intents) == 0 {
+		copied.Intents = nil
+	}
+	if len(copied.Entities) == 0 {
+		copied.Entities = nil
+	}
+	if len(copied.Counterexamples) == 0 {
+		copied.Counterexamples = nil
+	}
+	if len(copied.DialogNodes) == 0 {
+		copied.DialogNodes = nil
+	}
+	if len(copied.Webhooks) == 0 {
+		copied.Webhooks = nil
+	}
+	return copied, nil
+}
+
+// clone : Returns a deep copy of the workspace, made through its JSON form
+func clone(workspace *assistantv1.Workspace) (*assistantv1.Workspace, error) {
+	if workspace == nil {
+		return &assistantv1.Workspace{}, nil
+	}
+	data, err := json.Marshal(workspace)
+	if err != nil {
+		return nil, err
+	}
+	return Unmarshal(data)
+}
@@ -0,0 +1,256 @@
+/**
+ * (C) Copyright IBM Corp. 2021.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package workspace_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+
+	"github.com/IBM/go-sdk-core/v5/core"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/watson-developer-cloud/go-sdk/v2/assistantv1"
+	"github.com/watson-developer-cloud/go-sdk/v2/assistantv1/workspace"
+)
+
+const current = `{
+	"name": "Bank",
+	"language": "en",
+	"workspace_id": "ws1",
+	"learning_opt_out": false,
+	"status": "Available",
+	"created": "2021-01-01T00:00:00.000Z",
+	"updated": "2021-02-01T00:00:00.000Z",
+	"intents": [
+		{"intent": "goodbye", "examples": [{"text": "bye", "created": "2021-01-01T00:00:00.000Z"}]},
+		{"intent": "balance", "description": "Balance", "examples": [{"text": "my balance"}, {"text": "how much money"}]}
+	],
+	"entities": [
+		{"entity": "account", "values": [
+			{"value": "savings", "type": "synonyms", "synonyms": ["saving", "deposit"]},
+			{"value": "checking", "type": "synonyms", "synonyms": ["current"]}
+		]}
+	],
+	"counterexamples": [{"text": "weather"}],
+	"dialog_nodes": [
+		{"dialog_node": "welcome", "conditions": "welcome", "output": {"generic": [{"response_type": "text", "values": [{"text": "Hi"}]}]}},
+		{"dialog_node": "balance", "conditions": "#balance", "previous_sibling": "welcome"},
+		{"dialog_node": "balance_reply", "parent": "balance", "output": {"generic": [{"response_type": "text", "values": [{"text": "$5"}]}]}},
+		{"dialog_node": "old", "previous_sibling": "balance"},
+		{"dialog_node": "old_child", "parent": "old"}
+	]
+}`
+
+const desired = `{
+	"name": "Bank",
+	"description": "Banking assistant",
+	"language": "en",
+	"workspace_id": "ws1",
+	"learning_opt_out": false,
+	"intents": [
+		{"intent": "balance", "description": "Balance", "examples": [{"text": "how much money"}, {"text": "balance please"}]},
+		{"intent": "transfer", "examples": [{"text": "send money"}]}
+	],
+	"entities": [
+		{"entity": "account", "values": [
+			{"value": "checking", "type": "synonyms", "synonyms": ["current"]},
+			{"value": "savings", "type": "synonyms", "synonyms": ["saving", "rainy day"]}
+		]}
+	],
+	"counterexamples": [{"text": "weather"}, {"text": "sports"}],
+	"dialog_nodes": [
+		{"dialog_node": "welcome", "conditions": "welcome", "output": {"generic": [{"response_type": "text", "values": [{"text": "Hi"}]}]}},
+		{"dialog_node": "balance", "conditions": "#balance", "previous_sibling": "welcome"},
+		{"dialog_node": "balance_reply", "parent": "balance", "output": {"generic": [{"response_type": "text", "values": [{"text": "$10"}]}]}},
+		{"dialog_node": "transfer", "conditions": "#transfer", "previous_sibling": "balance"},
+		{"dialog_node": "transfer_reply", "parent": "transfer"}
+	]
+}`
+
+func mustUnmarshal(data string) *assistantv1.Workspace {
+	result, err := workspace.Unmarshal([]byte(data))
+	Expect(err).To(BeNil())
+	return result
+}
+
+var _ = Describe(`Marshal`, func() {
+	It(`Write workspaces deterministically`, func() {
+		data, err := workspace.Marshal(mustUnmarshal(current))
+		Expect(err).To(BeNil())
+		text := string(data)
+		Expect(text).ToNot(ContainSubstring("created"))
+		Expect(text).ToNot(ContainSubstring("updated"))
+		Expect(text).ToNot(ContainSubstring("Available"))
+		Expect(strings.Index(text, `"balance"`)).To(BeNumerically("<", strings.Index(text, `"goodbye"`)))
+		Expect(strings.Index(text, `"deposit"`)).To(BeNumerically("<", strings.Index(text, `"saving"`)))
+		Expect(strings.Index(text, `"dialog_node": "balance"`)).To(BeNumerically("<", strings.Index(text, `"dialog_node": "welcome"`)))
+		Expect(text).To(HaveSuffix("}\n"))
+
+		again, err := workspace.Marshal(mustUnmarshal(text))
+		Expect(err).To(BeNil())
+		Expect(string(again)).To(Equal(text))
+	})
+})
+
+var _ = Describe(`Compute`, func() {
+	It(`Find the changes between two workspaces`, func() {
+		diff, err := workspace.Compute(mustUnmarshal(current), mustUnmarshal(desired))
+		Expect(err).To(BeNil())
+		Expect(diff.IsEmpty()).To(BeFalse())
+		Expect(diff.String()).To(Equal(`~ description
+~ intent #balance
+  + example "balance please"
+  - example "my balance"
+- intent #goodbye
++ intent #transfer
+~ entity @account
+  ~ value "savings"
+    - synonym "deposit"
+    + synonym "rainy day"
++ counterexample "sports"
+~ dialog node balance_reply [output]
+- dialog node old
+- dialog node old_child
++ dialog node transfer
++ dialog node transfer_reply
+`))
+	})
+
+	It(`Find no changes between a workspace and its serialized form`, func() {
+		data, err := workspace.Marshal(mustUnmarshal(current))
+		Expect(err).To(BeNil())
+		diff, err := workspace.Compute(mustUnmarshal(current), mustUnmarshal(string(data)))
+		Expect(err).To(BeNil())
+		Expect(diff.IsEmpty()).To(BeTrue())
+		Expect(diff.String()).To(Equal(""))
+	})
+})
+
+// recorder : A stand-in for the service that records the requests it gets, and answers GET with its workspace
+type recorder struct {
+	mutex     sync.Mutex
+	workspace string
+	requests  []string
+	bodies    []map[string]interface{}
+}
+
+func (r *recorder) ServeHTTP(w http.ResponseWriter, request *http.Request) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	line := request.Method + " " + strings.TrimPrefix(request.URL.Path, "/v1/workspaces/ws1")
+	if request.URL.Query().Get("append") != "" {
+		line += "?append=" + request.URL.Query().Get("append")
+	}
+	r.requests = append(r.requests, line)
+	body := map[string]interface{}{}
+	data, _ := ioutil.ReadAll(request.Body)
+	_ = json.Unmarshal(data, &body)
+	r.bodies = append(r.bodies, body)
+
+	w.Header().Set("Content-Type", "application/json")
+	switch request.Method {
+	case "GET":
+		w.WriteHeader(200)
+		fmt.Fprint(w, r.workspace)
+	case "DELETE":
+		w.WriteHeader(200)
+	case "POST":
+		w.WriteHeader(200)
+		fmt.Fprint(w, `{}`)
+	}
+}
+
+var _ = Describe(`Apply`, func() {
+	var standIn *recorder
+	var testServer *httptest.Server
+	var assistantService *assistantv1.AssistantV1
+
+	BeforeEach(func() {
+		standIn = &recorder{}
+		testServer = httptest.NewServer(standIn)
+		var err error
+		assistantService, err = assistantv1.NewAssistantV1(&assistantv1.AssistantV1Options{
+			URL:           testServer.URL,
+			Version:       core.StringPtr("2021-06-14"),
+			Authenticator: &core.NoAuthAuthenticator{},
+		})
+		Expect(err).To(BeNil())
+	})
+	AfterEach(func() {
+		testServer.Close()
+	})
+
+	It(`Apply a diff with the fine-grained methods`, func() {
+		diff, err := workspace.Compute(mustUnmarshal(current), mustUnmarshal(desired))
+		Expect(err).To(BeNil())
+		Expect(workspace.Apply(context.Background(), assistantService, "ws1", diff, nil)).To(Succeed())
+		Expect(standIn.requests).To(Equal([]string{
+			"POST ?append=false",
+			"POST /counterexamples",
+			"DELETE /entities/account/values/savings/synonyms/deposit",
+			"POST /entities/account/values/savings/synonyms",
+			"POST /intents/balance/examples",
+			"DELETE /intents/balance/examples/my balance",
+			"DELETE /intents/goodbye",
+			"POST /intents",
+			"POST /dialog_nodes",
+			"POST /dialog_nodes",
+			"POST /dialog_nodes/balance_reply",
+			"DELETE /dialog_nodes/old",
+		}))
+		Expect(standIn.bodies[0]).To(Equal(map[string]interface{}{"description": "Banking assistant"}))
+		Expect(standIn.bodies[8]["dialog_node"]).To(Equal("transfer"))
+		Expect(standIn.bodies[9]["dialog_node"]).To(Equal("transfer_reply"))
+	})
+
+	It(`Apply a diff with a single update`, func() {
+		diff, err := workspace.Compute(mustUnmarshal(current), mustUnmarshal(desired))
+		Expect(err).To(BeNil())
+		Expect(workspace.Apply(context.Background(), assistantService, "ws1", diff, &workspace.ApplyOptions{SingleUpdate: true})).To(Succeed())
+		Expect(standIn.requests).To(Equal([]string{"POST ?append=false"}))
+		body := standIn.bodies[0]
+		Expect(body["description"]).To(Equal("Banking assistant"))
+		Expect(body["intents"]).To(HaveLen(2))
+		Expect(body["entities"]).To(HaveLen(1))
+		Expect(body["counterexamples"]).To(HaveLen(2))
+		Expect(body["dialog_nodes"]).To(HaveLen(5))
+	})
+
+	It(`Apply a diff that empties a section with a single update`, func() {
+		target := mustUnmarshal(current)
+		target.Counterexamples = nil
+		diff, err := workspace.Compute(mustUnmarshal(current), target)
+		Expect(err).To(BeNil())
+		Expect(workspace.Apply(context.Background(), assistantService, "ws1", diff, &workspace.ApplyOptions{SingleUpdate: true})).To(Succeed())
+		Expect(standIn.requests).To(Equal([]string{"DELETE /counterexamples/weather"}))
+	})
+
+	It(`Refuse changes that the API cannot make`, func() {
+		from := mustUnmarshal(current)
+		from.Metadata = map[string]interface{}{"owner": "bank"}
+		diff, err := workspace.Compute(from, mustUnmarshal(current))
+		Expect(err).To(BeNil())
+		Expect(workspace.Apply(context.Background(), assistantService, "ws1", diff, nil)).ToNot(Succeed())
+		Expect(standIn.requests).To(BeEmpty())
+	})
+})
@@ -0,0 +1,83 @@
+/**
+ * (C) Copyright IBM Corp. 2021.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package polling waits for server-side work with an exponential backoff.
+package polling
+
+import (
+	"context"
+	"time"
+)
+
+// Backoff : How often to poll for the status of server-side work
+type Backoff struct {
+	// The wait before the second poll. Defaults to one second.
+	InitialInterval time.Duration
+
+	// The longest wait between two polls. Defaults to 30 seconds.
+	MaxInterval time.Duration
+
+	// The factor by which the wait grows after each poll. Defaults to 2.
+	Multiplier float64
+}
+
+// withDefaults : Returns a copy of the backoff with unset fields filled in
+func (backoff *Backoff) withDefaults() Backoff {
+	resolved := Backoff{}
+	if backoff != nil {
+		resolved = *backoff
+	}
+	if resolved.InitialInterval <= 0 {
+		resolved.InitialInterval = time.Second
+	}
+	if resolved.MaxInterval <= 0 {
+		resolved.MaxInterval = 30 * time.Second
+	}
+	if resolved.MaxInterval < resolved.InitialInterval {
+		resolved.MaxInterval = resolved.InitialInterval
+	}
+	if resolved.Multiplier < 1 {
+		resolved.Multiplier = 2
+	}
+	return resolved
+}
+
+// Poll : Calls check until it reports done, waiting between calls according to the backoff, which may be nil for the
+// defaults. A check that fails because ctx ended is reported as ctx.Err().
+func Poll(ctx context.Context, backoff *Backoff, check func() (done bool, err error)) error {
+	resolved := backoff.withDefaults()
+	interval := resolved.InitialInterval
+	for {
+		done, err := check()
+		if err != nil && ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if err != nil || done {
+			return err
+		}
+		timer := time.NewTimer(interval)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+		}
+		interval = time.Duration(float64(interval) * resolved.Multiplier)
+		if interval > resolved.MaxInterval {
+			interval = resolved.MaxInterval
+		}
+	}
+}
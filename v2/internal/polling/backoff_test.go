@@ -0,0 +1,61 @@
+/**
+ * (C) Copyright IBM Corp. 2021.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package polling_test
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/watson-developer-cloud/go-sdk/v2/internal/polling"
+)
+
+var _ = Describe(`Poll`, func() {
+	backoff := &polling.Backoff{InitialInterval: time.Millisecond, MaxInterval: 2 * time.Millisecond}
+
+	It(`Poll until done or failed`, func() {
+		checks := 0
+		err := polling.Poll(context.Background(), backoff, func() (bool, error) {
+			checks++
+			return checks == 3, nil
+		})
+		Expect(err).To(BeNil())
+		Expect(checks).To(Equal(3))
+
+		failure := errors.New("failed")
+		err = polling.Poll(context.Background(), nil, func() (bool, error) {
+			return false, failure
+		})
+		Expect(err).To(Equal(failure))
+	})
+
+	It(`Stop when the context ends`, func() {
+		ctx, cancel := context.WithCancel(context.Background())
+		err := polling.Poll(ctx, backoff, func() (bool, error) {
+			cancel()
+			return false, nil
+		})
+		Expect(err).To(Equal(context.Canceled))
+
+		err = polling.Poll(ctx, backoff, func() (bool, error) {
+			return false, errors.New("request canceled")
+		})
+		Expect(err).To(Equal(context.Canceled))
+	})
+})
@@ -0,0 +1,28 @@
+/**
+ * (C) Copyright IBM Corp. 2021.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package websocketurl derives the websocket endpoints of a service from its URL.
+package websocketurl
+
+import "strings"
+
+// FromServiceURL : Converts the service URL to the equivalent websocket scheme
+func FromServiceURL(serviceURL string) string {
+	if strings.HasPrefix(serviceURL, "http://") {
+		return "ws://" + strings.TrimPrefix(serviceURL, "http://")
+	}
+	return strings.Replace(serviceURL, "https", "wss", 1)
+}
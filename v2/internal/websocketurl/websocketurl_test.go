@@ -0,0 +1,30 @@
+/**
+ * (C) Copyright IBM Corp. 2021.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package websocketurl_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/watson-developer-cloud/go-sdk/v2/internal/websocketurl"
+)
+
+var _ = Describe(`FromServiceURL`, func() {
+	It(`Convert service URLs to websocket URLs`, func() {
+		Expect(websocketurl.FromServiceURL("https://api.us-south.speech-to-text.watson.cloud.ibm.com")).To(Equal("wss://api.us-south.speech-to-text.watson.cloud.ibm.com"))
+		Expect(websocketurl.FromServiceURL("http://127.0.0.1:8080")).To(Equal("ws://127.0.0.1:8080"))
+	})
+})
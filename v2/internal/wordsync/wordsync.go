@@ -0,0 +1,114 @@
+/**
+ * (C) Copyright IBM Corp. 2021.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package wordsync plans and applies the changes that make the custom words of a speech model match a desired set.
+// It works on word names and indexes so that each service keeps its own word types.
+package wordsync
+
+import (
+	"fmt"
+	"sort"
+)
+
+// DefaultBatchSize is the number of words sent in one request when the options of a sync do not say
+const DefaultBatchSize = 1000
+
+// Plan : The changes that make the words of a model match the desired words
+type Plan struct {
+	// The indexes of the desired words that the model does not have, sorted by word.
+	Add []int
+
+	// The indexes of the desired words that differ in the model, sorted by word.
+	Update []int
+
+	// The words of the model that are not desired, sorted. Filled in only when extra words are deleted.
+	Delete []string
+
+	// The number of desired words that the model already has as desired.
+	Unchanged int
+}
+
+// NewPlan : Compares the desired words with the existing words of a model. matches reports whether the existing word
+// at an index already has the content of the desired word at an index; existing words without a name are ignored.
+// Desired words must have distinct, non-empty names.
+func NewPlan(desired []string, existing []string, matches func(desiredIndex int, existingIndex int) bool, deleteExtra bool) (*Plan, error) {
+	desiredByWord := make(map[string]int, len(desired))
+	for i, word := range desired {
+		if word == "" {
+			return nil, fmt.Errorf("desired words must have a word")
+		}
+		if _, ok := desiredByWord[word]; ok {
+			return nil, fmt.Errorf("word %q is desired more than once", word)
+		}
+		desiredByWord[word] = i
+	}
+
+	plan := &Plan{}
+	existingByWord := make(map[string]int, len(existing))
+	for i, word := range existing {
+		if word == "" {
+			continue
+		}
+		existingByWord[word] = i
+		if _, ok := desiredByWord[word]; !ok && deleteExtra {
+			plan.Delete = append(plan.Delete, word)
+		}
+	}
+	for i, word := range desired {
+		current, ok := existingByWord[word]
+		switch {
+		case !ok:
+			plan.Add = append(plan.Add, i)
+		case !matches(i, current):
+			plan.Update = append(plan.Update, i)
+		default:
+			plan.Unchanged++
+		}
+	}
+	byWord := func(indexes []int) func(i, j int) bool {
+		return func(i, j int) bool {
+			return desired[indexes[i]] < desired[indexes[j]]
+		}
+	}
+	sort.Slice(plan.Add, byWord(plan.Add))
+	sort.Slice(plan.Update, byWord(plan.Update))
+	sort.Strings(plan.Delete)
+	return plan, nil
+}
+
+// Apply : Calls add with the indexes of the words to add and update, in batches of batchSize or DefaultBatchSize
+// when batchSize is not positive, then remove for each word to delete. Stops at the first error.
+func (plan *Plan) Apply(batchSize int, add func(batch []int) error, remove func(word string) error) error {
+	if batchSize <= 0 {
+		batchSize = DefaultBatchSize
+	}
+	changed := append(append([]int{}, plan.Add...), plan.Update...)
+	for start := 0; start < len(changed); start += batchSize {
+		end := start + batchSize
+		if end > len(changed) {
+			end = len(changed)
+		}
+		if err := add(changed[start:end]); err != nil {
+			return err
+		}
+	}
+	for _, word := range plan.Delete {
+		if err := remove(word); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,78 @@
+/**
+ * (C) Copyright IBM Corp. 2021.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package wordsync_test
+
+import (
+	"errors"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/watson-developer-cloud/go-sdk/v2/internal/wordsync"
+)
+
+var _ = Describe(`Plan`, func() {
+	desired := []string{"zebra", "apple", "mango", "kiwi"}
+	existing := []string{"mango", "", "grape", "kiwi"}
+	matches := func(desiredIndex int, existingIndex int) bool {
+		return desired[desiredIndex] == "kiwi"
+	}
+
+	It(`Compare desired and existing words`, func() {
+		plan, err := wordsync.NewPlan(desired, existing, matches, true)
+		Expect(err).To(BeNil())
+		Expect(plan.Add).To(Equal([]int{1, 0}))
+		Expect(plan.Update).To(Equal([]int{2}))
+		Expect(plan.Delete).To(Equal([]string{"grape"}))
+		Expect(plan.Unchanged).To(Equal(1))
+
+		plan, err = wordsync.NewPlan(desired, existing, matches, false)
+		Expect(err).To(BeNil())
+		Expect(plan.Delete).To(BeEmpty())
+	})
+
+	It(`Reject empty and repeated desired words`, func() {
+		_, err := wordsync.NewPlan([]string{"a", ""}, nil, matches, false)
+		Expect(err).ToNot(BeNil())
+		_, err = wordsync.NewPlan([]string{"a", "a"}, nil, matches, false)
+		Expect(err).ToNot(BeNil())
+	})
+
+	It(`Apply a plan in batches`, func() {
+		plan, err := wordsync.NewPlan(desired, existing, matches, true)
+		Expect(err).To(BeNil())
+		var batches [][]int
+		var removed []string
+		add := func(batch []int) error {
+			batches = append(batches, append([]int{}, batch...))
+			return nil
+		}
+		remove := func(word string) error {
+			removed = append(removed, word)
+			return nil
+		}
+		Expect(plan.Apply(2, add, remove)).To(Succeed())
+		Expect(batches).To(Equal([][]int{{1, 0}, {2}}))
+		Expect(removed).To(Equal([]string{"grape"}))
+
+		failure := errors.New("failed")
+		err = plan.Apply(0, func(batch []int) error {
+			return failure
+		}, remove)
+		Expect(err).To(Equal(failure))
+		Expect(removed).To(HaveLen(1))
+	})
+})
@@ -0,0 +1,256 @@
+package speechtotextv1
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// sniffLength is the number of bytes read from the start of the audio to detect its format
+const sniffLength = 4096
+
+// ErrUnknownAudioFormat is returned when the format of audio cannot be detected and no hint is given
+var ErrUnknownAudioFormat = errors.New("unable to detect the audio format")
+
+// Encodings of headerless audio described by an AudioFormatHint
+const (
+	AudioFormatHintEncodingAlawConst  = "alaw"
+	AudioFormatHintEncodingL16Const   = "l16"
+	AudioFormatHintEncodingMulawConst = "mulaw"
+)
+
+// AudioFormatHint : Describes headerless audio, which cannot be recognized from its content
+type AudioFormatHint struct {
+	// The encoding of the samples. Allowable values: alaw, l16, mulaw.
+	Encoding string
+
+	// The sampling rate in Hz.
+	Rate int
+
+	// The number of channels. Defaults to 1.
+	Channels int
+
+	// The byte order of `l16` samples: `big-endian` or `little-endian`. Omitted from the content type if empty.
+	Endianness string
+}
+
+// ContentType : Returns the content type the service expects for audio described by the hint
+func (hint *AudioFormatHint) ContentType() (string, error) {
+	switch hint.Encoding {
+	case AudioFormatHintEncodingL16Const, AudioFormatHintEncodingMulawConst, AudioFormatHintEncodingAlawConst:
+	default:
+		return "", fmt.Errorf("unsupported audio encoding %q", hint.Encoding)
+	}
+	if hint.Rate <= 0 {
+		return "", fmt.Errorf("the %s encoding requires a rate", hint.Encoding)
+	}
+	channels := hint.Channels
+	if channels <= 0 {
+		channels = 1
+	}
+	contentType := fmt.Sprintf("audio/%s;rate=%d;channels=%d", hint.Encoding, hint.Rate, channels)
+	if hint.Encoding == AudioFormatHintEncodingL16Const && hint.Endianness != "" {
+		contentType += ";endianness=" + hint.Endianness
+	}
+	return contentType, nil
+}
+
+// DetectAudioContentType : Detects the content type of audio from its first bytes. WAV, FLAC, Ogg, WebM, MP3 and
+// Sun/NeXT (.au) audio, and ZIP and gzip archives of audio files, are recognized. Headerless audio is described by
+// hint, which takes precedence over the content when given, since headerless samples can look like a header; it may
+// be nil. The returned reader yields the whole audio, including the bytes read for detection.
+func DetectAudioContentType(audio io.Reader, hint *AudioFormatHint) (contentType string, wrapped io.Reader, err error) {
+	if hint != nil {
+		contentType, err = hint.ContentType()
+		return contentType, audio, err
+	}
+
+	head := make([]byte, sniffLength)
+	n, err := io.ReadFull(audio, head)
+	if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+		return "", nil, err
+	}
+	head = head[:n]
+	wrapped = io.MultiReader(bytes.NewReader(head), audio)
+
+	contentType = sniffAudioContentType(head)
+	if contentType != "" {
+		return contentType, wrapped, nil
+	}
+	return "", wrapped, ErrUnknownAudioFormat
+}
+
+// DetectAudioContentTypeReadCloser : DetectAudioContentType for an io.ReadCloser. Closing the returned reader
+// closes audio.
+func DetectAudioContentTypeReadCloser(audio io.ReadCloser, hint *AudioFormatHint) (string, io.ReadCloser, error) {
+	contentType, wrapped, err := DetectAudioContentType(audio, hint)
+	if wrapped == nil {
+		return contentType, audio, err
+	}
+	return contentType, &sniffedReadCloser{Reader: wrapped, closer: audio}, err
+}
+
+type sniffedReadCloser struct {
+	io.Reader
+	closer io.Closer
+}
+
+func (reader *sniffedReadCloser) Close() error {
+	return reader.closer.Close()
+}
+
+// DetectContentType : Sets ContentType from the content of Audio, replacing Audio with a reader that still yields
+// all of it. The hint may be nil.
+func (_options *RecognizeOptions) DetectContentType(hint *AudioFormatHint) error {
+	contentType, audio, err := DetectAudioContentTypeReadCloser(_options.Audio, hint)
+	_options.Audio = audio
+	if err != nil {
+		return err
+	}
+	_options.ContentType = &contentType
+	return nil
+}
+
+// DetectContentType : Sets ContentType from the content of Audio, replacing Audio with a reader that still yields
+// all of it. The hint may be nil.
+func (_options *CreateJobOptions) DetectContentType(hint *AudioFormatHint) error {
+	contentType, audio, err := DetectAudioContentTypeReadCloser(_options.Audio, hint)
+	_options.Audio = audio
+	if err != nil {
+		return err
+	}
+	_options.ContentType = &contentType
+	return nil
+}
+
+// DetectContentType : Sets ContentType from the content of AudioResource, replacing AudioResource with a reader that
+// still yields all of it. For an archive, ContainedContentType is left to the caller. The hint may be nil.
+func (_options *AddAudioOptions) DetectContentType(hint *AudioFormatHint) error {
+	contentType, audio, err := DetectAudioContentTypeReadCloser(_options.AudioResource, hint)
+	_options.AudioResource = audio
+	if err != nil {
+		return err
+	}
+	_options.ContentType = &contentType
+	return nil
+}
+
+// sniffAudioContentType : Returns the content type for the header in head, or an empty string
+func sniffAudioContentType(head []byte) string {
+	switch {
+	case len(head) >= 12 && bytes.Equal(head[0:4], []byte("RIFF")) && bytes.Equal(head[8:12], []byte("WAVE")):
+		return "audio/wav"
+	case bytes.HasPrefix(head, []byte("fLaC")):
+		return "audio/flac"
+	case bytes.HasPrefix(head, []byte("OggS")):
+		switch {
+		case bytes.Contains(head, []byte("OpusHead")):
+			return "audio/ogg;codecs=opus"
+		case bytes.Contains(head, []byte("\x01vorbis")):
+			return "audio/ogg;codecs=vorbis"
+		}
+		return "audio/ogg"
+	case bytes.HasPrefix(head, []byte{0x1A, 0x45, 0xDF, 0xA3}):
+		switch {
+		case bytes.Contains(head, []byte("A_OPUS")):
+			return "audio/webm;codecs=opus"
+		case bytes.Contains(head, []byte("A_VORBIS")):
+			return "audio/webm;codecs=vorbis"
+		}
+		return "audio/webm"
+	case bytes.HasPrefix(head, []byte("ID3")):
+		return "audio/mp3"
+	case isMPEGAudio(head):
+		return "audio/mp3"
+	case bytes.HasPrefix(head, []byte(".snd")):
+		return sniffSunAudio(head)
+	case bytes.HasPrefix(head, []byte("PK\x03\x04")):
+		return "application/zip"
+	case bytes.HasPrefix(head, []byte{0x1F, 0x8B}):
+		return "application/gzip"
+	}
+	return ""
+}
+
+// mpegBitrates are the bitrates in kbit/s by bitrate index, for MPEG-1 layers I, II and III and for MPEG-2 and 2.5
+// layers I, and II and III
+var mpegBitrates = [5][15]int{
+	{0, 32, 64, 96, 128, 160, 192, 224, 256, 288, 320, 352, 384, 416, 448},
+	{0, 32, 48, 56, 64, 80, 96, 112, 128, 160, 192, 224, 256, 320, 384},
+	{0, 32, 40, 48, 56, 64, 80, 96, 112, 128, 160, 192, 224, 256, 320},
+	{0, 32, 48, 56, 64, 80, 96, 112, 128, 144, 160, 176, 192, 224, 256},
+	{0, 8, 16, 24, 32, 40, 48, 56, 64, 80, 96, 112, 128, 144, 160},
+}
+
+// mpegSampleRates are the sampling rates in Hz by version bits (MPEG-2.5, reserved, MPEG-2, MPEG-1) and rate index
+var mpegSampleRates = [4][3]int{{11025, 12000, 8000}, {}, {22050, 24000, 16000}, {44100, 48000, 32000}}
+
+// mpegFrameLength : Returns the length in bytes of the MPEG audio frame whose header starts header, or 0 if header
+// does not start with a valid frame header. Free-format frames (bitrate index 0) are not recognized.
+func mpegFrameLength(header []byte) int {
+	if len(header) < 4 || header[0] != 0xFF || header[1]&0xE0 != 0xE0 {
+		return 0
+	}
+	version := (header[1] >> 3) & 0x03
+	layer := (header[1] >> 1) & 0x03
+	bitrateIndex := header[2] >> 4
+	rateIndex := (header[2] >> 2) & 0x03
+	padding := int((header[2] >> 1) & 0x01)
+	if version == 1 || layer == 0 || bitrateIndex == 0 || bitrateIndex == 0x0F || rateIndex == 3 {
+		return 0
+	}
+
+	table := 3 - int(layer) // layer bits 3, 2, 1 are layers I, II, III
+	if version != 3 {
+		table = 4
+		if layer == 3 {
+			table = 3
+		}
+	}
+	bitrate := mpegBitrates[table][bitrateIndex] * 1000
+	rate := mpegSampleRates[version][rateIndex]
+	switch {
+	case layer == 3:
+		return (12*bitrate/rate + padding) * 4
+	case layer == 1 && version != 3:
+		return 72*bitrate/rate + padding
+	}
+	return 144*bitrate/rate + padding
+}
+
+// isMPEGAudio : Tells whether head starts with an MPEG audio frame header that is followed by another frame header,
+// unless the audio ends before it
+func isMPEGAudio(head []byte) bool {
+	length := mpegFrameLength(head)
+	if length == 0 {
+		return false
+	}
+	if len(head) < length+4 {
+		return len(head) < sniffLength
+	}
+	return mpegFrameLength(head[length:]) != 0
+}
+
+// sniffSunAudio : Derives the content type of Sun/NeXT audio from its header
+func sniffSunAudio(head []byte) string {
+	if len(head) < 24 {
+		return ""
+	}
+	encoding := binary.BigEndian.Uint32(head[12:16])
+	rate := binary.BigEndian.Uint32(head[16:20])
+	channels := binary.BigEndian.Uint32(head[20:24])
+	switch encoding {
+	case 1:
+		if rate == 8000 && channels == 1 {
+			return "audio/basic"
+		}
+		return fmt.Sprintf("audio/mulaw;rate=%d;channels=%d", rate, channels)
+	case 3:
+		return fmt.Sprintf("audio/l16;rate=%d;channels=%d;endianness=big-endian", rate, channels)
+	case 27:
+		return fmt.Sprintf("audio/alaw;rate=%d;channels=%d", rate, channels)
+	}
+	return ""
+}
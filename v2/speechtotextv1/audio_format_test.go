@@ -0,0 +1,153 @@
+/**
+ * (C) Copyright IBM Corp. 2021.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package speechtotextv1_test
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io/ioutil"
+	"os"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/watson-developer-cloud/go-sdk/v2/speechtotextv1"
+)
+
+func sunAudioHeader(encoding, rate, channels uint32) []byte {
+	var header bytes.Buffer
+	header.WriteString(".snd")
+	_ = binary.Write(&header, binary.BigEndian, []uint32{24, 0xFFFFFFFF, encoding, rate, channels})
+	return header.Bytes()
+}
+
+var _ = Describe(`Audio format detection`, func() {
+	It(`Invoke DetectAudioContentType for each supported format`, func() {
+		formats := []struct {
+			head     []byte
+			expected string
+		}{
+			{[]byte("RIFF\x24\x00\x00\x00WAVEfmt "), "audio/wav"},
+			{[]byte("fLaC\x00\x00\x00\x22"), "audio/flac"},
+			{[]byte("OggS\x00\x02\x00\x00\x00\x00\x00\x00\x00\x00\x01\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x01\x13OpusHead"), "audio/ogg;codecs=opus"},
+			{[]byte("OggS\x00\x02\x00\x00\x00\x00\x00\x00\x00\x00\x01\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x01\x1e\x01vorbis"), "audio/ogg;codecs=vorbis"},
+			{[]byte("\x1a\x45\xdf\xa3\x9f\x42\x86\x81\x01webm\x86\x86A_OPUS"), "audio/webm;codecs=opus"},
+			{[]byte("\x1a\x45\xdf\xa3\x9f\x42\x86\x81\x01webm"), "audio/webm"},
+			{[]byte("ID3\x03\x00\x00\x00\x00\x00\x00"), "audio/mp3"},
+			{[]byte{0xFF, 0xFB, 0x90, 0x64}, "audio/mp3"},
+			{sunAudioHeader(1, 8000, 1), "audio/basic"},
+			{sunAudioHeader(1, 16000, 2), "audio/mulaw;rate=16000;channels=2"},
+			{sunAudioHeader(3, 16000, 1), "audio/l16;rate=16000;channels=1;endianness=big-endian"},
+			{[]byte("PK\x03\x04\x14\x00"), "application/zip"},
+			{[]byte{0x1F, 0x8B, 0x08, 0x00}, "application/gzip"},
+		}
+		for _, format := range formats {
+			audio := append(append([]byte{}, format.head...), bytes.Repeat([]byte{0}, 100)...)
+			contentType, wrapped, err := speechtotextv1.DetectAudioContentType(bytes.NewReader(audio), nil)
+			Expect(err).To(BeNil())
+			Expect(contentType).To(Equal(format.expected))
+			roundTrip, err := ioutil.ReadAll(wrapped)
+			Expect(err).To(BeNil())
+			Expect(roundTrip).To(Equal(audio))
+		}
+	})
+	It(`Detects the example audio files`, func() {
+		for file, expected := range map[string]string{
+			"../resources/audio_example.mp3": "audio/mp3",
+			"../resources/output.wav":        "audio/wav",
+		} {
+			audio, err := os.Open(file)
+			if os.IsNotExist(err) {
+				continue
+			}
+			Expect(err).To(BeNil())
+			contentType, _, err := speechtotextv1.DetectAudioContentTypeReadCloser(audio, nil)
+			Expect(err).To(BeNil())
+			Expect(contentType).To(Equal(expected), file)
+			audio.Close()
+		}
+	})
+	It(`Uses the hint for headerless audio`, func() {
+		raw := bytes.Repeat([]byte{0x7F, 0x00}, 10)
+		_, _, err := speechtotextv1.DetectAudioContentType(bytes.NewReader(raw), nil)
+		Expect(err).To(Equal(speechtotextv1.ErrUnknownAudioFormat))
+
+		contentType, wrapped, err := speechtotextv1.DetectAudioContentType(bytes.NewReader(raw), &speechtotextv1.AudioFormatHint{
+			Encoding:   speechtotextv1.AudioFormatHintEncodingL16Const,
+			Rate:       16000,
+			Endianness: "little-endian",
+		})
+		Expect(err).To(BeNil())
+		Expect(contentType).To(Equal("audio/l16;rate=16000;channels=1;endianness=little-endian"))
+		roundTrip, _ := ioutil.ReadAll(wrapped)
+		Expect(roundTrip).To(Equal(raw))
+
+		_, _, err = speechtotextv1.DetectAudioContentType(bytes.NewReader(raw), &speechtotextv1.AudioFormatHint{Encoding: "mulaw"})
+		Expect(err).ToNot(BeNil())
+	})
+	It(`Checks MPEG frame headers strictly`, func() {
+		// MPEG-1 layer III, 128 kbit/s, 44100 Hz: 417-byte frames
+		frame := append([]byte{0xFF, 0xFB, 0x90, 0x64}, make([]byte, 413)...)
+		audio := append(bytes.Repeat(frame, 12), make([]byte, 100)...)
+		contentType, _, err := speechtotextv1.DetectAudioContentType(bytes.NewReader(audio), nil)
+		Expect(err).To(BeNil())
+		Expect(contentType).To(Equal("audio/mp3"))
+
+		noSecondFrame := append(append([]byte{}, frame...), make([]byte, 5000)...)
+		_, _, err = speechtotextv1.DetectAudioContentType(bytes.NewReader(noSecondFrame), nil)
+		Expect(err).To(Equal(speechtotextv1.ErrUnknownAudioFormat))
+
+		for _, header := range [][]byte{{0xFF, 0xFB, 0x00, 0x64}, {0xFF, 0xFB, 0xF0, 0x64}, {0xFF, 0xFB, 0x9C, 0x64}, {0xFF, 0xEB, 0x90, 0x64}} {
+			_, _, err = speechtotextv1.DetectAudioContentType(bytes.NewReader(append(header, make([]byte, 100)...)), nil)
+			Expect(err).To(Equal(speechtotextv1.ErrUnknownAudioFormat), fmt.Sprintf("% x", header))
+		}
+	})
+	It(`Prefers the hint for headerless audio that looks like MP3`, func() {
+		silence := bytes.Repeat([]byte{0xFF}, 8000)
+		_, _, err := speechtotextv1.DetectAudioContentType(bytes.NewReader(silence), nil)
+		Expect(err).To(Equal(speechtotextv1.ErrUnknownAudioFormat))
+		contentType, wrapped, err := speechtotextv1.DetectAudioContentType(bytes.NewReader(silence), &speechtotextv1.AudioFormatHint{
+			Encoding: speechtotextv1.AudioFormatHintEncodingMulawConst,
+			Rate:     8000,
+		})
+		Expect(err).To(BeNil())
+		Expect(contentType).To(Equal("audio/mulaw;rate=8000;channels=1"))
+		roundTrip, _ := ioutil.ReadAll(wrapped)
+		Expect(roundTrip).To(Equal(silence))
+
+		// Little-endian l16 samples of -1 followed by MPEG-looking bytes
+		samples := append([]byte{0xFF, 0xFF, 0xFF, 0xFB, 0x90, 0x64}, make([]byte, 100)...)
+		contentType, _, err = speechtotextv1.DetectAudioContentType(bytes.NewReader(samples), &speechtotextv1.AudioFormatHint{
+			Encoding:   speechtotextv1.AudioFormatHintEncodingL16Const,
+			Rate:       16000,
+			Endianness: "little-endian",
+		})
+		Expect(err).To(BeNil())
+		Expect(contentType).To(Equal("audio/l16;rate=16000;channels=1;endianness=little-endian"))
+	})
+	It(`Sets the content type of options`, func() {
+		recognizeOptions := (&speechtotextv1.SpeechToTextV1{}).NewRecognizeOptions(ioutil.NopCloser(bytes.NewReader([]byte("fLaC\x00"))))
+		Expect(recognizeOptions.DetectContentType(nil)).To(Succeed())
+		Expect(*recognizeOptions.ContentType).To(Equal("audio/flac"))
+		audio, _ := ioutil.ReadAll(recognizeOptions.Audio)
+		Expect(audio).To(Equal([]byte("fLaC\x00")))
+
+		addAudioOptions := (&speechtotextv1.SpeechToTextV1{}).NewAddAudioOptions("id", "name", ioutil.NopCloser(bytes.NewReader([]byte("PK\x03\x04"))))
+		Expect(addAudioOptions.DetectContentType(nil)).To(Succeed())
+		Expect(*addAudioOptions.ContentType).To(Equal("application/zip"))
+	})
+})
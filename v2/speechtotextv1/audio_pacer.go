@@ -0,0 +1,122 @@
+package speechtotextv1
+
+import (
+	"fmt"
+	"mime"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// audioPacer : Decides the chunk size of a websocket audio upload and how long to wait between chunks
+type audioPacer struct {
+	chunkSize int
+
+	// interval is a fixed pause after every chunk, used when no rate is set
+	interval time.Duration
+
+	// bytesPerSecond is the target upload rate; zero disables rate pacing
+	bytesPerSecond int64
+
+	start time.Time
+	sent  int64
+}
+
+// newAudioPacer : Builds the pacer described by the Pacing, BytesPerSecond and ChunkSize options
+func newAudioPacer(recognizeWSOptions *RecognizeUsingWebsocketOptions) (*audioPacer, error) {
+	pacer := &audioPacer{chunkSize: ONE_KB * 2, interval: TEN_MILLISECONDS}
+
+	if recognizeWSOptions.ChunkSize != nil {
+		if *recognizeWSOptions.ChunkSize <= 0 {
+			return nil, fmt.Errorf("ChunkSize must be greater than zero")
+		}
+		pacer.chunkSize = int(*recognizeWSOptions.ChunkSize)
+	}
+
+	if recognizeWSOptions.Pacing == nil {
+		return pacer, nil
+	}
+	switch *recognizeWSOptions.Pacing {
+	case RecognizeUsingWebsocketOptionsPacingNoneConst:
+		pacer.interval = 0
+	case RecognizeUsingWebsocketOptionsPacingBytesPerSecondConst:
+		if recognizeWSOptions.BytesPerSecond == nil || *recognizeWSOptions.BytesPerSecond <= 0 {
+			return nil, fmt.Errorf("BytesPerSecond must be greater than zero when Pacing is %q", RecognizeUsingWebsocketOptionsPacingBytesPerSecondConst)
+		}
+		pacer.interval = 0
+		pacer.bytesPerSecond = *recognizeWSOptions.BytesPerSecond
+	case RecognizeUsingWebsocketOptionsPacingRealTimeConst:
+		if recognizeWSOptions.ContentType == nil {
+			return nil, fmt.Errorf("ContentType is required when Pacing is %q", RecognizeUsingWebsocketOptionsPacingRealTimeConst)
+		}
+		bytesPerSecond, err := contentTypeBytesPerSecond(*recognizeWSOptions.ContentType)
+		if err != nil {
+			return nil, err
+		}
+		pacer.interval = 0
+		pacer.bytesPerSecond = bytesPerSecond
+	default:
+		return nil, fmt.Errorf("unknown Pacing %q", *recognizeWSOptions.Pacing)
+	}
+	return pacer, nil
+}
+
+// wait : Returns how long to pause after a chunk of n bytes has been written. Rate pacing follows an absolute
+// schedule from the first chunk, so time spent blocked in a write counts toward the pause.
+func (pacer *audioPacer) wait(n int) time.Duration {
+	if pacer.bytesPerSecond == 0 {
+		return pacer.interval
+	}
+	if pacer.start.IsZero() {
+		pacer.start = time.Now()
+	}
+	pacer.sent += int64(n)
+	due := pacer.start.Add(time.Duration(pacer.sent * int64(time.Second) / pacer.bytesPerSecond))
+	return time.Until(due)
+}
+
+// contentTypeBytesPerSecond : Computes the byte rate of uncompressed audio from its declared content type
+func contentTypeBytesPerSecond(contentType string) (int64, error) {
+	mediaType, params, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return 0, err
+	}
+
+	var bytesPerSample int64
+	switch mediaType {
+	case "audio/l16":
+		bytesPerSample = 2
+	case "audio/mulaw", "audio/alaw":
+		bytesPerSample = 1
+	case "audio/basic":
+		// audio/basic is always 8 kHz single-channel mu-law
+		return 8000, nil
+	default:
+		return 0, fmt.Errorf("real-time pacing is not supported for content type %q", mediaType)
+	}
+
+	rate, err := contentTypeParameter(params, "rate", 0)
+	if err != nil {
+		return 0, err
+	}
+	if rate <= 0 {
+		return 0, fmt.Errorf("content type %q must declare a rate for real-time pacing", contentType)
+	}
+	channels, err := contentTypeParameter(params, "channels", 1)
+	if err != nil {
+		return 0, err
+	}
+	return rate * channels * bytesPerSample, nil
+}
+
+func contentTypeParameter(params map[string]string, name string, defaultValue int64) (int64, error) {
+	value, ok := params[name]
+	if !ok {
+		return defaultValue, nil
+	}
+	parsed, err := strconv.ParseInt(strings.TrimSpace(value), 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid %s %q in content type", name, value)
+	}
+	return parsed, nil
+}
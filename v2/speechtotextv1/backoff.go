@@ -0,0 +1,8 @@
+package speechtotextv1
+
+import "github.com/watson-developer-cloud/go-sdk/v2/internal/polling"
+
+// Backoff : How often to poll for the status of server-side work. InitialInterval, the wait before the second poll,
+// defaults to one second; MaxInterval, the longest wait between two polls, to 30 seconds; and Multiplier, the factor
+// by which the wait grows after each poll, to 2.
+type Backoff = polling.Backoff
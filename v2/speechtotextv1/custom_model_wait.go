@@ -0,0 +1,214 @@
+package speechtotextv1
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/IBM/go-sdk-core/v5/core"
+	"github.com/watson-developer-cloud/go-sdk/v2/internal/polling"
+)
+
+// ResourceFailedError is returned by the WaitFor methods when a custom model or one of its resources ends in a
+// state from which it cannot become usable
+type ResourceFailedError struct {
+	// The kind of resource, for example `language model` or `corpus`.
+	Resource string
+
+	// The customization ID of the model, or the name of the resource.
+	Name string
+
+	// The final status of the resource.
+	Status string
+
+	// The error message reported by the service, if any.
+	Message string
+}
+
+func (e *ResourceFailedError) Error() string {
+	if e.Message != "" {
+		return fmt.Sprintf("%s %s is %s: %s", e.Resource, e.Name, e.Status, e.Message)
+	}
+	return fmt.Sprintf("%s %s is %s", e.Resource, e.Name, e.Status)
+}
+
+// WaitOptions : Options for the WaitFor methods
+type WaitOptions struct {
+	// How often to poll for the status. Defaults to polling after one second, backing off to every 30 seconds.
+	Backoff *Backoff
+
+	// Called with every status observed while waiting.
+	OnStatus func(status string)
+}
+
+func (waitOptions *WaitOptions) backoff() *Backoff {
+	if waitOptions == nil {
+		return nil
+	}
+	return waitOptions.Backoff
+}
+
+func (waitOptions *WaitOptions) observe(status *string) {
+	if waitOptions != nil && waitOptions.OnStatus != nil && status != nil {
+		waitOptions.OnStatus(*status)
+	}
+}
+
+// WaitForLanguageModel : Polls GetLanguageModel until the custom language model is `ready` or `available`. A model
+// that has no valid training data stays `pending`, so ctx should carry a deadline. A model that ends up `failed`
+// is reported as a *ResourceFailedError.
+func (speechToText *SpeechToTextV1) WaitForLanguageModel(ctx context.Context, customizationID string, waitOptions *WaitOptions) (*LanguageModel, error) {
+	return speechToText.waitForLanguageModel(ctx, customizationID, waitOptions, LanguageModelStatusReadyConst, LanguageModelStatusAvailableConst)
+}
+
+func (speechToText *SpeechToTextV1) waitForLanguageModel(ctx context.Context, customizationID string, waitOptions *WaitOptions, doneStatuses ...string) (model *LanguageModel, err error) {
+	getLanguageModelOptions := speechToText.NewGetLanguageModelOptions(customizationID)
+	err = polling.Poll(ctx, waitOptions.backoff(), func() (bool, error) {
+		var getErr error
+		model, _, getErr = speechToText.GetLanguageModelWithContext(ctx, getLanguageModelOptions)
+		if getErr != nil {
+			return false, getErr
+		}
+		waitOptions.observe(model.Status)
+		if model.Status == nil {
+			return false, nil
+		}
+		if *model.Status == LanguageModelStatusFailedConst {
+			return false, &ResourceFailedError{Resource: "language model", Name: customizationID, Status: *model.Status, Message: core.StringNilMapper(model.Error)}
+		}
+		return hasStatus(*model.Status, doneStatuses), nil
+	})
+	return
+}
+
+// WaitForAcousticModel : Polls GetAcousticModel until the custom acoustic model is `ready` or `available`. A model
+// that ends up `failed` is reported as a *ResourceFailedError.
+func (speechToText *SpeechToTextV1) WaitForAcousticModel(ctx context.Context, customizationID string, waitOptions *WaitOptions) (*AcousticModel, error) {
+	return speechToText.waitForAcousticModel(ctx, customizationID, waitOptions, AcousticModelStatusReadyConst, AcousticModelStatusAvailableConst)
+}
+
+func (speechToText *SpeechToTextV1) waitForAcousticModel(ctx context.Context, customizationID string, waitOptions *WaitOptions, doneStatuses ...string) (model *AcousticModel, err error) {
+	getAcousticModelOptions := speechToText.NewGetAcousticModelOptions(customizationID)
+	err = polling.Poll(ctx, waitOptions.backoff(), func() (bool, error) {
+		var getErr error
+		model, _, getErr = speechToText.GetAcousticModelWithContext(ctx, getAcousticModelOptions)
+		if getErr != nil {
+			return false, getErr
+		}
+		waitOptions.observe(model.Status)
+		if model.Status == nil {
+			return false, nil
+		}
+		if *model.Status == AcousticModelStatusFailedConst {
+			return false, &ResourceFailedError{Resource: "acoustic model", Name: customizationID, Status: *model.Status}
+		}
+		return hasStatus(*model.Status, doneStatuses), nil
+	})
+	return
+}
+
+// WaitForCorpus : Polls GetCorpus until the corpus is `analyzed`. A corpus that ends up `undetermined` is reported
+// as a *ResourceFailedError.
+func (speechToText *SpeechToTextV1) WaitForCorpus(ctx context.Context, customizationID string, corpusName string, waitOptions *WaitOptions) (corpus *Corpus, err error) {
+	getCorpusOptions := speechToText.NewGetCorpusOptions(customizationID, corpusName)
+	err = polling.Poll(ctx, waitOptions.backoff(), func() (bool, error) {
+		var getErr error
+		corpus, _, getErr = speechToText.GetCorpusWithContext(ctx, getCorpusOptions)
+		if getErr != nil {
+			return false, getErr
+		}
+		waitOptions.observe(corpus.Status)
+		if corpus.Status == nil {
+			return false, nil
+		}
+		if *corpus.Status == CorpusStatusUndeterminedConst {
+			return false, &ResourceFailedError{Resource: "corpus", Name: corpusName, Status: *corpus.Status, Message: core.StringNilMapper(corpus.Error)}
+		}
+		return *corpus.Status == CorpusStatusAnalyzedConst, nil
+	})
+	return
+}
+
+// WaitForGrammar : Polls GetGrammar until the grammar is `analyzed`. A grammar that ends up `undetermined` is
+// reported as a *ResourceFailedError.
+func (speechToText *SpeechToTextV1) WaitForGrammar(ctx context.Context, customizationID string, grammarName string, waitOptions *WaitOptions) (grammar *Grammar, err error) {
+	getGrammarOptions := speechToText.NewGetGrammarOptions(customizationID, grammarName)
+	err = polling.Poll(ctx, waitOptions.backoff(), func() (bool, error) {
+		var getErr error
+		grammar, _, getErr = speechToText.GetGrammarWithContext(ctx, getGrammarOptions)
+		if getErr != nil {
+			return false, getErr
+		}
+		waitOptions.observe(grammar.Status)
+		if grammar.Status == nil {
+			return false, nil
+		}
+		if *grammar.Status == GrammarStatusUndeterminedConst {
+			return false, &ResourceFailedError{Resource: "grammar", Name: grammarName, Status: *grammar.Status, Message: core.StringNilMapper(grammar.Error)}
+		}
+		return *grammar.Status == GrammarStatusAnalyzedConst, nil
+	})
+	return
+}
+
+// WaitForAudio : Polls GetAudio until the audio resource is `ok`. For an archive-type resource the status of the
+// archive container is used. A resource that ends up `invalid` is reported as a *ResourceFailedError.
+func (speechToText *SpeechToTextV1) WaitForAudio(ctx context.Context, customizationID string, audioName string, waitOptions *WaitOptions) (audio *AudioListing, err error) {
+	getAudioOptions := speechToText.NewGetAudioOptions(customizationID, audioName)
+	err = polling.Poll(ctx, waitOptions.backoff(), func() (bool, error) {
+		var getErr error
+		audio, _, getErr = speechToText.GetAudioWithContext(ctx, getAudioOptions)
+		if getErr != nil {
+			return false, getErr
+		}
+		status := audio.Status
+		if status == nil && audio.Container != nil {
+			status = audio.Container.Status
+		}
+		waitOptions.observe(status)
+		if status == nil {
+			return false, nil
+		}
+		if *status == AudioListingStatusInvalidConst {
+			return false, &ResourceFailedError{Resource: "audio resource", Name: audioName, Status: *status}
+		}
+		return *status == AudioListingStatusOkConst, nil
+	})
+	return
+}
+
+// TrainLanguageModelAndWait : Starts training of a custom language model and waits until the model is `available`.
+// The training warnings returned by the service are passed back alongside the model.
+func (speechToText *SpeechToTextV1) TrainLanguageModelAndWait(ctx context.Context, trainLanguageModelOptions *TrainLanguageModelOptions, waitOptions *WaitOptions) (model *LanguageModel, warnings []TrainingWarning, err error) {
+	trainingResponse, _, err := speechToText.TrainLanguageModelWithContext(ctx, trainLanguageModelOptions)
+	if err != nil {
+		return
+	}
+	if trainingResponse != nil {
+		warnings = trainingResponse.Warnings
+	}
+	model, err = speechToText.waitForLanguageModel(ctx, *trainLanguageModelOptions.CustomizationID, waitOptions, LanguageModelStatusAvailableConst)
+	return
+}
+
+// TrainAcousticModelAndWait : Starts training of a custom acoustic model and waits until the model is `available`.
+// The training warnings returned by the service are passed back alongside the model.
+func (speechToText *SpeechToTextV1) TrainAcousticModelAndWait(ctx context.Context, trainAcousticModelOptions *TrainAcousticModelOptions, waitOptions *WaitOptions) (model *AcousticModel, warnings []TrainingWarning, err error) {
+	trainingResponse, _, err := speechToText.TrainAcousticModelWithContext(ctx, trainAcousticModelOptions)
+	if err != nil {
+		return
+	}
+	if trainingResponse != nil {
+		warnings = trainingResponse.Warnings
+	}
+	model, err = speechToText.waitForAcousticModel(ctx, *trainAcousticModelOptions.CustomizationID, waitOptions, AcousticModelStatusAvailableConst)
+	return
+}
+
+func hasStatus(status string, statuses []string) bool {
+	for _, candidate := range statuses {
+		if status == candidate {
+			return true
+		}
+	}
+	return false
+}
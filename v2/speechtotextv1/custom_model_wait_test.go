@@ -0,0 +1,176 @@
+/**
+ * (C) Copyright IBM Corp. 2021.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package speechtotextv1_test
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"time"
+
+	"github.com/IBM/go-sdk-core/v5/core"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/watson-developer-cloud/go-sdk/v2/speechtotextv1"
+)
+
+var _ = Describe(`Custom model WaitFor methods`, func() {
+	var testServer *httptest.Server
+	var speechToTextService *speechtotextv1.SpeechToTextV1
+
+	// responses maps a request path to the bodies returned by successive GET requests; the last body repeats
+	var mutex sync.Mutex
+	var responses map[string][]string
+
+	waitOptions := &speechtotextv1.WaitOptions{
+		Backoff: &speechtotextv1.Backoff{InitialInterval: time.Millisecond, MaxInterval: 2 * time.Millisecond},
+	}
+
+	BeforeEach(func() {
+		responses = map[string][]string{}
+		testServer = httptest.NewServer(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+			res.Header().Set("Content-type", "application/json")
+			if req.Method == "POST" {
+				res.WriteHeader(200)
+				fmt.Fprint(res, `{"warnings":[{"code":"invalid_audio_files","message":"Analysis of the following audio files failed"}]}`)
+				return
+			}
+			mutex.Lock()
+			defer mutex.Unlock()
+			bodies, ok := responses[req.URL.EscapedPath()]
+			if !ok {
+				res.WriteHeader(404)
+				fmt.Fprint(res, `{"error":"not found","code":404}`)
+				return
+			}
+			res.WriteHeader(200)
+			fmt.Fprint(res, bodies[0])
+			if len(bodies) > 1 {
+				responses[req.URL.EscapedPath()] = bodies[1:]
+			}
+		}))
+		var serviceErr error
+		speechToTextService, serviceErr = speechtotextv1.NewSpeechToTextV1(&speechtotextv1.SpeechToTextV1Options{
+			URL:           testServer.URL,
+			Authenticator: &core.NoAuthAuthenticator{},
+		})
+		Expect(serviceErr).To(BeNil())
+	})
+	AfterEach(func() {
+		testServer.Close()
+	})
+
+	It(`Invoke WaitForLanguageModel until the model is ready`, func() {
+		responses["/v1/customizations/lm"] = []string{
+			`{"customization_id":"lm","status":"pending"}`,
+			`{"customization_id":"lm","status":"ready"}`,
+		}
+		var statuses []string
+		model, err := speechToTextService.WaitForLanguageModel(context.Background(), "lm", &speechtotextv1.WaitOptions{
+			Backoff:  waitOptions.Backoff,
+			OnStatus: func(status string) { statuses = append(statuses, status) },
+		})
+		Expect(err).To(BeNil())
+		Expect(*model.Status).To(Equal("ready"))
+		Expect(statuses).To(Equal([]string{"pending", "ready"}))
+	})
+	It(`Invoke WaitForLanguageModel with a failed model`, func() {
+		responses["/v1/customizations/lm"] = []string{`{"customization_id":"lm","status":"failed","error":"Cannot compile grammar"}`}
+		_, err := speechToTextService.WaitForLanguageModel(context.Background(), "lm", waitOptions)
+		Expect(err).To(BeAssignableToTypeOf(&speechtotextv1.ResourceFailedError{}))
+		Expect(err.Error()).To(Equal("language model lm is failed: Cannot compile grammar"))
+	})
+	It(`Invoke WaitForLanguageModel and cancel`, func() {
+		responses["/v1/customizations/lm"] = []string{`{"customization_id":"lm","status":"pending"}`}
+		ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+		defer cancel()
+		_, err := speechToTextService.WaitForLanguageModel(ctx, "lm", waitOptions)
+		Expect(err).To(Equal(context.DeadlineExceeded))
+	})
+	It(`Invoke TrainLanguageModelAndWait`, func() {
+		responses["/v1/customizations/lm"] = []string{
+			`{"customization_id":"lm","status":"ready"}`,
+			`{"customization_id":"lm","status":"training"}`,
+			`{"customization_id":"lm","status":"available"}`,
+		}
+		model, warnings, err := speechToTextService.TrainLanguageModelAndWait(context.Background(), speechToTextService.NewTrainLanguageModelOptions("lm"), waitOptions)
+		Expect(err).To(BeNil())
+		Expect(*model.Status).To(Equal("available"))
+		Expect(warnings).To(HaveLen(1))
+		Expect(*warnings[0].Code).To(Equal("invalid_audio_files"))
+	})
+	It(`Invoke TrainAcousticModelAndWait`, func() {
+		responses["/v1/acoustic_customizations/am"] = []string{
+			`{"customization_id":"am","status":"training"}`,
+			`{"customization_id":"am","status":"available"}`,
+		}
+		model, warnings, err := speechToTextService.TrainAcousticModelAndWait(context.Background(), speechToTextService.NewTrainAcousticModelOptions("am"), waitOptions)
+		Expect(err).To(BeNil())
+		Expect(*model.Status).To(Equal("available"))
+		Expect(warnings).To(HaveLen(1))
+	})
+	It(`Invoke WaitForAcousticModel with a failed model`, func() {
+		responses["/v1/acoustic_customizations/am"] = []string{`{"customization_id":"am","status":"failed"}`}
+		_, err := speechToTextService.WaitForAcousticModel(context.Background(), "am", waitOptions)
+		Expect(err).To(BeAssignableToTypeOf(&speechtotextv1.ResourceFailedError{}))
+	})
+	It(`Invoke WaitForCorpus`, func() {
+		responses["/v1/customizations/lm/corpora/corpus1"] = []string{
+			`{"name":"corpus1","total_words":0,"out_of_vocabulary_words":0,"status":"being_processed"}`,
+			`{"name":"corpus1","total_words":10,"out_of_vocabulary_words":1,"status":"analyzed"}`,
+		}
+		corpus, err := speechToTextService.WaitForCorpus(context.Background(), "lm", "corpus1", waitOptions)
+		Expect(err).To(BeNil())
+		Expect(*corpus.TotalWords).To(Equal(int64(10)))
+
+		responses["/v1/customizations/lm/corpora/corpus2"] = []string{
+			`{"name":"corpus2","total_words":0,"out_of_vocabulary_words":0,"status":"undetermined","error":"Analysis of corpus 'corpus2' failed"}`,
+		}
+		_, err = speechToTextService.WaitForCorpus(context.Background(), "lm", "corpus2", waitOptions)
+		Expect(err).To(BeAssignableToTypeOf(&speechtotextv1.ResourceFailedError{}))
+		Expect(err.(*speechtotextv1.ResourceFailedError).Message).To(Equal("Analysis of corpus 'corpus2' failed"))
+	})
+	It(`Invoke WaitForGrammar`, func() {
+		responses["/v1/customizations/lm/grammars/grammar1"] = []string{
+			`{"name":"grammar1","out_of_vocabulary_words":0,"status":"being_processed"}`,
+			`{"name":"grammar1","out_of_vocabulary_words":0,"status":"analyzed"}`,
+		}
+		grammar, err := speechToTextService.WaitForGrammar(context.Background(), "lm", "grammar1", waitOptions)
+		Expect(err).To(BeNil())
+		Expect(*grammar.Status).To(Equal("analyzed"))
+	})
+	It(`Invoke WaitForAudio for an archive`, func() {
+		responses["/v1/acoustic_customizations/am/audio/audio1"] = []string{
+			`{"name":"audio1","duration":10,"container":{"name":"audio1","duration":10,"details":{},"status":"being_processed"}}`,
+			`{"name":"audio1","duration":10,"container":{"name":"audio1","duration":10,"details":{},"status":"ok"}}`,
+		}
+		audio, err := speechToTextService.WaitForAudio(context.Background(), "am", "audio1", waitOptions)
+		Expect(err).To(BeNil())
+		Expect(*audio.Container.Status).To(Equal("ok"))
+
+		responses["/v1/acoustic_customizations/am/audio/audio2"] = []string{`{"name":"audio2","duration":10,"status":"invalid"}`}
+		_, err = speechToTextService.WaitForAudio(context.Background(), "am", "audio2", waitOptions)
+		Expect(err).To(BeAssignableToTypeOf(&speechtotextv1.ResourceFailedError{}))
+	})
+	It(`Invoke WaitForCorpus with a service error`, func() {
+		_, err := speechToTextService.WaitForCorpus(context.Background(), "lm", "missing", waitOptions)
+		Expect(err).ToNot(BeNil())
+		Expect(err).ToNot(BeAssignableToTypeOf(&speechtotextv1.ResourceFailedError{}))
+	})
+})
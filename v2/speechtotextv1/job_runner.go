@@ -0,0 +1,351 @@
+package speechtotextv1
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha1"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/IBM/go-sdk-core/v5/core"
+	"github.com/watson-developer-cloud/go-sdk/v2/internal/polling"
+)
+
+// Events of the callback notifications sent for asynchronous recognition jobs
+const (
+	JobNotificationEventStartedConst              = "recognitions.started"
+	JobNotificationEventCompletedConst            = "recognitions.completed"
+	JobNotificationEventCompletedWithResultsConst = "recognitions.completed_with_results"
+	JobNotificationEventFailedConst               = "recognitions.failed"
+)
+
+const (
+	// CallbackSignatureHeader is the header in which the service sends the HMAC-SHA1 signature of a callback request
+	CallbackSignatureHeader = "X-Callback-Signature"
+
+	// CallbackChallengeParameter is the query parameter of the callback URL verification request
+	CallbackChallengeParameter = "challenge_string"
+)
+
+// JobFailedError is returned when a recognition job ends in the `failed` state
+type JobFailedError struct {
+	Job *RecognitionJob
+}
+
+func (e *JobFailedError) Error() string {
+	if e.Job != nil && e.Job.ID != nil {
+		return fmt.Sprintf("recognition job %s failed", *e.Job.ID)
+	}
+	return "recognition job failed"
+}
+
+// JobNotification : A callback notification sent by the service for an asynchronous recognition job
+type JobNotification struct {
+	// The ID of the job.
+	ID string `json:"id"`
+
+	// The event that triggered the notification.
+	Event string `json:"event"`
+
+	// The user token that was specified when the job was created.
+	UserToken string `json:"user_token,omitempty"`
+
+	// The results of the job, for the `recognitions.completed_with_results` event.
+	Results []SpeechRecognitionResults `json:"results,omitempty"`
+}
+
+// The defaults of the limits of JobRunnerOptions.
+const (
+	DefaultMaxPendingNotifications = 1000
+	DefaultPendingNotificationTTL  = 10 * time.Minute
+)
+
+// JobRunnerOptions : Options for a JobRunner
+type JobRunnerOptions struct {
+	// How often to poll CheckJob for jobs created without a callback URL.
+	Backoff *Backoff
+
+	// The secret used to register the callback URL. When set, the handler rejects callback requests whose
+	// X-Callback-Signature header does not match.
+	UserSecret string
+
+	// The number of notifications kept for jobs that nobody waits for yet, such as jobs whose notification arrives
+	// before CreateJob returns or jobs of another process that uses the same callback URL. The oldest is dropped when
+	// a new one would exceed the limit. Defaults to DefaultMaxPendingNotifications.
+	MaxPendingNotifications int
+
+	// How long a notification is kept for a job that nobody waits for. Defaults to DefaultPendingNotificationTTL.
+	PendingNotificationTTL time.Duration
+}
+
+// JobRunner : Runs asynchronous recognition jobs to completion. Jobs created without a callback URL are polled with
+// CheckJob. Jobs created with a callback URL wait for the service's notification, which the runner receives as an
+// http.Handler mounted at that URL.
+type JobRunner struct {
+	speechToText *SpeechToTextV1
+	options      JobRunnerOptions
+
+	mutex   sync.Mutex
+	waiters map[string]chan *JobNotification
+	pending map[string]pendingNotification
+}
+
+// pendingNotification : A notification kept until a waiter subscribes, with the time it was received
+type pendingNotification struct {
+	notification *JobNotification
+	received     time.Time
+}
+
+// NewJobRunner : Instantiate a JobRunner. The options may be nil.
+func (speechToText *SpeechToTextV1) NewJobRunner(options *JobRunnerOptions) *JobRunner {
+	runner := &JobRunner{
+		speechToText: speechToText,
+		waiters:      make(map[string]chan *JobNotification),
+		pending:      make(map[string]pendingNotification),
+	}
+	if options != nil {
+		runner.options = *options
+	}
+	if runner.options.MaxPendingNotifications <= 0 {
+		runner.options.MaxPendingNotifications = DefaultMaxPendingNotifications
+	}
+	if runner.options.PendingNotificationTTL <= 0 {
+		runner.options.PendingNotificationTTL = DefaultPendingNotificationTTL
+	}
+	return runner
+}
+
+// RegisterCallback : Registers callbackURL with the service, using the runner's user secret
+func (runner *JobRunner) RegisterCallback(ctx context.Context, callbackURL string) (result *RegisterStatus, response *core.DetailedResponse, err error) {
+	registerCallbackOptions := runner.speechToText.NewRegisterCallbackOptions(callbackURL)
+	if runner.options.UserSecret != "" {
+		registerCallbackOptions.SetUserSecret(runner.options.UserSecret)
+	}
+	return runner.speechToText.RegisterCallbackWithContext(ctx, registerCallbackOptions)
+}
+
+// UnregisterCallback : Removes callbackURL from the service's allowlist
+func (runner *JobRunner) UnregisterCallback(ctx context.Context, callbackURL string) (response *core.DetailedResponse, err error) {
+	return runner.speechToText.UnregisterCallbackWithContext(ctx, runner.speechToText.NewUnregisterCallbackOptions(callbackURL))
+}
+
+// Run : Creates a recognition job and waits for it to finish. The results of the job are merged into a single
+// SpeechRecognitionResults. A job that fails is reported as a *JobFailedError.
+func (runner *JobRunner) Run(ctx context.Context, createJobOptions *CreateJobOptions) (result *SpeechRecognitionResults, job *RecognitionJob, err error) {
+	job, _, err = runner.speechToText.CreateJobWithContext(ctx, createJobOptions)
+	if err != nil {
+		return
+	}
+	if job.ID == nil {
+		err = fmt.Errorf("the service did not return a job ID")
+		return
+	}
+
+	if createJobOptions.CallbackURL != nil {
+		job, err = runner.WaitForNotification(ctx, *job.ID)
+	} else {
+		job, err = runner.WaitForJob(ctx, *job.ID)
+	}
+	if err != nil {
+		return
+	}
+	result = MergeSpeechRecognitionResults(job.Results)
+	return
+}
+
+// WaitForJob : Polls CheckJob until the job is `completed` or `failed`
+func (runner *JobRunner) WaitForJob(ctx context.Context, id string) (job *RecognitionJob, err error) {
+	checkJobOptions := runner.speechToText.NewCheckJobOptions(id)
+	err = polling.Poll(ctx, runner.options.Backoff, func() (bool, error) {
+		var checkErr error
+		job, _, checkErr = runner.speechToText.CheckJobWithContext(ctx, checkJobOptions)
+		if checkErr != nil {
+			return false, checkErr
+		}
+		return job.Status != nil && (*job.Status == RecognitionJobStatusCompletedConst || *job.Status == RecognitionJobStatusFailedConst), nil
+	})
+	if err == nil && *job.Status == RecognitionJobStatusFailedConst {
+		err = &JobFailedError{Job: job}
+	}
+	return
+}
+
+// WaitForNotification : Waits for the runner's handler to receive the completion or failure notification of the job.
+// When the notification does not carry the results, they are retrieved with CheckJob.
+func (runner *JobRunner) WaitForNotification(ctx context.Context, id string) (job *RecognitionJob, err error) {
+	notifications := runner.subscribe(id)
+	defer runner.unsubscribe(id)
+
+	for {
+		var notification *JobNotification
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case notification = <-notifications:
+		}
+
+		switch notification.Event {
+		case JobNotificationEventCompletedWithResultsConst:
+			job = &RecognitionJob{
+				ID:      core.StringPtr(notification.ID),
+				Status:  core.StringPtr(RecognitionJobStatusCompletedConst),
+				Results: notification.Results,
+			}
+			if notification.UserToken != "" {
+				job.UserToken = core.StringPtr(notification.UserToken)
+			}
+			return job, nil
+		case JobNotificationEventCompletedConst:
+			job, _, err = runner.speechToText.CheckJobWithContext(ctx, runner.speechToText.NewCheckJobOptions(id))
+			return
+		case JobNotificationEventFailedConst:
+			job = &RecognitionJob{
+				ID:     core.StringPtr(notification.ID),
+				Status: core.StringPtr(RecognitionJobStatusFailedConst),
+			}
+			return job, &JobFailedError{Job: job}
+		}
+	}
+}
+
+// ServeHTTP : Handles the requests the service sends to a callback URL. GET requests verify the URL by echoing the
+// challenge string; POST requests deliver job notifications.
+func (runner *JobRunner) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		challenge := r.URL.Query().Get(CallbackChallengeParameter)
+		if challenge == "" {
+			http.Error(w, "missing "+CallbackChallengeParameter, http.StatusBadRequest)
+			return
+		}
+		if !runner.verifySignature(r, []byte(challenge)) {
+			http.Error(w, "invalid signature", http.StatusUnauthorized)
+			return
+		}
+		w.Header().Set("Content-Type", "text/plain")
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write([]byte(challenge))
+	case http.MethodPost:
+		body, err := ioutil.ReadAll(r.Body)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if !runner.verifySignature(r, body) {
+			http.Error(w, "invalid signature", http.StatusUnauthorized)
+			return
+		}
+		notification := new(JobNotification)
+		if err := json.Unmarshal(body, notification); err != nil || notification.ID == "" {
+			http.Error(w, "invalid notification", http.StatusBadRequest)
+			return
+		}
+		runner.deliver(notification)
+		w.WriteHeader(http.StatusOK)
+	default:
+		w.Header().Set("Allow", "GET, POST")
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// verifySignature : Checks the X-Callback-Signature header against payload, when the runner has a user secret
+func (runner *JobRunner) verifySignature(r *http.Request, payload []byte) bool {
+	if runner.options.UserSecret == "" {
+		return true
+	}
+	signature, err := base64.StdEncoding.DecodeString(r.Header.Get(CallbackSignatureHeader))
+	if err != nil {
+		return false
+	}
+	return hmac.Equal(signature, CallbackSignature(runner.options.UserSecret, payload))
+}
+
+// CallbackSignature : Computes the HMAC-SHA1 signature that the service sends for payload, before base64 encoding
+func CallbackSignature(userSecret string, payload []byte) []byte {
+	mac := hmac.New(sha1.New, []byte(userSecret))
+	mac.Write(payload)
+	return mac.Sum(nil)
+}
+
+// subscribe : Registers interest in the notifications of a job, replaying any that arrived early
+func (runner *JobRunner) subscribe(id string) <-chan *JobNotification {
+	runner.mutex.Lock()
+	defer runner.mutex.Unlock()
+	notifications := make(chan *JobNotification, 4)
+	runner.expirePending(time.Now())
+	if pending, ok := runner.pending[id]; ok {
+		notifications <- pending.notification
+		delete(runner.pending, id)
+	}
+	runner.waiters[id] = notifications
+	return notifications
+}
+
+func (runner *JobRunner) unsubscribe(id string) {
+	runner.mutex.Lock()
+	defer runner.mutex.Unlock()
+	delete(runner.waiters, id)
+}
+
+// deliver : Passes a notification to the waiter of its job. Terminal notifications for jobs that nobody waits for
+// yet are kept until a waiter subscribes, within the limits of the options.
+func (runner *JobRunner) deliver(notification *JobNotification) {
+	runner.mutex.Lock()
+	defer runner.mutex.Unlock()
+	if notifications, ok := runner.waiters[notification.ID]; ok {
+		select {
+		case notifications <- notification:
+		default:
+		}
+		return
+	}
+	if notification.Event == JobNotificationEventStartedConst {
+		return
+	}
+	now := time.Now()
+	runner.expirePending(now)
+	if _, ok := runner.pending[notification.ID]; !ok && len(runner.pending) >= runner.options.MaxPendingNotifications {
+		oldestID := ""
+		for id, pending := range runner.pending {
+			if oldestID == "" || pending.received.Before(runner.pending[oldestID].received) {
+				oldestID = id
+			}
+		}
+		delete(runner.pending, oldestID)
+	}
+	runner.pending[notification.ID] = pendingNotification{notification: notification, received: now}
+}
+
+// expirePending : Drops the kept notifications that are older than the TTL of the options
+func (runner *JobRunner) expirePending(now time.Time) {
+	for id, pending := range runner.pending {
+		if now.Sub(pending.received) > runner.options.PendingNotificationTTL {
+			delete(runner.pending, id)
+		}
+	}
+}
+
+// MergeSpeechRecognitionResults : Combines the results of a job into one SpeechRecognitionResults
+func MergeSpeechRecognitionResults(results []SpeechRecognitionResults) *SpeechRecognitionResults {
+	if len(results) == 1 {
+		return &results[0]
+	}
+	merged := &SpeechRecognitionResults{}
+	for _, result := range results {
+		merged.Results = append(merged.Results, result.Results...)
+		merged.SpeakerLabels = append(merged.SpeakerLabels, result.SpeakerLabels...)
+		merged.Warnings = append(merged.Warnings, result.Warnings...)
+		if result.ProcessingMetrics != nil {
+			merged.ProcessingMetrics = result.ProcessingMetrics
+		}
+		if result.AudioMetrics != nil {
+			merged.AudioMetrics = result.AudioMetrics
+		}
+	}
+	return merged
+}
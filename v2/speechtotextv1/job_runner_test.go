@@ -0,0 +1,198 @@
+/**
+ * (C) Copyright IBM Corp. 2021.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package speechtotextv1_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/IBM/go-sdk-core/v5/core"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/watson-developer-cloud/go-sdk/v2/speechtotextv1"
+)
+
+const jobResults = `[{"result_index":0,"results":[{"final":true,"alternatives":[{"transcript":"hello world","confidence":0.9}]}]}]`
+
+var _ = Describe(`JobRunner`, func() {
+	var testServer *httptest.Server
+	var speechToTextService *speechtotextv1.SpeechToTextV1
+	var checks int32
+	var finalStatus string
+
+	fastBackoff := &speechtotextv1.Backoff{InitialInterval: time.Millisecond, MaxInterval: 5 * time.Millisecond}
+
+	BeforeEach(func() {
+		atomic.StoreInt32(&checks, 0)
+		finalStatus = "completed"
+		testServer = httptest.NewServer(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+			defer GinkgoRecover()
+			res.Header().Set("Content-type", "application/json")
+			switch {
+			case req.Method == "POST" && req.URL.EscapedPath() == "/v1/recognitions":
+				res.WriteHeader(201)
+				fmt.Fprintf(res, `{"id": "job-1", "status": "waiting", "created": "2021-01-01T00:00:00.000Z"}`)
+			case req.Method == "GET" && req.URL.EscapedPath() == "/v1/recognitions/job-1":
+				res.WriteHeader(200)
+				if atomic.AddInt32(&checks, 1) < 3 {
+					fmt.Fprintf(res, `{"id": "job-1", "status": "processing", "created": "2021-01-01T00:00:00.000Z"}`)
+				} else {
+					fmt.Fprintf(res, `{"id": "job-1", "status": "%s", "created": "2021-01-01T00:00:00.000Z", "results": %s}`, finalStatus, jobResults)
+				}
+			default:
+				res.WriteHeader(404)
+			}
+		}))
+		var serviceErr error
+		speechToTextService, serviceErr = speechtotextv1.NewSpeechToTextV1(&speechtotextv1.SpeechToTextV1Options{
+			URL:           testServer.URL,
+			Authenticator: &core.NoAuthAuthenticator{},
+		})
+		Expect(serviceErr).To(BeNil())
+	})
+	AfterEach(func() {
+		testServer.Close()
+	})
+
+	It(`Invoke Run and poll until the job completes`, func() {
+		runner := speechToTextService.NewJobRunner(&speechtotextv1.JobRunnerOptions{Backoff: fastBackoff})
+		options := speechToTextService.NewCreateJobOptions(ioutil.NopCloser(strings.NewReader("audio")))
+
+		result, job, err := runner.Run(context.Background(), options)
+		Expect(err).To(BeNil())
+		Expect(*job.Status).To(Equal("completed"))
+		Expect(*result.Results[0].Alternatives[0].Transcript).To(Equal("hello world"))
+		Expect(atomic.LoadInt32(&checks)).To(Equal(int32(3)))
+	})
+	It(`Invoke Run and poll until the job fails`, func() {
+		finalStatus = "failed"
+		runner := speechToTextService.NewJobRunner(&speechtotextv1.JobRunnerOptions{Backoff: fastBackoff})
+		options := speechToTextService.NewCreateJobOptions(ioutil.NopCloser(strings.NewReader("audio")))
+
+		_, _, err := runner.Run(context.Background(), options)
+		Expect(err).To(BeAssignableToTypeOf(&speechtotextv1.JobFailedError{}))
+		Expect(err.(*speechtotextv1.JobFailedError).Job.ID).To(Equal(core.StringPtr("job-1")))
+	})
+	It(`Invoke Run and cancel while polling`, func() {
+		runner := speechToTextService.NewJobRunner(&speechtotextv1.JobRunnerOptions{
+			Backoff: &speechtotextv1.Backoff{InitialInterval: time.Hour},
+		})
+		options := speechToTextService.NewCreateJobOptions(ioutil.NopCloser(strings.NewReader("audio")))
+
+		ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+		defer cancel()
+		_, _, err := runner.Run(ctx, options)
+		Expect(err).To(Equal(context.DeadlineExceeded))
+	})
+	It(`Invoke Run and wait for a callback notification`, func() {
+		runner := speechToTextService.NewJobRunner(&speechtotextv1.JobRunnerOptions{UserSecret: "secret"})
+		callbackServer := httptest.NewServer(runner)
+		defer callbackServer.Close()
+
+		options := speechToTextService.NewCreateJobOptions(ioutil.NopCloser(strings.NewReader("audio")))
+		options.SetCallbackURL(callbackServer.URL).SetEvents(speechtotextv1.CreateJobOptionsEventsRecognitionsCompletedWithResultsConst)
+
+		notify := func(body string, secret string) int {
+			req, err := http.NewRequest("POST", callbackServer.URL, bytes.NewBufferString(body))
+			Expect(err).To(BeNil())
+			signature := speechtotextv1.CallbackSignature(secret, []byte(body))
+			req.Header.Set(speechtotextv1.CallbackSignatureHeader, base64.StdEncoding.EncodeToString(signature))
+			res, err := http.DefaultClient.Do(req)
+			Expect(err).To(BeNil())
+			res.Body.Close()
+			return res.StatusCode
+		}
+		go func() {
+			defer GinkgoRecover()
+			time.Sleep(10 * time.Millisecond)
+			Expect(notify(`{"id":"job-1","event":"recognitions.started"}`, "secret")).To(Equal(200))
+			Expect(notify(`{"id":"job-1","event":"recognitions.completed_with_results","results":`+jobResults+`}`, "wrong")).To(Equal(401))
+			Expect(notify(`{"id":"job-1","event":"recognitions.completed_with_results","results":`+jobResults+`}`, "secret")).To(Equal(200))
+		}()
+
+		result, job, err := runner.Run(context.Background(), options)
+		Expect(err).To(BeNil())
+		Expect(*job.Status).To(Equal("completed"))
+		Expect(*result.Results[0].Alternatives[0].Transcript).To(Equal("hello world"))
+		Expect(atomic.LoadInt32(&checks)).To(Equal(int32(0)))
+	})
+	It(`Invoke Run with a failure notification that arrives before the waiter`, func() {
+		runner := speechToTextService.NewJobRunner(nil)
+		callbackServer := httptest.NewServer(runner)
+		defer callbackServer.Close()
+
+		res, err := http.Post(callbackServer.URL, "application/json", strings.NewReader(`{"id":"job-1","event":"recognitions.failed"}`))
+		Expect(err).To(BeNil())
+		Expect(res.StatusCode).To(Equal(200))
+
+		options := speechToTextService.NewCreateJobOptions(ioutil.NopCloser(strings.NewReader("audio")))
+		options.SetCallbackURL(callbackServer.URL)
+		_, _, err = runner.Run(context.Background(), options)
+		Expect(err).To(BeAssignableToTypeOf(&speechtotextv1.JobFailedError{}))
+	})
+	It(`Drop notifications for jobs that nobody waits for`, func() {
+		runner := speechToTextService.NewJobRunner(&speechtotextv1.JobRunnerOptions{MaxPendingNotifications: 2, PendingNotificationTTL: 50 * time.Millisecond})
+		callbackServer := httptest.NewServer(runner)
+		defer callbackServer.Close()
+
+		for _, id := range []string{"job-1", "job-2", "job-3"} {
+			res, err := http.Post(callbackServer.URL, "application/json", strings.NewReader(`{"id":"`+id+`","event":"recognitions.failed"}`))
+			Expect(err).To(BeNil())
+			res.Body.Close()
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+		defer cancel()
+		_, err := runner.WaitForNotification(ctx, "job-1")
+		Expect(err).To(Equal(context.DeadlineExceeded))
+		_, err = runner.WaitForNotification(context.Background(), "job-2")
+		Expect(err).To(BeAssignableToTypeOf(&speechtotextv1.JobFailedError{}))
+
+		time.Sleep(60 * time.Millisecond)
+		ctx, cancel = context.WithTimeout(context.Background(), 20*time.Millisecond)
+		defer cancel()
+		_, err = runner.WaitForNotification(ctx, "job-3")
+		Expect(err).To(Equal(context.DeadlineExceeded))
+	})
+	It(`Answer the callback URL challenge`, func() {
+		runner := speechToTextService.NewJobRunner(&speechtotextv1.JobRunnerOptions{UserSecret: "secret"})
+		callbackServer := httptest.NewServer(runner)
+		defer callbackServer.Close()
+
+		req, err := http.NewRequest("GET", callbackServer.URL+"?challenge_string=abc123", nil)
+		Expect(err).To(BeNil())
+		req.Header.Set(speechtotextv1.CallbackSignatureHeader, base64.StdEncoding.EncodeToString(speechtotextv1.CallbackSignature("secret", []byte("abc123"))))
+		res, err := http.DefaultClient.Do(req)
+		Expect(err).To(BeNil())
+		body, _ := ioutil.ReadAll(res.Body)
+		res.Body.Close()
+		Expect(res.StatusCode).To(Equal(200))
+		Expect(string(body)).To(Equal("abc123"))
+
+		res, err = http.Get(callbackServer.URL + "?challenge_string=abc123")
+		Expect(err).To(BeNil())
+		res.Body.Close()
+		Expect(res.StatusCode).To(Equal(401))
+	})
+})
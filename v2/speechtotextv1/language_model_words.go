@@ -0,0 +1,281 @@
+package speechtotextv1
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/IBM/go-sdk-core/v5/core"
+	"github.com/watson-developer-cloud/go-sdk/v2/internal/polling"
+	"github.com/watson-developer-cloud/go-sdk/v2/internal/wordsync"
+)
+
+// SyncWordsOptions : Options for SyncLanguageModelWords
+type SyncWordsOptions struct {
+	// Delete the user-added words of the model that are not in the desired set. Note that DeleteWord removes a word
+	// regardless of its source, including words that also come from corpora or grammars.
+	DeleteExtra bool
+
+	// Compute the change plan without changing the model.
+	DryRun bool
+
+	// The number of words sent in one AddWords request. Defaults to 1000.
+	BatchSize int
+
+	// How long to wait before retrying a request that the service rejects with 409 Conflict because it is still
+	// processing earlier words. Defaults to waiting one second, backing off to 30 seconds.
+	Backoff *Backoff
+
+	// The number of times a request is retried after 409 Conflict before the conflict is returned. Defaults to
+	// DefaultMaxConflictRetries.
+	MaxConflictRetries int
+}
+
+// DefaultMaxConflictRetries is the number of times SyncLanguageModelWords retries a request after 409 Conflict when
+// SyncWordsOptions does not say
+const DefaultMaxConflictRetries = 10
+
+// WordSyncPlan : The changes that SyncLanguageModelWords makes, or would make in dry-run mode, to a custom model
+type WordSyncPlan struct {
+	// The desired words that the model does not have.
+	Add []CustomWord `json:"add,omitempty"`
+
+	// The desired words whose sounds-like pronunciations or display-as spelling differ in the model.
+	Update []CustomWord `json:"update,omitempty"`
+
+	// The user-added words of the model that are not in the desired set. Filled in only with DeleteExtra.
+	Delete []string `json:"delete,omitempty"`
+
+	// The number of desired words that the model already has as desired.
+	Unchanged int `json:"unchanged"`
+}
+
+// HasChanges : Reports whether the plan changes the model
+func (plan *WordSyncPlan) HasChanges() bool {
+	return len(plan.Add) > 0 || len(plan.Update) > 0 || len(plan.Delete) > 0
+}
+
+// SyncLanguageModelWords : Makes the user-added words of a custom language model match desired. The words of the
+// model are listed with ListWords (`word_type=user`); words that are missing or whose sounds-like pronunciations or
+// display-as spelling differ are added in batches with AddWords, and, with DeleteExtra, words that are not desired
+// are deleted with DeleteWord. A desired word without sounds-like pronunciations leaves the pronunciations of the
+// model alone, because the service generates one for words that have none. The options may be nil.
+//
+// The plan is returned in all cases; in dry-run mode the model is not changed. The service processes added words
+// asynchronously, so the model is not ready for training until WaitForLanguageModel reports it so.
+func (speechToText *SpeechToTextV1) SyncLanguageModelWords(ctx context.Context, customizationID string, desired []CustomWord, syncOptions *SyncWordsOptions) (plan *WordSyncPlan, err error) {
+	if syncOptions == nil {
+		syncOptions = &SyncWordsOptions{}
+	}
+	desiredWords := make([]string, len(desired))
+	for i, word := range desired {
+		desiredWords[i] = core.StringNilMapper(word.Word)
+	}
+
+	listWordsOptions := speechToText.NewListWordsOptions(customizationID)
+	listWordsOptions.SetWordType(ListWordsOptionsWordTypeUserConst)
+	listWordsOptions.SetSort(ListWordsOptionsSortAlphabeticalConst)
+	existing, _, err := speechToText.ListWordsWithContext(ctx, listWordsOptions)
+	if err != nil {
+		return nil, err
+	}
+	existingWords := make([]string, len(existing.Words))
+	for i, word := range existing.Words {
+		existingWords[i] = core.StringNilMapper(word.Word)
+	}
+	changes, err := wordsync.NewPlan(desiredWords, existingWords, func(desiredIndex int, existingIndex int) bool {
+		return customWordMatches(desired[desiredIndex], &existing.Words[existingIndex])
+	}, syncOptions.DeleteExtra)
+	if err != nil {
+		return nil, err
+	}
+
+	plan = &WordSyncPlan{
+		Add:       pickCustomWords(desired, changes.Add),
+		Update:    pickCustomWords(desired, changes.Update),
+		Delete:    changes.Delete,
+		Unchanged: changes.Unchanged,
+	}
+	if syncOptions.DryRun {
+		return plan, nil
+	}
+	err = changes.Apply(syncOptions.BatchSize, func(batch []int) error {
+		addWordsOptions := speechToText.NewAddWordsOptions(customizationID, pickCustomWords(desired, batch))
+		return retryOnConflict(ctx, syncOptions.Backoff, syncOptions.MaxConflictRetries, func() (*core.DetailedResponse, error) {
+			return speechToText.AddWordsWithContext(ctx, addWordsOptions)
+		})
+	}, func(word string) error {
+		deleteWordOptions := speechToText.NewDeleteWordOptions(customizationID, word)
+		return retryOnConflict(ctx, syncOptions.Backoff, syncOptions.MaxConflictRetries, func() (*core.DetailedResponse, error) {
+			return speechToText.DeleteWordWithContext(ctx, deleteWordOptions)
+		})
+	})
+	return plan, err
+}
+
+// customWordMatches : Reports whether the model's word already has the pronunciations and spelling of desired
+func customWordMatches(desired CustomWord, current *Word) bool {
+	desiredDisplayAs := core.StringNilMapper(desired.DisplayAs)
+	if desiredDisplayAs == *desired.Word {
+		desiredDisplayAs = ""
+	}
+	currentDisplayAs := core.StringNilMapper(current.DisplayAs)
+	if currentDisplayAs == *desired.Word {
+		currentDisplayAs = ""
+	}
+	if desiredDisplayAs != currentDisplayAs {
+		return false
+	}
+	if len(desired.SoundsLike) == 0 {
+		return true
+	}
+	return sameStrings(desired.SoundsLike, current.SoundsLike)
+}
+
+// sameStrings : Reports whether a and b hold the same strings, in any order
+func sameStrings(a []string, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	sortedA := append([]string{}, a...)
+	sortedB := append([]string{}, b...)
+	sort.Strings(sortedA)
+	sort.Strings(sortedB)
+	for i := range sortedA {
+		if sortedA[i] != sortedB[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// pickCustomWords : Returns the words at the indexes
+func pickCustomWords(words []CustomWord, indexes []int) []CustomWord {
+	var picked []CustomWord
+	for _, i := range indexes {
+		picked = append(picked, words[i])
+	}
+	return picked
+}
+
+// retryOnConflict : Calls request until the service stops answering 409 Conflict, which it does while it is still
+// processing an earlier request for the model, or until the conflict has been retried maxRetries times, in which
+// case the last conflict is returned
+func retryOnConflict(ctx context.Context, backoff *Backoff, maxRetries int, request func() (*core.DetailedResponse, error)) error {
+	if maxRetries <= 0 {
+		maxRetries = DefaultMaxConflictRetries
+	}
+	retries := 0
+	return polling.Poll(ctx, backoff, func() (bool, error) {
+		response, err := request()
+		if err != nil && response != nil && response.StatusCode == http.StatusConflict && retries < maxRetries {
+			retries++
+			return false, nil
+		}
+		return true, err
+	})
+}
+
+// wordListCSVHeader is the header row written by WriteCustomWordsCSV
+var wordListCSVHeader = []string{"word", "display_as", "sounds_like"}
+
+// ReadCustomWordsCSV : Reads custom words from CSV. Each record holds a word, its display-as spelling (empty for
+// none) and any number of sounds-like pronunciations in the remaining fields. A first record that starts with the
+// `word` column name is taken as a header and skipped, as are blank fields.
+func ReadCustomWordsCSV(reader io.Reader) ([]CustomWord, error) {
+	csvReader := csv.NewReader(reader)
+	csvReader.FieldsPerRecord = -1
+	csvReader.TrimLeadingSpace = true
+	csvReader.Comment = '#'
+
+	var words []CustomWord
+	for recordNumber := 1; ; recordNumber++ {
+		record, err := csvReader.Read()
+		if err == io.EOF {
+			return words, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		if recordNumber == 1 && strings.EqualFold(strings.TrimSpace(record[0]), wordListCSVHeader[0]) {
+			continue
+		}
+		word := strings.TrimSpace(record[0])
+		if word == "" {
+			return nil, fmt.Errorf("record %d: missing word", recordNumber)
+		}
+		customWord := CustomWord{Word: core.StringPtr(word)}
+		if len(record) > 1 {
+			if displayAs := strings.TrimSpace(record[1]); displayAs != "" {
+				customWord.DisplayAs = core.StringPtr(displayAs)
+			}
+		}
+		for i := 2; i < len(record); i++ {
+			if soundsLike := strings.TrimSpace(record[i]); soundsLike != "" {
+				customWord.SoundsLike = append(customWord.SoundsLike, soundsLike)
+			}
+		}
+		words = append(words, customWord)
+	}
+}
+
+// WriteCustomWordsCSV : Writes custom words as CSV in the layout read by ReadCustomWordsCSV, with a header row. The
+// words are written in alphabetical order so that the file diffs cleanly under version control.
+func WriteCustomWordsCSV(writer io.Writer, words []CustomWord) error {
+	csvWriter := csv.NewWriter(writer)
+	if err := csvWriter.Write(wordListCSVHeader); err != nil {
+		return err
+	}
+	for _, word := range sortedCustomWords(words) {
+		record := append([]string{core.StringNilMapper(word.Word), core.StringNilMapper(word.DisplayAs)}, word.SoundsLike...)
+		if err := csvWriter.Write(record); err != nil {
+			return err
+		}
+	}
+	csvWriter.Flush()
+	return csvWriter.Error()
+}
+
+// ReadCustomWordsJSON : Reads custom words from JSON, either an array of words or an object with a `words` array as
+// accepted by AddWords and returned by ListWords
+func ReadCustomWordsJSON(reader io.Reader) ([]CustomWord, error) {
+	data, err := ioutil.ReadAll(reader)
+	if err != nil {
+		return nil, err
+	}
+	trimmed := strings.TrimSpace(string(data))
+	if strings.HasPrefix(trimmed, "[") {
+		var words []CustomWord
+		err = json.Unmarshal(data, &words)
+		return words, err
+	}
+	var wordList struct {
+		Words []CustomWord `json:"words"`
+	}
+	err = json.Unmarshal(data, &wordList)
+	return wordList.Words, err
+}
+
+// WriteCustomWordsJSON : Writes custom words as an indented JSON object with a `words` array, in alphabetical order
+func WriteCustomWordsJSON(writer io.Writer, words []CustomWord) error {
+	encoder := json.NewEncoder(writer)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(struct {
+		Words []CustomWord `json:"words"`
+	}{Words: sortedCustomWords(words)})
+}
+
+// sortedCustomWords : Returns a copy of words in alphabetical order; words without a word sort first
+func sortedCustomWords(words []CustomWord) []CustomWord {
+	sorted := append([]CustomWord{}, words...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return core.StringNilMapper(sorted[i].Word) < core.StringNilMapper(sorted[j].Word)
+	})
+	return sorted
+}
@@ -0,0 +1,221 @@
+/**
+ * (C) Copyright IBM Corp. 2021.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package speechtotextv1_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/IBM/go-sdk-core/v5/core"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/watson-developer-cloud/go-sdk/v2/speechtotextv1"
+)
+
+var _ = Describe(`SyncLanguageModelWords`, func() {
+	var testServer *httptest.Server
+	var speechToTextService *speechtotextv1.SpeechToTextV1
+
+	var mutex sync.Mutex
+	var listQuery string
+	var addedBatches [][]speechtotextv1.CustomWord
+	var deleted []string
+	var conflicts int
+
+	syncOptions := func(options speechtotextv1.SyncWordsOptions) *speechtotextv1.SyncWordsOptions {
+		options.Backoff = &speechtotextv1.Backoff{InitialInterval: time.Millisecond, MaxInterval: 2 * time.Millisecond}
+		return &options
+	}
+
+	BeforeEach(func() {
+		listQuery = ""
+		addedBatches = nil
+		deleted = nil
+		conflicts = 0
+		testServer = httptest.NewServer(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+			mutex.Lock()
+			defer mutex.Unlock()
+			res.Header().Set("Content-type", "application/json")
+			switch {
+			case req.Method == "GET" && req.URL.EscapedPath() == "/v1/customizations/lm/words":
+				listQuery = req.URL.RawQuery
+				res.WriteHeader(200)
+				fmt.Fprint(res, `{"words":[
+					{"word":"HHonors","sounds_like":["hilton honors","h honors"],"display_as":"HHonors","count":1,"source":["user"]},
+					{"word":"IEEE","sounds_like":["i triple e"],"display_as":"IEEE","count":1,"source":["user"]},
+					{"word":"tachycardia","sounds_like":["tacky cardia"],"display_as":"","count":1,"source":["user"]},
+					{"word":"obsolete","sounds_like":["obsolete"],"display_as":"","count":1,"source":["user"]}
+				]}`)
+			case req.Method == "POST" && req.URL.EscapedPath() == "/v1/customizations/lm/words":
+				if conflicts > 0 {
+					conflicts--
+					res.WriteHeader(409)
+					fmt.Fprint(res, `{"error":"Customization is being processed","code":409}`)
+					return
+				}
+				var body struct {
+					Words []speechtotextv1.CustomWord `json:"words"`
+				}
+				Expect(json.NewDecoder(req.Body).Decode(&body)).To(Succeed())
+				addedBatches = append(addedBatches, body.Words)
+				res.WriteHeader(201)
+				fmt.Fprint(res, `{}`)
+			case req.Method == "DELETE" && strings.HasPrefix(req.URL.EscapedPath(), "/v1/customizations/lm/words/"):
+				deleted = append(deleted, strings.TrimPrefix(req.URL.Path, "/v1/customizations/lm/words/"))
+				res.WriteHeader(200)
+				fmt.Fprint(res, `{}`)
+			default:
+				res.WriteHeader(404)
+				fmt.Fprint(res, `{"error":"not found","code":404}`)
+			}
+		}))
+		var serviceErr error
+		speechToTextService, serviceErr = speechtotextv1.NewSpeechToTextV1(&speechtotextv1.SpeechToTextV1Options{
+			URL:           testServer.URL,
+			Authenticator: &core.NoAuthAuthenticator{},
+		})
+		Expect(serviceErr).To(BeNil())
+	})
+	AfterEach(func() {
+		testServer.Close()
+	})
+
+	desired := []speechtotextv1.CustomWord{
+		{Word: core.StringPtr("IEEE"), SoundsLike: []string{"i triple e"}},
+		{Word: core.StringPtr("HHonors"), SoundsLike: []string{"h honors", "hilton honors"}},
+		{Word: core.StringPtr("tachycardia"), SoundsLike: []string{"tacky cardia", "tack ee cardia"}},
+		{Word: core.StringPtr("NCAA"), SoundsLike: []string{"n c double a"}, DisplayAs: core.StringPtr("N.C.A.A.")},
+		{Word: core.StringPtr("bradycardia")},
+	}
+
+	It(`Invoke SyncLanguageModelWords in dry-run mode`, func() {
+		plan, err := speechToTextService.SyncLanguageModelWords(context.Background(), "lm", desired, syncOptions(speechtotextv1.SyncWordsOptions{DryRun: true, DeleteExtra: true}))
+		Expect(err).To(BeNil())
+		Expect(listQuery).To(ContainSubstring("word_type=user"))
+
+		Expect(plan.HasChanges()).To(BeTrue())
+		Expect(plan.Add).To(HaveLen(2))
+		Expect(*plan.Add[0].Word).To(Equal("NCAA"))
+		Expect(*plan.Add[1].Word).To(Equal("bradycardia"))
+		Expect(plan.Update).To(HaveLen(1))
+		Expect(*plan.Update[0].Word).To(Equal("tachycardia"))
+		Expect(plan.Delete).To(Equal([]string{"obsolete"}))
+		Expect(plan.Unchanged).To(Equal(2))
+
+		Expect(addedBatches).To(BeEmpty())
+		Expect(deleted).To(BeEmpty())
+	})
+
+	It(`Invoke SyncLanguageModelWords to add words in batches and delete extras`, func() {
+		conflicts = 1
+		plan, err := speechToTextService.SyncLanguageModelWords(context.Background(), "lm", desired, syncOptions(speechtotextv1.SyncWordsOptions{BatchSize: 2, DeleteExtra: true}))
+		Expect(err).To(BeNil())
+		Expect(plan.Add).To(HaveLen(2))
+
+		Expect(addedBatches).To(HaveLen(2))
+		Expect(addedBatches[0]).To(HaveLen(2))
+		Expect(*addedBatches[0][1].Word).To(Equal("bradycardia"))
+		Expect(addedBatches[1]).To(HaveLen(1))
+		Expect(*addedBatches[1][0].Word).To(Equal("tachycardia"))
+		Expect(deleted).To(Equal([]string{"obsolete"}))
+	})
+
+	It(`Invoke SyncLanguageModelWords and give up after too many conflicts`, func() {
+		conflicts = 10
+		_, err := speechToTextService.SyncLanguageModelWords(context.Background(), "lm", desired, syncOptions(speechtotextv1.SyncWordsOptions{MaxConflictRetries: 2}))
+		Expect(err).ToNot(BeNil())
+		Expect(err.Error()).To(ContainSubstring("Customization is being processed"))
+		Expect(conflicts).To(Equal(7))
+		Expect(addedBatches).To(BeEmpty())
+	})
+
+	It(`Invoke SyncLanguageModelWords without deleting extras`, func() {
+		plan, err := speechToTextService.SyncLanguageModelWords(context.Background(), "lm", desired, nil)
+		Expect(err).To(BeNil())
+		Expect(plan.Delete).To(BeEmpty())
+		Expect(addedBatches).To(HaveLen(1))
+		Expect(addedBatches[0]).To(HaveLen(3))
+		Expect(deleted).To(BeEmpty())
+	})
+
+	It(`Invoke SyncLanguageModelWords with a duplicate word`, func() {
+		duplicated := append([]speechtotextv1.CustomWord{{Word: core.StringPtr("IEEE")}}, desired...)
+		plan, err := speechToTextService.SyncLanguageModelWords(context.Background(), "lm", duplicated, nil)
+		Expect(err).ToNot(BeNil())
+		Expect(plan).To(BeNil())
+	})
+})
+
+var _ = Describe(`Custom word lists`, func() {
+	words := []speechtotextv1.CustomWord{
+		{Word: core.StringPtr("tachycardia"), SoundsLike: []string{"tacky cardia", "tack ee cardia"}},
+		{Word: core.StringPtr("NCAA"), SoundsLike: []string{"n c double a"}, DisplayAs: core.StringPtr("N.C.A.A.")},
+		{Word: core.StringPtr("bradycardia")},
+	}
+
+	It(`Write and read custom words as CSV`, func() {
+		var buffer bytes.Buffer
+		Expect(speechtotextv1.WriteCustomWordsCSV(&buffer, words)).To(Succeed())
+		Expect(buffer.String()).To(Equal("word,display_as,sounds_like\n" +
+			"NCAA,N.C.A.A.,n c double a\n" +
+			"bradycardia,\n" +
+			"tachycardia,,tacky cardia,tack ee cardia\n"))
+
+		read, err := speechtotextv1.ReadCustomWordsCSV(&buffer)
+		Expect(err).To(BeNil())
+		Expect(read).To(HaveLen(3))
+		Expect(*read[0].DisplayAs).To(Equal("N.C.A.A."))
+		Expect(read[1].DisplayAs).To(BeNil())
+		Expect(read[1].SoundsLike).To(BeEmpty())
+		Expect(read[2].SoundsLike).To(Equal([]string{"tacky cardia", "tack ee cardia"}))
+	})
+
+	It(`Read custom words from CSV without a header`, func() {
+		read, err := speechtotextv1.ReadCustomWordsCSV(strings.NewReader("# medical terms\nstat\nECG, , e c g\n"))
+		Expect(err).To(BeNil())
+		Expect(read).To(HaveLen(2))
+		Expect(*read[0].Word).To(Equal("stat"))
+		Expect(read[1].DisplayAs).To(BeNil())
+		Expect(read[1].SoundsLike).To(Equal([]string{"e c g"}))
+
+		_, err = speechtotextv1.ReadCustomWordsCSV(strings.NewReader("stat\n,N.C.A.A.\n"))
+		Expect(err).ToNot(BeNil())
+	})
+
+	It(`Write and read custom words as JSON`, func() {
+		var buffer bytes.Buffer
+		Expect(speechtotextv1.WriteCustomWordsJSON(&buffer, words)).To(Succeed())
+		Expect(buffer.String()).To(HavePrefix("{\n  \"words\": [\n"))
+
+		read, err := speechtotextv1.ReadCustomWordsJSON(&buffer)
+		Expect(err).To(BeNil())
+		Expect(read).To(HaveLen(3))
+		Expect(*read[0].Word).To(Equal("NCAA"))
+
+		read, err = speechtotextv1.ReadCustomWordsJSON(ioutil.NopCloser(strings.NewReader(`[{"word":"stat"}]`)))
+		Expect(err).To(BeNil())
+		Expect(read).To(HaveLen(1))
+	})
+})
@@ -0,0 +1,291 @@
+package speechtotextv1
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/IBM/go-sdk-core/v5/core"
+	"github.com/gorilla/websocket"
+)
+
+var (
+	// ErrSessionNotStarted is returned when audio is written or a stop is requested outside of an utterance
+	ErrSessionNotStarted = errors.New("recognize session: no utterance has been started")
+
+	// ErrSessionStarted is returned when Start is called while an utterance is in progress
+	ErrSessionStarted = errors.New("recognize session: an utterance is already in progress")
+
+	// ErrSessionClosed is returned when the session is used after its connection has closed
+	ErrSessionClosed = errors.New("recognize session: the connection is closed")
+)
+
+// RecognizeSession : A push-based websocket recognition session. Audio is written to the session as it becomes
+// available instead of being read from RecognizeUsingWebsocketOptions.Audio. One connection can carry several
+// utterances, each begun with Start and ended with Stop.
+type RecognizeSession struct {
+	ctx      context.Context
+	conn     *websocket.Conn
+	callback RecognizeCallbackWrapper
+
+	// writeMutex serializes writes to the connection
+	writeMutex sync.Mutex
+
+	// stateMutex guards started and err
+	stateMutex sync.Mutex
+	started    bool
+	err        error
+
+	listening chan struct{}
+	done      chan struct{}
+	closing   chan struct{}
+	closeOnce sync.Once
+}
+
+// NewRecognizeSession : Opens a recognize websocket and starts the first utterance with recognizeWSOptions
+func (speechToText *SpeechToTextV1) NewRecognizeSession(recognizeWSOptions *RecognizeUsingWebsocketOptions, callback RecognizeCallbackWrapper) (*RecognizeSession, error) {
+	return speechToText.NewRecognizeSessionWithContext(context.Background(), recognizeWSOptions, callback)
+}
+
+// NewRecognizeSessionWithContext is an alternate form of the NewRecognizeSession method which supports a Context parameter.
+// The connection is dialed with ctx and is closed when ctx is cancelled. The model and customization parameters of
+// recognizeWSOptions apply to the whole connection; the Audio field is ignored.
+func (speechToText *SpeechToTextV1) NewRecognizeSessionWithContext(ctx context.Context, recognizeWSOptions *RecognizeUsingWebsocketOptions, callback RecognizeCallbackWrapper) (session *RecognizeSession, err error) {
+	err = core.ValidateNotNil(recognizeWSOptions, "recognizeOptions cannot be nil")
+	if err != nil {
+		return
+	}
+	err = core.ValidateNotNil(callback, "callback cannot be nil")
+	if err != nil {
+		return
+	}
+
+	dialURL, param, headers, err := speechToText.recognizeWebsocketRequest(recognizeWSOptions)
+	if err != nil {
+		return
+	}
+
+	conn, _, err := websocket.DefaultDialer.DialContext(ctx, fmt.Sprintf("%s%s?%s", dialURL, RECOGNIZE_ENDPOINT, param.Encode()), headers)
+	if err != nil {
+		return
+	}
+
+	session = &RecognizeSession{
+		ctx:       ctx,
+		conn:      conn,
+		callback:  callback,
+		listening: make(chan struct{}, 1),
+		done:      make(chan struct{}),
+		closing:   make(chan struct{}),
+	}
+	callback.OnOpen()
+	go session.receive()
+	go func() {
+		select {
+		case <-ctx.Done():
+			session.conn.Close()
+		case <-session.done:
+		}
+	}()
+
+	err = session.Start(recognizeWSOptions)
+	if err != nil {
+		session.Close()
+		session = nil
+	}
+	return
+}
+
+// Start : Begins a new utterance on the session. It returns once the service has acknowledged the start message.
+func (session *RecognizeSession) Start(recognizeWSOptions *RecognizeUsingWebsocketOptions) error {
+	if err := core.ValidateNotNil(recognizeWSOptions, "recognizeOptions cannot be nil"); err != nil {
+		return err
+	}
+
+	session.stateMutex.Lock()
+	if session.started {
+		session.stateMutex.Unlock()
+		return ErrSessionStarted
+	}
+	session.started = true
+	session.stateMutex.Unlock()
+
+	startOptions := *recognizeWSOptions
+	startOptions.Audio = nil
+	startOptions.Action = core.StringPtr("start")
+	startMsgBytes, err := json.Marshal(startOptions)
+	if err != nil {
+		session.setStarted(false)
+		return err
+	}
+	if err := session.exchange(websocket.TextMessage, startMsgBytes); err != nil {
+		session.setStarted(false)
+		return err
+	}
+	return nil
+}
+
+// Write : Sends a frame of audio for the current utterance. It implements io.Writer.
+func (session *RecognizeSession) Write(audio []byte) (int, error) {
+	if !session.isStarted() {
+		return 0, ErrSessionNotStarted
+	}
+	if err := session.write(websocket.BinaryMessage, audio); err != nil {
+		return 0, err
+	}
+	return len(audio), nil
+}
+
+// Stop : Ends the current utterance. It returns once the service has delivered the final results for the utterance,
+// after which Start may be called again.
+func (session *RecognizeSession) Stop() error {
+	if !session.isStarted() {
+		return ErrSessionNotStarted
+	}
+	stopMsgBytes, _ := json.Marshal(RecognizeUsingWebsocketOptions{Action: core.StringPtr("stop")})
+	err := session.exchange(websocket.TextMessage, stopMsgBytes)
+	session.setStarted(false)
+	return err
+}
+
+// Close : Closes the connection and waits for the session to finish. Any utterance in progress is abandoned.
+func (session *RecognizeSession) Close() error {
+	session.closeOnce.Do(func() {
+		close(session.closing)
+		_ = session.write(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseNormalClosure, ""))
+		session.conn.Close()
+	})
+	<-session.done
+	return nil
+}
+
+// Done : Returns a channel that is closed when the session's connection has closed
+func (session *RecognizeSession) Done() <-chan struct{} {
+	return session.done
+}
+
+// Err : Returns the first error reported during the session, or the context error if the session was cancelled
+func (session *RecognizeSession) Err() error {
+	session.stateMutex.Lock()
+	defer session.stateMutex.Unlock()
+	if session.err != nil {
+		return session.err
+	}
+	return session.ctx.Err()
+}
+
+// exchange : Sends a control message and waits for the service to report the listening state
+func (session *RecognizeSession) exchange(messageType int, message []byte) error {
+	// Discard any stray acknowledgement so that we wait for the one that answers this message
+	select {
+	case <-session.listening:
+	default:
+	}
+	if err := session.write(messageType, message); err != nil {
+		return err
+	}
+	select {
+	case <-session.listening:
+		return nil
+	case <-session.done:
+		return session.closedErr()
+	case <-session.ctx.Done():
+		return session.ctx.Err()
+	}
+}
+
+// write : Writes a single message, serialized with all other writes on the session
+func (session *RecognizeSession) write(messageType int, message []byte) error {
+	select {
+	case <-session.done:
+		return session.closedErr()
+	default:
+	}
+	session.writeMutex.Lock()
+	defer session.writeMutex.Unlock()
+	return session.conn.WriteMessage(messageType, message)
+}
+
+// receive : Reads messages until the connection closes, dispatching results to the callback
+func (session *RecognizeSession) receive() {
+	for {
+		_, result, err := session.conn.ReadMessage()
+		if err != nil {
+			if !session.closeRequested() && session.ctx.Err() == nil && !websocket.IsCloseError(err, websocket.CloseNormalClosure) {
+				session.onError(err)
+			}
+			break
+		}
+
+		var websocketResponse WebsocketRecognitionResults
+		err = json.Unmarshal(result, &websocketResponse)
+		if err != nil {
+			session.onError(err)
+			break
+		}
+
+		dispatchRecognizeMessage(session.callback, &websocketResponse)
+
+		if websocketResponse.State == "listening" {
+			select {
+			case session.listening <- struct{}{}:
+			default:
+			}
+			continue
+		}
+
+		if len(websocketResponse.Error) > 0 {
+			session.onError(errors.New(websocketResponse.Error))
+			break
+		}
+
+		detailResp := core.DetailedResponse{}
+		detailResp.Result = result
+		detailResp.StatusCode = SUCCESS
+		session.callback.OnData(&detailResp)
+	}
+	session.conn.Close()
+	session.callback.OnClose()
+	close(session.done)
+}
+
+// onError : Records the first error of the session and reports it to the callback
+func (session *RecognizeSession) onError(err error) {
+	session.stateMutex.Lock()
+	if session.err == nil {
+		session.err = err
+	}
+	session.stateMutex.Unlock()
+	session.callback.OnError(err)
+}
+
+// closedErr : Returns the error to report for operations attempted after the connection closed
+func (session *RecognizeSession) closedErr() error {
+	if err := session.Err(); err != nil {
+		return err
+	}
+	return ErrSessionClosed
+}
+
+func (session *RecognizeSession) closeRequested() bool {
+	select {
+	case <-session.closing:
+		return true
+	default:
+		return false
+	}
+}
+
+func (session *RecognizeSession) isStarted() bool {
+	session.stateMutex.Lock()
+	defer session.stateMutex.Unlock()
+	return session.started
+}
+
+func (session *RecognizeSession) setStarted(started bool) {
+	session.stateMutex.Lock()
+	defer session.stateMutex.Unlock()
+	session.started = started
+}
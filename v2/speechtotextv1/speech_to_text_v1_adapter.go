@@ -1,14 +1,15 @@
 package speechtotextv1
 
 import (
+	"context"
 	"fmt"
 	"io"
 
 	"github.com/IBM/go-sdk-core/v5/core"
+	"github.com/watson-developer-cloud/go-sdk/v2/internal/websocketurl"
 
 	"net/http"
 	"net/url"
-	"strings"
 	"time"
 )
 
@@ -45,8 +46,28 @@ type RecognizeUsingWebsocketOptions struct {
 	// instead of at periodic intervals, set the value to a large number. If the value is larger than the duration of the
 	// audio, the service returns processing metrics only for transcription events.
 	ProcessingMetricsInterval *float32 `json:"processing_metrics_interval,omitempty"`
+
+	// How the audio upload is paced. By default a short pause is made between chunks. Allowable values: none,
+	// real_time, bytes_per_second. Real-time pacing derives the byte rate from the `rate` and `channels` parameters of
+	// an `audio/l16`, `audio/mulaw`, `audio/alaw` or `audio/basic` content type; it is not supported for container and
+	// compressed formats such as `audio/wav`, `audio/ogg` or `audio/mp3`, which can be paced with bytes_per_second.
+	Pacing *string `json:"-"`
+
+	// The upload rate in bytes per second when Pacing is `bytes_per_second`.
+	BytesPerSecond *int64 `json:"-"`
+
+	// The size in bytes of each audio chunk sent to the service. Defaults to 2 KB.
+	ChunkSize *int64 `json:"-"`
 }
 
+// Constants associated with the RecognizeUsingWebsocketOptions.Pacing property.
+// How the audio upload is paced.
+const (
+	RecognizeUsingWebsocketOptionsPacingBytesPerSecondConst = "bytes_per_second"
+	RecognizeUsingWebsocketOptionsPacingNoneConst           = "none"
+	RecognizeUsingWebsocketOptionsPacingRealTimeConst       = "real_time"
+)
+
 // SetAction: Allows user to set the Action
 func (recognizeWSOptions *RecognizeUsingWebsocketOptions) SetAction(action string) *RecognizeUsingWebsocketOptions {
 	recognizeWSOptions.Action = core.StringPtr(action)
@@ -71,11 +92,32 @@ func (recognizeWSOptions *RecognizeUsingWebsocketOptions) SetProcessingMetricsIn
 	return recognizeWSOptions
 }
 
-// NewRecognizeUsingWebsocketOptions: Instantiate RecognizeOptions to enable websocket support
+// SetPacing : Allow user to set Pacing
+func (recognizeWSOptions *RecognizeUsingWebsocketOptions) SetPacing(pacing string) *RecognizeUsingWebsocketOptions {
+	recognizeWSOptions.Pacing = core.StringPtr(pacing)
+	return recognizeWSOptions
+}
+
+// SetBytesPerSecond : Allow user to set BytesPerSecond
+func (recognizeWSOptions *RecognizeUsingWebsocketOptions) SetBytesPerSecond(bytesPerSecond int64) *RecognizeUsingWebsocketOptions {
+	recognizeWSOptions.BytesPerSecond = core.Int64Ptr(bytesPerSecond)
+	return recognizeWSOptions
+}
+
+// SetChunkSize : Allow user to set ChunkSize
+func (recognizeWSOptions *RecognizeUsingWebsocketOptions) SetChunkSize(chunkSize int64) *RecognizeUsingWebsocketOptions {
+	recognizeWSOptions.ChunkSize = core.Int64Ptr(chunkSize)
+	return recognizeWSOptions
+}
+
+// NewRecognizeUsingWebsocketOptions: Instantiate RecognizeOptions to enable websocket support. If contentType is
+// empty, it is detected from the audio when the recognition starts.
 func (speechToText *SpeechToTextV1) NewRecognizeUsingWebsocketOptions(audio io.ReadCloser, contentType string) *RecognizeUsingWebsocketOptions {
 	recognizeOptions := speechToText.NewRecognizeOptions(audio)
-	recognizeOptions.SetContentType(contentType)
-	recognizeWSOptions := &RecognizeUsingWebsocketOptions{*recognizeOptions, nil, nil, nil, nil}
+	if contentType != "" {
+		recognizeOptions.SetContentType(contentType)
+	}
+	recognizeWSOptions := &RecognizeUsingWebsocketOptions{RecognizeOptions: *recognizeOptions}
 	return recognizeWSOptions
 }
 
@@ -95,7 +137,31 @@ type RecognizeCallbackWrapper interface {
 	OnError(error)
 }
 
-// RecognizeUsingWebsocket: Recognize audio over websocket connection
+// RecognizeResultsCallback : An optional extension of RecognizeCallbackWrapper. When the callback passed to a
+// websocket recognition implements it, each message from the service is also decoded and routed to the matching
+// method in addition to OnData.
+type RecognizeResultsCallback interface {
+	RecognizeCallbackWrapper
+
+	// OnResults receives messages that carry transcription results or warnings
+	OnResults(*SpeechRecognitionResults)
+
+	// OnSpeakerLabels receives the speaker labels of a message, when speaker labels were requested
+	OnSpeakerLabels([]SpeakerLabelsResult)
+
+	// OnProcessingMetrics receives processing metrics, when processing metrics were requested
+	OnProcessingMetrics(*ProcessingMetrics)
+
+	// OnAudioMetrics receives audio metrics, when audio metrics were requested
+	OnAudioMetrics(*AudioMetrics)
+
+	// OnStateChange receives state notifications from the service, such as `listening`
+	OnStateChange(state string)
+}
+
+// RecognizeUsingWebsocket: Recognize audio over websocket connection. Invalid options and authentication failures
+// panic, and errors once the connection is dialed are passed to callback.OnError; RecognizeUsingWebsocketWithContext
+// returns them instead.
 func (speechToText *SpeechToTextV1) RecognizeUsingWebsocket(recognizeWSOptions *RecognizeUsingWebsocketOptions, callback RecognizeCallbackWrapper) {
 	if err := core.ValidateNotNil(recognizeWSOptions, "recognizeOptions cannot be nil"); err != nil {
 		panic(err)
@@ -103,25 +169,77 @@ func (speechToText *SpeechToTextV1) RecognizeUsingWebsocket(recognizeWSOptions *
 	if err := core.ValidateStruct(recognizeWSOptions, "recognizeOptions"); err != nil {
 		panic(err)
 	}
+	dialURL, param, headers, err := speechToText.recognizeWebsocketRequest(recognizeWSOptions)
+	if err != nil {
+		panic(err)
+	}
+	speechToText.NewRecognizeListener(callback, recognizeWSOptions, dialURL, param, headers)
+}
 
+// RecognizeUsingWebsocketWithContext is an alternate form of the RecognizeUsingWebsocket method which supports a Context parameter.
+// The connection is dialed with ctx; cancelling it stops the audio upload and closes the socket. Errors that occur before
+// the connection is open are returned without invoking the callback. Errors during the session are passed to
+// callback.OnError, and the first of them (or the context error) is returned once the session has closed.
+func (speechToText *SpeechToTextV1) RecognizeUsingWebsocketWithContext(ctx context.Context, recognizeWSOptions *RecognizeUsingWebsocketOptions, callback RecognizeCallbackWrapper) (err error) {
+	err = core.ValidateNotNil(recognizeWSOptions, "recognizeOptions cannot be nil")
+	if err != nil {
+		return
+	}
+	err = core.ValidateStruct(recognizeWSOptions, "recognizeOptions")
+	if err != nil {
+		return
+	}
+	err = core.ValidateNotNil(callback, "callback cannot be nil")
+	if err != nil {
+		return
+	}
+
+	if recognizeWSOptions.ContentType == nil {
+		err = recognizeWSOptions.DetectContentType(nil)
+		if err != nil {
+			return
+		}
+	}
+
+	_, err = newAudioPacer(recognizeWSOptions)
+	if err != nil {
+		return
+	}
+
+	dialURL, param, headers, err := speechToText.recognizeWebsocketRequest(recognizeWSOptions)
+	if err != nil {
+		return
+	}
+
+	return speechToText.NewRecognizeListenerWithContext(ctx, callback, recognizeWSOptions, dialURL, param, headers)
+}
+
+// recognizeWebsocketRequest : Builds the dial URL, query parameters and authenticated headers for a recognize websocket
+func (speechToText *SpeechToTextV1) recognizeWebsocketRequest(recognizeWSOptions *RecognizeUsingWebsocketOptions) (dialURL string, param url.Values, headers http.Header, err error) {
 	// Add authentication to the outbound request.
 	if speechToText.Service.Options.Authenticator == nil {
-		panic(fmt.Errorf("Authentication information was not properly configured."))
+		err = fmt.Errorf("Authentication information was not properly configured.")
+		return
 	}
 
 	// Create a dummy request for authenticate
 	// Need to update design to let recognizeListener take in a request object
-	req, _ := http.NewRequest("POST", speechToText.Service.Options.URL, nil)
-	err := speechToText.Service.Options.Authenticator.Authenticate(req)
+	req, err := http.NewRequest("POST", speechToText.Service.Options.URL, nil)
 	if err != nil {
-		panic(err)
+		return
+	}
+	err = speechToText.Service.Options.Authenticator.Authenticate(req)
+	if err != nil {
+		return
 	}
-	headers := req.Header
+	headers = req.Header
 
-	headers.Set("Content-Type", *recognizeWSOptions.ContentType)
+	if recognizeWSOptions.ContentType != nil {
+		headers.Set("Content-Type", *recognizeWSOptions.ContentType)
+	}
 
-	dialURL := strings.Replace(speechToText.Service.Options.URL, "https", "wss", 1)
-	param := url.Values{}
+	dialURL = websocketurl.FromServiceURL(speechToText.Service.Options.URL)
+	param = url.Values{}
 
 	if recognizeWSOptions.Model != nil {
 		param.Set("model", *recognizeWSOptions.Model)
@@ -135,6 +253,5 @@ func (speechToText *SpeechToTextV1) RecognizeUsingWebsocket(recognizeWSOptions *
 	if recognizeWSOptions.BaseModelVersion != nil {
 		param.Set("base_model_version", *recognizeWSOptions.BaseModelVersion)
 	}
-
-	speechToText.NewRecognizeListener(callback, recognizeWSOptions, dialURL, param, headers)
+	return
 }
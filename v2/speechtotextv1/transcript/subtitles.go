@@ -0,0 +1,212 @@
+/**
+ * (C) Copyright IBM Corp. 2021.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package transcript
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"math"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// Default cue limits, following common captioning guidelines
+const (
+	DefaultMaxCharsPerLine = 42
+	DefaultMaxLines        = 2
+	DefaultMaxDuration     = 7 * time.Second
+)
+
+// CueOptions : Options that control how a transcript is split into subtitle cues
+type CueOptions struct {
+	// The maximum number of characters on one line of a cue. Defaults to DefaultMaxCharsPerLine.
+	MaxCharsPerLine int
+
+	// The maximum number of lines in one cue. Defaults to DefaultMaxLines.
+	MaxLines int
+
+	// The maximum time a cue stays on screen. Defaults to DefaultMaxDuration.
+	MaxDuration time.Duration
+
+	// Start a new cue after a word that ends a sentence.
+	SplitOnSentences bool
+
+	// Mark the speaker of each cue with a WebVTT voice tag. Ignored for SRT.
+	VoiceTags bool
+
+	// Names to use in voice tags, by speaker. Speakers without a name are called "Speaker N".
+	SpeakerNames map[int64]string
+}
+
+// Cue : A subtitle cue
+type Cue struct {
+	// The start time of the cue in seconds from the beginning of the audio.
+	Start float64 `json:"start"`
+
+	// The end time of the cue in seconds from the beginning of the audio.
+	End float64 `json:"end"`
+
+	// The speaker of the cue, or UnknownSpeaker.
+	Speaker int64 `json:"speaker"`
+
+	// The text of the cue, wrapped into lines.
+	Lines []string `json:"lines"`
+}
+
+// withDefaults : Returns a copy of options with unset limits filled in
+func (options *CueOptions) withDefaults() CueOptions {
+	resolved := CueOptions{}
+	if options != nil {
+		resolved = *options
+	}
+	if resolved.MaxCharsPerLine <= 0 {
+		resolved.MaxCharsPerLine = DefaultMaxCharsPerLine
+	}
+	if resolved.MaxLines <= 0 {
+		resolved.MaxLines = DefaultMaxLines
+	}
+	if resolved.MaxDuration <= 0 {
+		resolved.MaxDuration = DefaultMaxDuration
+	}
+	return resolved
+}
+
+// Cues : Splits the transcript into subtitle cues. A cue never spans two speakers. Turns without word timestamps
+// cannot be timed and are skipped, as are hesitation markers such as `%HESITATION`. The options may be nil.
+func (transcript *Transcript) Cues(options *CueOptions) []Cue {
+	resolved := options.withDefaults()
+	maxDuration := resolved.MaxDuration.Seconds()
+
+	cues := []Cue{}
+	for _, turn := range transcript.Turns {
+		var words []Word
+		flush := func() {
+			if len(words) == 0 {
+				return
+			}
+			cues = append(cues, Cue{
+				Start:   words[0].Start,
+				End:     words[len(words)-1].End,
+				Speaker: turn.Speaker,
+				Lines:   wrapWords(words, resolved.MaxCharsPerLine),
+			})
+			words = nil
+		}
+
+		for _, word := range turn.Words {
+			if strings.HasPrefix(word.Text, "%") {
+				continue
+			}
+			if len(words) > 0 {
+				candidate := append(words[:len(words):len(words)], word)
+				switch {
+				case word.End-words[0].Start > maxDuration:
+					flush()
+				case len(wrapWords(candidate, resolved.MaxCharsPerLine)) > resolved.MaxLines:
+					flush()
+				case resolved.SplitOnSentences && endsSentence(words[len(words)-1].Text):
+					flush()
+				}
+			}
+			words = append(words, word)
+		}
+		flush()
+	}
+	return cues
+}
+
+// WriteSRT : Writes the transcript as a SubRip (SRT) subtitle file
+func (transcript *Transcript) WriteSRT(w io.Writer, options *CueOptions) error {
+	writer := bufio.NewWriter(w)
+	for i, cue := range transcript.Cues(options) {
+		fmt.Fprintf(writer, "%d\n%s --> %s\n", i+1, formatTimestamp(cue.Start, ","), formatTimestamp(cue.End, ","))
+		for _, line := range cue.Lines {
+			fmt.Fprintf(writer, "%s\n", line)
+		}
+		fmt.Fprint(writer, "\n")
+	}
+	return writer.Flush()
+}
+
+// WriteWebVTT : Writes the transcript as a WebVTT subtitle file
+func (transcript *Transcript) WriteWebVTT(w io.Writer, options *CueOptions) error {
+	resolved := options.withDefaults()
+	writer := bufio.NewWriter(w)
+	fmt.Fprint(writer, "WEBVTT\n\n")
+	for _, cue := range transcript.Cues(options) {
+		fmt.Fprintf(writer, "%s --> %s\n", formatTimestamp(cue.Start, "."), formatTimestamp(cue.End, "."))
+		for i, line := range cue.Lines {
+			line = escapeWebVTT(line)
+			if i == 0 && resolved.VoiceTags && cue.Speaker != UnknownSpeaker {
+				line = fmt.Sprintf("<v %s>%s", escapeWebVTT(resolved.speakerName(cue.Speaker)), line)
+			}
+			fmt.Fprintf(writer, "%s\n", line)
+		}
+		fmt.Fprint(writer, "\n")
+	}
+	return writer.Flush()
+}
+
+func (options CueOptions) speakerName(speaker int64) string {
+	if name, ok := options.SpeakerNames[speaker]; ok {
+		return name
+	}
+	return fmt.Sprintf("Speaker %d", speaker)
+}
+
+// wrapWords : Fills lines greedily with words up to maxChars characters. A word longer than a line gets a line of
+// its own.
+func wrapWords(words []Word, maxChars int) []string {
+	var lines []string
+	line := ""
+	for _, word := range words {
+		switch {
+		case line == "":
+			line = word.Text
+		case utf8.RuneCountInString(line)+1+utf8.RuneCountInString(word.Text) <= maxChars:
+			line += " " + word.Text
+		default:
+			lines = append(lines, line)
+			line = word.Text
+		}
+	}
+	if line != "" {
+		lines = append(lines, line)
+	}
+	return lines
+}
+
+func endsSentence(word string) bool {
+	return strings.HasSuffix(word, ".") || strings.HasSuffix(word, "?") || strings.HasSuffix(word, "!")
+}
+
+// formatTimestamp : Formats seconds as HH:MM:SS followed by the separator and milliseconds
+func formatTimestamp(seconds float64, separator string) string {
+	millis := int64(math.Round(seconds * 1000))
+	if millis < 0 {
+		millis = 0
+	}
+	return fmt.Sprintf("%02d:%02d:%02d%s%03d", millis/3600000, millis/60000%60, millis/1000%60, separator, millis%1000)
+}
+
+var webVTTEscaper = strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;")
+
+func escapeWebVTT(text string) string {
+	return webVTTEscaper.Replace(text)
+}
@@ -0,0 +1,98 @@
+/**
+ * (C) Copyright IBM Corp. 2021.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package transcript_test
+
+import (
+	"bytes"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/watson-developer-cloud/go-sdk/v2/speechtotextv1/transcript"
+)
+
+var _ = Describe(`Subtitles`, func() {
+	var assembler *transcript.Assembler
+
+	BeforeEach(func() {
+		assembler = transcript.NewAssembler(nil)
+		assembler.Add(recognitionResults(`{
+			"result_index": 0,
+			"results": [{
+				"final": true,
+				"alternatives": [{
+					"transcript": "welcome to the course. %HESITATION today we cover safety <rules> & more",
+					"timestamps": [
+						["welcome",0.0,0.4],["to",0.4,0.5],["the",0.5,0.6],["course.",0.6,1.1],
+						["%HESITATION",1.2,1.5],["today",1.6,1.9],["we",1.9,2.0],["cover",2.0,2.3],
+						["safety",2.3,2.8],["<rules>",2.8,3.2],["&",3.3,3.4],["more",3.4,3665.25]
+					]
+				}]
+			}]
+		}`))
+		assembler.Add(recognitionResults(`{"speaker_labels":[
+			{"from":0.0,"to":0.4,"speaker":0,"confidence":0.9,"final":true},
+			{"from":0.4,"to":0.5,"speaker":0,"confidence":0.9,"final":true},
+			{"from":0.5,"to":0.6,"speaker":0,"confidence":0.9,"final":true},
+			{"from":0.6,"to":1.1,"speaker":0,"confidence":0.9,"final":true},
+			{"from":1.2,"to":1.5,"speaker":1,"confidence":0.9,"final":true},
+			{"from":1.6,"to":1.9,"speaker":1,"confidence":0.9,"final":true},
+			{"from":1.9,"to":2.0,"speaker":1,"confidence":0.9,"final":true},
+			{"from":2.0,"to":2.3,"speaker":1,"confidence":0.9,"final":true},
+			{"from":2.3,"to":2.8,"speaker":1,"confidence":0.9,"final":true},
+			{"from":2.8,"to":3.2,"speaker":1,"confidence":0.9,"final":true},
+			{"from":3.3,"to":3.4,"speaker":1,"confidence":0.9,"final":true},
+			{"from":3.4,"to":3665.25,"speaker":1,"confidence":0.9,"final":true}
+		]}`))
+	})
+
+	It(`Splits cues by speaker and line length`, func() {
+		cues := assembler.Transcript().Cues(&transcript.CueOptions{MaxCharsPerLine: 12, MaxLines: 1, MaxDuration: time.Hour * 2})
+		Expect(cues).To(HaveLen(6))
+		Expect(cues[0].Lines).To(Equal([]string{"welcome to"}))
+		Expect(cues[1].Lines).To(Equal([]string{"the course."}))
+		Expect(cues[2].Lines).To(Equal([]string{"today we"}))
+		Expect(cues[2].Speaker).To(Equal(int64(1)))
+		Expect(cues[3].Lines).To(Equal([]string{"cover safety"}))
+		Expect(cues[4].Lines).To(Equal([]string{"<rules> &"}))
+		Expect(cues[5].Lines).To(Equal([]string{"more"}))
+	})
+	It(`Splits cues by duration and sentence`, func() {
+		cues := assembler.Transcript().Cues(&transcript.CueOptions{MaxDuration: 1500 * time.Millisecond, SplitOnSentences: true})
+		Expect(cues[0].Lines).To(Equal([]string{"welcome to the course."}))
+		Expect(cues[1].Lines).To(Equal([]string{"today we cover safety"}))
+	})
+	It(`Writes SRT`, func() {
+		var buffer bytes.Buffer
+		err := assembler.Transcript().WriteSRT(&buffer, nil)
+		Expect(err).To(BeNil())
+		Expect(buffer.String()).To(Equal("1\n00:00:00,000 --> 00:00:01,100\nwelcome to the course.\n\n" +
+			"2\n00:00:01,600 --> 00:00:03,400\ntoday we cover safety <rules> &\n\n" +
+			"3\n00:00:03,400 --> 01:01:05,250\nmore\n\n"))
+	})
+	It(`Writes WebVTT with voice tags`, func() {
+		var buffer bytes.Buffer
+		err := assembler.Transcript().WriteWebVTT(&buffer, &transcript.CueOptions{
+			SplitOnSentences: true,
+			VoiceTags:        true,
+			SpeakerNames:     map[int64]string{0: "Instructor"},
+		})
+		Expect(err).To(BeNil())
+		Expect(buffer.String()).To(HavePrefix("WEBVTT\n\n00:00:00.000 --> 00:00:01.100\n<v Instructor>welcome to the course.\n\n"))
+		Expect(buffer.String()).To(ContainSubstring("<v Speaker 1>today we cover safety &lt;rules&gt; &amp;\n"))
+	})
+})
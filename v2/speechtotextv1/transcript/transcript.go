@@ -0,0 +1,296 @@
+/**
+ * (C) Copyright IBM Corp. 2021.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package transcript assembles speaker-attributed transcripts from Speech to Text recognition results.
+//
+// An Assembler consumes SpeechRecognitionResults as they arrive from Recognize, CheckJob or a websocket
+// recognition, replaces interim results that the service has superseded, and joins the word timestamps of
+// each result with the speaker labels to produce speaker turns. A Transcript can be rendered as JSON, plain
+// text, or as SRT and WebVTT subtitles.
+package transcript
+
+import (
+	"encoding/json"
+	"math"
+	"strings"
+	"sync"
+
+	"github.com/watson-developer-cloud/go-sdk/v2/speechtotextv1"
+)
+
+// UnknownSpeaker is the speaker of words for which no speaker label has been received
+const UnknownSpeaker int64 = -1
+
+// labelTolerance is how far apart, in seconds, a word start and a speaker label start may be and still match
+const labelTolerance = 0.005
+
+// Word : A single word of the transcript
+type Word struct {
+	// The word as transcribed.
+	Text string `json:"text"`
+
+	// The start time of the word in seconds from the beginning of the audio.
+	Start float64 `json:"start"`
+
+	// The end time of the word in seconds from the beginning of the audio.
+	End float64 `json:"end"`
+
+	// The confidence score of the word, or zero if word confidence was not requested.
+	Confidence float64 `json:"confidence,omitempty"`
+
+	// The speaker of the word, or UnknownSpeaker.
+	Speaker int64 `json:"speaker"`
+}
+
+// Turn : A contiguous run of words spoken by one speaker
+type Turn struct {
+	// The speaker of the turn, or UnknownSpeaker.
+	Speaker int64 `json:"speaker"`
+
+	// The start time of the turn in seconds from the beginning of the audio.
+	Start float64 `json:"start"`
+
+	// The end time of the turn in seconds from the beginning of the audio.
+	End float64 `json:"end"`
+
+	// The words of the turn joined by spaces.
+	Text string `json:"text"`
+
+	// The mean confidence of the words of the turn, falling back to the confidence of the results it was built from.
+	Confidence float64 `json:"confidence"`
+
+	// Whether every result that contributed to the turn is final.
+	Final bool `json:"final"`
+
+	// The words of the turn.
+	Words []Word `json:"words"`
+}
+
+// Transcript : The speaker turns assembled from a recognition
+type Transcript struct {
+	Turns []Turn `json:"turns"`
+}
+
+// JSON : Returns the transcript encoded as JSON
+func (transcript *Transcript) JSON() ([]byte, error) {
+	return json.Marshal(transcript)
+}
+
+// Text : Returns the transcript as plain text, one turn per line
+func (transcript *Transcript) Text() string {
+	lines := make([]string, 0, len(transcript.Turns))
+	for _, turn := range transcript.Turns {
+		lines = append(lines, turn.Text)
+	}
+	return strings.Join(lines, "\n")
+}
+
+// AssemblerOptions : Options for an Assembler
+type AssemblerOptions struct {
+	// Include results that are not yet final. By default only final results are assembled.
+	IncludeInterim bool
+}
+
+// Assembler : Accumulates recognition results and speaker labels. It is safe for concurrent use, so it can be fed
+// directly from a websocket callback.
+type Assembler struct {
+	options AssemblerOptions
+
+	mutex   sync.Mutex
+	results []speechtotextv1.SpeechRecognitionResult
+	labels  []speechtotextv1.SpeakerLabelsResult
+}
+
+// NewAssembler : Instantiate an Assembler. The options may be nil.
+func NewAssembler(options *AssemblerOptions) *Assembler {
+	assembler := &Assembler{}
+	if options != nil {
+		assembler.options = *options
+	}
+	return assembler
+}
+
+// Add : Merges a set of recognition results. Results replace all those already held from their ResultIndex onwards,
+// so results that the service revised away are dropped; results without a ResultIndex are appended. A ResultIndex past
+// the results held leaves a gap of empty results until the missing results arrive.
+func (assembler *Assembler) Add(results *speechtotextv1.SpeechRecognitionResults) {
+	if results == nil {
+		return
+	}
+	assembler.mutex.Lock()
+	defer assembler.mutex.Unlock()
+
+	if len(results.Results) > 0 {
+		index := len(assembler.results)
+		if results.ResultIndex != nil && *results.ResultIndex >= 0 {
+			index = int(*results.ResultIndex)
+		}
+		for len(assembler.results) < index {
+			assembler.results = append(assembler.results, speechtotextv1.SpeechRecognitionResult{})
+		}
+		assembler.results = append(assembler.results[:index], results.Results...)
+	}
+
+	for _, label := range results.SpeakerLabels {
+		assembler.addLabel(label)
+	}
+}
+
+// AddJob : Merges the results of a recognition job returned by CheckJob or CheckJobs
+func (assembler *Assembler) AddJob(job *speechtotextv1.RecognitionJob) {
+	if job == nil {
+		return
+	}
+	for i := range job.Results {
+		assembler.Add(&job.Results[i])
+	}
+}
+
+// addLabel : Stores a speaker label, replacing an earlier label for the same word
+func (assembler *Assembler) addLabel(label speechtotextv1.SpeakerLabelsResult) {
+	if label.From == nil || label.To == nil || label.Speaker == nil {
+		return
+	}
+	for i, existing := range assembler.labels {
+		if math.Abs(float64(*existing.From-*label.From)) < labelTolerance {
+			assembler.labels[i] = label
+			return
+		}
+	}
+	assembler.labels = append(assembler.labels, label)
+}
+
+// Transcript : Returns the speaker turns of the results received so far
+func (assembler *Assembler) Transcript() *Transcript {
+	assembler.mutex.Lock()
+	defer assembler.mutex.Unlock()
+
+	transcript := &Transcript{Turns: []Turn{}}
+	var current *Turn
+	var confidenceSum float64
+	var confidenceCount int
+	flush := func() {
+		if current == nil {
+			return
+		}
+		if confidenceCount > 0 {
+			current.Confidence = confidenceSum / float64(confidenceCount)
+		}
+		transcript.Turns = append(transcript.Turns, *current)
+		current = nil
+		confidenceSum, confidenceCount = 0, 0
+	}
+
+	for _, result := range assembler.results {
+		final := result.Final != nil && *result.Final
+		if (!final && !assembler.options.IncludeInterim) || len(result.Alternatives) == 0 {
+			continue
+		}
+		alternative := result.Alternatives[0]
+		words := assembler.words(alternative)
+
+		if len(words) == 0 {
+			// Without timestamps the result can only be reported as a whole
+			flush()
+			text := ""
+			if alternative.Transcript != nil {
+				text = strings.TrimSpace(*alternative.Transcript)
+			}
+			turn := Turn{Speaker: UnknownSpeaker, Text: text, Final: final, Words: []Word{}}
+			if alternative.Confidence != nil {
+				turn.Confidence = *alternative.Confidence
+			}
+			transcript.Turns = append(transcript.Turns, turn)
+			continue
+		}
+
+		for _, word := range words {
+			if current == nil || current.Speaker != word.Speaker {
+				flush()
+				current = &Turn{Speaker: word.Speaker, Start: word.Start, Final: true}
+			}
+			current.End = word.End
+			current.Final = current.Final && final
+			current.Words = append(current.Words, word)
+			if current.Text == "" {
+				current.Text = word.Text
+			} else {
+				current.Text += " " + word.Text
+			}
+			switch {
+			case word.Confidence > 0:
+				confidenceSum += word.Confidence
+				confidenceCount++
+			case alternative.Confidence != nil:
+				confidenceSum += *alternative.Confidence
+				confidenceCount++
+			}
+		}
+	}
+	flush()
+	return transcript
+}
+
+// words : Decodes the timestamps and word confidences of an alternative and attributes each word to a speaker
+func (assembler *Assembler) words(alternative speechtotextv1.SpeechRecognitionAlternative) []Word {
+	words := make([]Word, 0, len(alternative.Timestamps))
+	for _, timestamp := range alternative.Timestamps {
+		tuple, ok := timestamp.([]interface{})
+		if !ok || len(tuple) < 3 {
+			continue
+		}
+		text, textOK := tuple[0].(string)
+		start, startOK := tuple[1].(float64)
+		end, endOK := tuple[2].(float64)
+		if !textOK || !startOK || !endOK {
+			continue
+		}
+		words = append(words, Word{Text: text, Start: start, End: end, Speaker: assembler.speakerAt(start, end)})
+	}
+
+	// Word confidences are listed in the same order as the timestamps
+	if len(alternative.WordConfidence) == len(words) {
+		for i, confidence := range alternative.WordConfidence {
+			tuple, ok := confidence.([]interface{})
+			if !ok || len(tuple) < 2 {
+				continue
+			}
+			if value, ok := tuple[1].(float64); ok {
+				words[i].Confidence = value
+			}
+		}
+	}
+	return words
+}
+
+// speakerAt : Finds the speaker of the word spanning start to end. The service labels each word with a label
+// starting at the word's start time; otherwise the label with the largest overlap wins.
+func (assembler *Assembler) speakerAt(start, end float64) int64 {
+	speaker := UnknownSpeaker
+	bestOverlap := 0.0
+	for _, label := range assembler.labels {
+		from, to := float64(*label.From), float64(*label.To)
+		if math.Abs(from-start) < labelTolerance {
+			return *label.Speaker
+		}
+		overlap := math.Min(to, end) - math.Max(from, start)
+		if overlap > bestOverlap {
+			bestOverlap = overlap
+			speaker = *label.Speaker
+		}
+	}
+	return speaker
+}
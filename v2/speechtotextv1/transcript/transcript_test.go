@@ -0,0 +1,139 @@
+/**
+ * (C) Copyright IBM Corp. 2021.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package transcript_test
+
+import (
+	"encoding/json"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/watson-developer-cloud/go-sdk/v2/speechtotextv1"
+	"github.com/watson-developer-cloud/go-sdk/v2/speechtotextv1/transcript"
+)
+
+func recognitionResults(body string) *speechtotextv1.SpeechRecognitionResults {
+	results := new(speechtotextv1.SpeechRecognitionResults)
+	Expect(json.Unmarshal([]byte(body), results)).To(Succeed())
+	return results
+}
+
+var _ = Describe(`Assembler`, func() {
+	It(`Replaces interim results with later results`, func() {
+		assembler := transcript.NewAssembler(&transcript.AssemblerOptions{IncludeInterim: true})
+		assembler.Add(recognitionResults(`{"result_index":0,"results":[{"final":false,"alternatives":[{"transcript":"hello","timestamps":[["hello",0.1,0.5]]}]}]}`))
+		Expect(assembler.Transcript().Text()).To(Equal("hello"))
+
+		assembler.Add(recognitionResults(`{"result_index":0,"results":[{"final":true,"alternatives":[{"transcript":"hello world","timestamps":[["hello",0.1,0.5],["world",0.6,1.0]]}]}]}`))
+		assembler.Add(recognitionResults(`{"result_index":1,"results":[{"final":false,"alternatives":[{"transcript":"how","timestamps":[["how",1.2,1.4]]}]}]}`))
+		assembler.Add(recognitionResults(`{"result_index":1,"results":[{"final":true,"alternatives":[{"transcript":"how are you","timestamps":[["how",1.2,1.4],["are",1.4,1.6],["you",1.6,1.9]]}]}]}`))
+
+		result := assembler.Transcript()
+		Expect(result.Turns).To(HaveLen(1))
+		Expect(result.Turns[0].Text).To(Equal("hello world how are you"))
+		Expect(result.Turns[0].Speaker).To(Equal(transcript.UnknownSpeaker))
+		Expect(result.Turns[0].Final).To(BeTrue())
+	})
+	It(`Drops results that a revision no longer has`, func() {
+		assembler := transcript.NewAssembler(&transcript.AssemblerOptions{IncludeInterim: true})
+		assembler.Add(recognitionResults(`{"result_index":0,"results":[
+			{"final":false,"alternatives":[{"transcript":"ice cream","timestamps":[["ice",0.1,0.4],["cream",0.4,0.8]]}]},
+			{"final":false,"alternatives":[{"transcript":"scream","timestamps":[["scream",0.9,1.3]]}]}
+		]}`))
+		Expect(assembler.Transcript().Text()).To(Equal("ice cream scream"))
+
+		assembler.Add(recognitionResults(`{"result_index":0,"results":[{"final":true,"alternatives":[{"transcript":"I scream","timestamps":[["I",0.1,0.4],["scream",0.4,1.3]]}]}]}`))
+		Expect(assembler.Transcript().Text()).To(Equal("I scream"))
+	})
+	It(`Fills in results that arrive after a gap`, func() {
+		assembler := transcript.NewAssembler(nil)
+		assembler.Add(recognitionResults(`{"result_index":2,"results":[{"final":true,"alternatives":[{"transcript":"you","timestamps":[["you",1.6,1.9]]}]}]}`))
+		Expect(assembler.Transcript().Text()).To(Equal("you"))
+
+		assembler.Add(recognitionResults(`{"result_index":0,"results":[
+			{"final":true,"alternatives":[{"transcript":"how","timestamps":[["how",1.2,1.4]]}]},
+			{"final":true,"alternatives":[{"transcript":"are","timestamps":[["are",1.4,1.6]]}]},
+			{"final":true,"alternatives":[{"transcript":"you","timestamps":[["you",1.6,1.9]]}]}
+		]}`))
+		Expect(assembler.Transcript().Text()).To(Equal("how are you"))
+	})
+	It(`Omits interim results by default`, func() {
+		assembler := transcript.NewAssembler(nil)
+		assembler.Add(recognitionResults(`{"result_index":0,"results":[{"final":true,"alternatives":[{"transcript":"hello","timestamps":[["hello",0.1,0.5]]}]}]}`))
+		assembler.Add(recognitionResults(`{"result_index":1,"results":[{"final":false,"alternatives":[{"transcript":"wor","timestamps":[["wor",0.6,0.8]]}]}]}`))
+		Expect(assembler.Transcript().Text()).To(Equal("hello"))
+	})
+	It(`Splits turns by speaker`, func() {
+		assembler := transcript.NewAssembler(nil)
+		assembler.Add(recognitionResults(`{
+			"result_index": 0,
+			"results": [{
+				"final": true,
+				"alternatives": [{
+					"transcript": "hello there hi",
+					"confidence": 0.9,
+					"timestamps": [["hello",0.1,0.5],["there",0.5,0.9],["hi",1.5,1.8]],
+					"word_confidence": [["hello",0.9],["there",0.8],["hi",0.7]]
+				}]
+			}]
+		}`))
+		assembler.Add(recognitionResults(`{"speaker_labels":[
+			{"from":0.1,"to":0.5,"speaker":0,"confidence":0.5,"final":false},
+			{"from":0.5,"to":0.9,"speaker":0,"confidence":0.5,"final":false},
+			{"from":1.5,"to":1.8,"speaker":0,"confidence":0.5,"final":false}
+		]}`))
+		// A later, final label revises the speaker of the last word
+		assembler.Add(recognitionResults(`{"speaker_labels":[{"from":1.5,"to":1.8,"speaker":1,"confidence":0.6,"final":true}]}`))
+
+		result := assembler.Transcript()
+		Expect(result.Turns).To(HaveLen(2))
+		Expect(result.Turns[0].Speaker).To(Equal(int64(0)))
+		Expect(result.Turns[0].Text).To(Equal("hello there"))
+		Expect(result.Turns[0].Start).To(Equal(0.1))
+		Expect(result.Turns[0].End).To(Equal(0.9))
+		Expect(result.Turns[0].Confidence).To(BeNumerically("~", 0.85, 1e-9))
+		Expect(result.Turns[1].Speaker).To(Equal(int64(1)))
+		Expect(result.Turns[1].Text).To(Equal("hi"))
+		Expect(result.Turns[1].Confidence).To(BeNumerically("~", 0.7, 1e-9))
+
+		encoded, err := result.JSON()
+		Expect(err).To(BeNil())
+		Expect(string(encoded)).To(ContainSubstring(`"speaker":1,"start":1.5,"end":1.8,"text":"hi"`))
+	})
+	It(`Assembles the results of a recognition job`, func() {
+		job := new(speechtotextv1.RecognitionJob)
+		Expect(json.Unmarshal([]byte(`{
+			"id": "4bd734c0-e575-21f3-de03-f932aa0468a0",
+			"status": "completed",
+			"created": "2016-08-17T19:15:17.926Z",
+			"results": [{
+				"result_index": 0,
+				"results": [
+					{"final":true,"alternatives":[{"transcript":"first sentence ","confidence":0.8}]},
+					{"final":true,"alternatives":[{"transcript":"second sentence ","confidence":0.6}]}
+				]
+			}]
+		}`), job)).To(Succeed())
+
+		assembler := transcript.NewAssembler(nil)
+		assembler.AddJob(job)
+		result := assembler.Transcript()
+		Expect(result.Turns).To(HaveLen(2))
+		Expect(result.Turns[0].Text).To(Equal("first sentence"))
+		Expect(result.Turns[0].Confidence).To(Equal(0.8))
+		Expect(result.Turns[1].Text).To(Equal("second sentence"))
+	})
+})
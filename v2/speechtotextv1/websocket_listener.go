@@ -1,12 +1,14 @@
 package speechtotextv1
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
 	"net/http"
 	"net/url"
+	"sync"
 	"time"
 
 	"github.com/IBM/go-sdk-core/v5/core"
@@ -16,6 +18,16 @@ import (
 type RecognizeListener struct {
 	IsClosed chan bool
 	Callback RecognizeCallbackWrapper
+
+	ctx   context.Context
+	done  chan struct{}
+	state *listenerState
+}
+
+// listenerState : Records the first error reported during a session
+type listenerState struct {
+	mutex sync.Mutex
+	err   error
 }
 
 /*
@@ -43,7 +55,9 @@ func (wsHandle RecognizeListener) OnData(conn *websocket.Conn, recognizeOptions
 		var websocketResponse WebsocketRecognitionResults
 		_, result, err := conn.ReadMessage()
 		if err != nil {
-			wsHandle.OnError(err)
+			if !wsHandle.cancelled() && !websocket.IsCloseError(err, websocket.CloseNormalClosure) {
+				wsHandle.OnError(err)
+			}
 			break
 		}
 		err = json.Unmarshal(result, &websocketResponse)
@@ -52,6 +66,8 @@ func (wsHandle RecognizeListener) OnData(conn *websocket.Conn, recognizeOptions
 			break
 		}
 
+		dispatchRecognizeMessage(wsHandle.Callback, &websocketResponse)
+
 		if websocketResponse.State == "listening" {
 			if !isListening {
 				isListening = true
@@ -72,6 +88,9 @@ func (wsHandle RecognizeListener) OnData(conn *websocket.Conn, recognizeOptions
 		wsHandle.Callback.OnData(&detailResp)
 	}
 	conn.Close()
+	if wsHandle.done != nil {
+		close(wsHandle.done)
+	}
 	wsHandle.IsClosed <- true
 }
 
@@ -79,9 +98,81 @@ func (wsHandle RecognizeListener) OnData(conn *websocket.Conn, recognizeOptions
 	OnError: Callback when error encountered
 */
 func (wsHandle RecognizeListener) OnError(err error) {
+	if wsHandle.state != nil {
+		wsHandle.state.mutex.Lock()
+		if wsHandle.state.err == nil {
+			wsHandle.state.err = err
+		}
+		wsHandle.state.mutex.Unlock()
+	}
 	wsHandle.Callback.OnError(err)
 }
 
+/*
+	cancelled : Reports whether the session context has been cancelled
+*/
+func (wsHandle RecognizeListener) cancelled() bool {
+	return wsHandle.ctx != nil && wsHandle.ctx.Err() != nil
+}
+
+/*
+	closed : Reports whether the receiving side of the session has finished
+*/
+func (wsHandle RecognizeListener) closed() bool {
+	if wsHandle.done == nil {
+		return false
+	}
+	select {
+	case <-wsHandle.done:
+		return true
+	default:
+		return false
+	}
+}
+
+/*
+	err : Returns the error that ended the session, if any
+*/
+func (wsHandle RecognizeListener) err() error {
+	if wsHandle.state != nil {
+		wsHandle.state.mutex.Lock()
+		defer wsHandle.state.mutex.Unlock()
+		if wsHandle.state.err != nil {
+			return wsHandle.state.err
+		}
+	}
+	if wsHandle.cancelled() {
+		return wsHandle.ctx.Err()
+	}
+	return nil
+}
+
+/*
+	dispatchRecognizeMessage : Routes a decoded message to the typed methods of callback, if it implements RecognizeResultsCallback
+*/
+func dispatchRecognizeMessage(callback RecognizeCallbackWrapper, message *WebsocketRecognitionResults) {
+	resultsCallback, ok := callback.(RecognizeResultsCallback)
+	if !ok {
+		return
+	}
+	if message.State != "" {
+		resultsCallback.OnStateChange(message.State)
+	}
+	if len(message.Results) > 0 || len(message.Warnings) > 0 {
+		results := message.SpeechRecognitionResults
+		resultsCallback.OnResults(&results)
+	}
+	if len(message.SpeakerLabels) > 0 {
+		resultsCallback.OnSpeakerLabels(message.SpeakerLabels)
+	}
+	if message.ProcessingMetrics != nil {
+		resultsCallback.OnProcessingMetrics(message.ProcessingMetrics)
+	}
+	if message.AudioMetrics != nil {
+		resultsCallback.OnAudioMetrics(message.AudioMetrics)
+	}
+}
+
 /*
 	sendStartMessage : Sends start message to server
 */
@@ -105,39 +196,108 @@ func sendCloseMessage(conn *websocket.Conn) {
 }
 
 /*
-	sendAudio : Sends audio data to the server
+	sendAudio : Sends audio data to the server until the audio is exhausted, the context
+	is cancelled or the connection is closed. Each chunk is written before the next one
+	is read, so a blocked socket holds back the audio source.
 */
-func sendAudio(conn *websocket.Conn, recognizeOptions *RecognizeUsingWebsocketOptions, recognizeListener *RecognizeListener) {
-	chunk := make([]byte, ONE_KB*2)
+func sendAudio(conn *websocket.Conn, recognizeOptions *RecognizeUsingWebsocketOptions, recognizeListener *RecognizeListener, pacer *audioPacer) {
+	chunk := make([]byte, pacer.chunkSize)
 	for {
+		if recognizeListener.cancelled() || recognizeListener.closed() {
+			return
+		}
 		bytesRead, err := (recognizeOptions.Audio).Read(chunk)
+		if bytesRead > 0 {
+			writeErr := conn.WriteMessage(websocket.BinaryMessage, chunk[:bytesRead])
+			if writeErr != nil {
+				if !recognizeListener.cancelled() && !recognizeListener.closed() {
+					recognizeListener.OnError(writeErr)
+				}
+				return
+			}
+		}
 		if err != nil {
-			if err == io.EOF {
-				break
-			} else {
+			if err != io.EOF {
 				recognizeListener.OnError(err)
+				return
 			}
+			break
 		}
-		err = conn.WriteMessage(websocket.BinaryMessage, chunk[:bytesRead])
-		if err != nil {
-			recognizeListener.OnError(err)
+		if wait := pacer.wait(bytesRead); wait > 0 {
+			select {
+			case <-recognizeListener.ctx.Done():
+				return
+			case <-recognizeListener.done:
+				return
+			case <-time.After(wait):
+			}
 		}
-		time.Sleep(TEN_MILLISECONDS)
 	}
 	sendCloseMessage(conn)
 }
 
 /*
-	NewRecognizeListener : Instantiates a listener instance to control the sending/receiving of audio/text
+	NewRecognizeListener : Instantiates a listener instance to control the sending/receiving of audio/text. Errors,
+	including a failure to dial, are passed to the callback.
 */
 func (speechToText *SpeechToTextV1) NewRecognizeListener(callback RecognizeCallbackWrapper, recognizeWSOptions *RecognizeUsingWebsocketOptions, dialURL string, param url.Values, headers http.Header) {
-	recognizeListener := RecognizeListener{Callback: callback, IsClosed: make(chan bool, 1)}
+	pacer, err := newAudioPacer(recognizeWSOptions)
+	if err != nil {
+		callback.OnError(err)
+		return
+	}
+
 	conn, _, err := websocket.DefaultDialer.Dial(fmt.Sprintf("%s%s?%s", dialURL, RECOGNIZE_ENDPOINT, param.Encode()), headers)
 	if err != nil {
-		recognizeListener.OnError(err)
+		callback.OnError(err)
+		return
+	}
+	runRecognizeListener(context.Background(), conn, callback, recognizeWSOptions, pacer)
+}
+
+/*
+	NewRecognizeListenerWithContext : Instantiates a listener instance bound to ctx and runs the session until
+	the connection is closed. A dial failure is returned without invoking the callback.
+*/
+func (speechToText *SpeechToTextV1) NewRecognizeListenerWithContext(ctx context.Context, callback RecognizeCallbackWrapper, recognizeWSOptions *RecognizeUsingWebsocketOptions, dialURL string, param url.Values, headers http.Header) error {
+	pacer, err := newAudioPacer(recognizeWSOptions)
+	if err != nil {
+		return err
+	}
+
+	conn, _, err := websocket.DefaultDialer.DialContext(ctx, fmt.Sprintf("%s%s?%s", dialURL, RECOGNIZE_ENDPOINT, param.Encode()), headers)
+	if err != nil {
+		return err
 	}
+	return runRecognizeListener(ctx, conn, callback, recognizeWSOptions, pacer)
+}
+
+/*
+	runRecognizeListener : Runs a session over an open connection until it is closed and returns the first error
+	passed to the callback, or the context error
+*/
+func runRecognizeListener(ctx context.Context, conn *websocket.Conn, callback RecognizeCallbackWrapper, recognizeWSOptions *RecognizeUsingWebsocketOptions, pacer *audioPacer) error {
+	recognizeListener := RecognizeListener{
+		Callback: callback,
+		IsClosed: make(chan bool, 1),
+		ctx:      ctx,
+		done:     make(chan struct{}),
+		state:    &listenerState{},
+	}
+
+	// Close the connection if the context is cancelled before the session ends,
+	// which unblocks the reader and lets the session wind down.
+	go func() {
+		select {
+		case <-ctx.Done():
+			conn.Close()
+		case <-recognizeListener.done:
+		}
+	}()
+
 	recognizeListener.OnOpen(recognizeWSOptions, conn)
 	go recognizeListener.OnData(conn, recognizeWSOptions)
-	go sendAudio(conn, recognizeWSOptions, &recognizeListener)
+	sendAudio(conn, recognizeWSOptions, &recognizeListener, pacer)
 	recognizeListener.OnClose()
+	return recognizeListener.err()
 }
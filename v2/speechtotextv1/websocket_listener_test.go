@@ -0,0 +1,443 @@
+/**
+ * (C) Copyright IBM Corp. 2021.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package speechtotextv1_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"time"
+
+	"github.com/IBM/go-sdk-core/v5/core"
+	"github.com/gorilla/websocket"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/watson-developer-cloud/go-sdk/v2/speechtotextv1"
+)
+
+// recordingCallback : Records every callback invocation in order
+type recordingCallback struct {
+	mutex  sync.Mutex
+	events []string
+	data   [][]byte
+	errors []error
+}
+
+func (cb *recordingCallback) record(event string) {
+	cb.mutex.Lock()
+	defer cb.mutex.Unlock()
+	cb.events = append(cb.events, event)
+}
+
+func (cb *recordingCallback) OnOpen()  { cb.record("open") }
+func (cb *recordingCallback) OnClose() { cb.record("close") }
+func (cb *recordingCallback) OnData(resp *core.DetailedResponse) {
+	cb.mutex.Lock()
+	cb.data = append(cb.data, resp.GetResult().([]byte))
+	cb.mutex.Unlock()
+	cb.record("data")
+}
+func (cb *recordingCallback) OnError(err error) {
+	cb.mutex.Lock()
+	cb.errors = append(cb.errors, err)
+	cb.mutex.Unlock()
+	cb.record("error")
+}
+
+func (cb *recordingCallback) Events() []string {
+	cb.mutex.Lock()
+	defer cb.mutex.Unlock()
+	return append([]string(nil), cb.events...)
+}
+
+// typedCallback : Records the typed callbacks of RecognizeResultsCallback
+type typedCallback struct {
+	recordingCallback
+	results           []*speechtotextv1.SpeechRecognitionResults
+	speakerLabels     []speechtotextv1.SpeakerLabelsResult
+	processingMetrics *speechtotextv1.ProcessingMetrics
+	audioMetrics      *speechtotextv1.AudioMetrics
+	states            []string
+}
+
+func (cb *typedCallback) OnResults(results *speechtotextv1.SpeechRecognitionResults) {
+	cb.results = append(cb.results, results)
+}
+func (cb *typedCallback) OnSpeakerLabels(labels []speechtotextv1.SpeakerLabelsResult) {
+	cb.speakerLabels = append(cb.speakerLabels, labels...)
+}
+func (cb *typedCallback) OnProcessingMetrics(metrics *speechtotextv1.ProcessingMetrics) {
+	cb.processingMetrics = metrics
+}
+func (cb *typedCallback) OnAudioMetrics(metrics *speechtotextv1.AudioMetrics) {
+	cb.audioMetrics = metrics
+}
+func (cb *typedCallback) OnStateChange(state string) {
+	cb.states = append(cb.states, state)
+}
+
+// recognizeStandIn : A minimal stand-in for the /v1/recognize websocket endpoint
+type recognizeStandIn struct {
+	mutex    sync.Mutex
+	audio    bytes.Buffer
+	actions  []string
+	results  []string
+	hangOpen bool
+}
+
+func (s *recognizeStandIn) handler() http.HandlerFunc {
+	upgrader := websocket.Upgrader{}
+	return func(w http.ResponseWriter, r *http.Request) {
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		for {
+			messageType, message, err := conn.ReadMessage()
+			if err != nil {
+				return
+			}
+			if messageType == websocket.BinaryMessage {
+				s.mutex.Lock()
+				s.audio.Write(message)
+				s.mutex.Unlock()
+				continue
+			}
+			var msg map[string]interface{}
+			_ = json.Unmarshal(message, &msg)
+			action, _ := msg["action"].(string)
+			s.mutex.Lock()
+			s.actions = append(s.actions, action)
+			s.mutex.Unlock()
+			switch action {
+			case "start":
+				_ = conn.WriteMessage(websocket.TextMessage, []byte(`{"state":"listening"}`))
+			case "stop":
+				if s.hangOpen {
+					continue
+				}
+				for _, result := range s.results {
+					_ = conn.WriteMessage(websocket.TextMessage, []byte(result))
+				}
+				_ = conn.WriteMessage(websocket.TextMessage, []byte(`{"state":"listening"}`))
+			}
+		}
+	}
+}
+
+func (s *recognizeStandIn) Actions() []string {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return append([]string(nil), s.actions...)
+}
+
+// blockingReader : An audio source that never produces data until closed
+type blockingReader struct {
+	closed chan struct{}
+}
+
+func (r *blockingReader) Read(p []byte) (int, error) {
+	select {
+	case <-r.closed:
+		return 0, io.EOF
+	case <-time.After(5 * time.Millisecond):
+		return 0, nil
+	}
+}
+
+func (r *blockingReader) Close() error { return nil }
+
+var _ = Describe(`SpeechToTextV1 websocket recognition`, func() {
+	var testServer *httptest.Server
+	var standIn *recognizeStandIn
+	var speechToTextService *speechtotextv1.SpeechToTextV1
+
+	BeforeEach(func() {
+		standIn = &recognizeStandIn{
+			results: []string{`{"result_index":0,"results":[{"final":true,"alternatives":[{"transcript":"hello world"}]}]}`},
+		}
+		testServer = httptest.NewServer(standIn.handler())
+		var serviceErr error
+		speechToTextService, serviceErr = speechtotextv1.NewSpeechToTextV1(&speechtotextv1.SpeechToTextV1Options{
+			URL:           testServer.URL,
+			Authenticator: &core.NoAuthAuthenticator{},
+		})
+		Expect(serviceErr).To(BeNil())
+	})
+	AfterEach(func() {
+		testServer.Close()
+	})
+
+	It(`Invoke RecognizeUsingWebsocketWithContext with a complete session`, func() {
+		callback := &recordingCallback{}
+		audio := ioutil.NopCloser(bytes.NewReader(bytes.Repeat([]byte{1}, 5000)))
+		options := speechToTextService.NewRecognizeUsingWebsocketOptions(audio, "audio/l16;rate=16000")
+
+		err := speechToTextService.RecognizeUsingWebsocketWithContext(context.Background(), options, callback)
+		Expect(err).To(BeNil())
+		Expect(callback.Events()).To(Equal([]string{"open", "data", "close"}))
+		Expect(string(callback.data[0])).To(ContainSubstring("hello world"))
+		Expect(standIn.audio.Len()).To(Equal(5000))
+		Expect(standIn.Actions()).To(Equal([]string{"start", "stop"}))
+	})
+	It(`Invoke RecognizeUsingWebsocketWithContext with a typed callback`, func() {
+		standIn.results = []string{
+			`{"result_index":0,"results":[{"final":true,"alternatives":[{"transcript":"hello world","timestamps":[["hello",0.1,0.5],["world",0.6,1.0]]}]}]}`,
+			`{"speaker_labels":[{"from":0.1,"to":0.5,"speaker":1,"confidence":0.9,"final":true}]}`,
+			`{"processing_metrics":{"processed_audio":{"received":1.0,"seen_by_engine":1.0,"transcription":1.0,"speaker_labels":1.0},"wall_clock_since_first_byte_received":0.5,"periodic":false}}`,
+			`{"audio_metrics":{"sampling_interval":0.1,"accumulated":{"final":true,"end_time":1.0,"signal_to_noise_ratio":20.5,"speech_ratio":0.8,"high_frequency_loss":0,"direct_current_offset":[],"clipping_rate":[],"speech_level":[],"non_speech_level":[]}}}`,
+		}
+		callback := &typedCallback{}
+		audio := ioutil.NopCloser(bytes.NewReader([]byte{1, 2, 3}))
+		options := speechToTextService.NewRecognizeUsingWebsocketOptions(audio, "audio/l16;rate=16000")
+
+		err := speechToTextService.RecognizeUsingWebsocketWithContext(context.Background(), options, callback)
+		Expect(err).To(BeNil())
+		Expect(callback.Events()).To(Equal([]string{"open", "data", "data", "data", "data", "close"}))
+		Expect(callback.states).To(Equal([]string{"listening", "listening"}))
+		Expect(callback.results).To(HaveLen(1))
+		Expect(*callback.results[0].Results[0].Alternatives[0].Transcript).To(Equal("hello world"))
+		Expect(callback.results[0].Results[0].Alternatives[0].Timestamps).To(HaveLen(2))
+		Expect(callback.speakerLabels).To(HaveLen(1))
+		Expect(*callback.speakerLabels[0].Speaker).To(Equal(int64(1)))
+		Expect(callback.processingMetrics).ToNot(BeNil())
+		Expect(*callback.processingMetrics.WallClockSinceFirstByteReceived).To(Equal(float32(0.5)))
+		Expect(callback.audioMetrics).ToNot(BeNil())
+		Expect(*callback.audioMetrics.Accumulated.SignalToNoiseRatio).To(Equal(float32(20.5)))
+	})
+	It(`Invoke RecognizeUsingWebsocketWithContext with real-time pacing`, func() {
+		callback := &recordingCallback{}
+		audio := ioutil.NopCloser(bytes.NewReader(make([]byte, 4000)))
+		options := speechToTextService.NewRecognizeUsingWebsocketOptions(audio, "audio/l16; rate=8000; channels=1")
+		options.SetPacing(speechtotextv1.RecognizeUsingWebsocketOptionsPacingRealTimeConst).SetChunkSize(1000)
+
+		start := time.Now()
+		err := speechToTextService.RecognizeUsingWebsocketWithContext(context.Background(), options, callback)
+		Expect(err).To(BeNil())
+		Expect(time.Since(start)).To(BeNumerically(">=", 200*time.Millisecond))
+		Expect(standIn.audio.Len()).To(Equal(4000))
+	})
+	It(`Invoke RecognizeUsingWebsocketWithContext with a custom byte rate`, func() {
+		callback := &recordingCallback{}
+		audio := ioutil.NopCloser(bytes.NewReader(make([]byte, 3000)))
+		options := speechToTextService.NewRecognizeUsingWebsocketOptions(audio, "audio/mp3")
+		options.SetPacing(speechtotextv1.RecognizeUsingWebsocketOptionsPacingBytesPerSecondConst).SetBytesPerSecond(10000).SetChunkSize(500)
+
+		start := time.Now()
+		err := speechToTextService.RecognizeUsingWebsocketWithContext(context.Background(), options, callback)
+		Expect(err).To(BeNil())
+		Expect(time.Since(start)).To(BeNumerically(">=", 250*time.Millisecond))
+		Expect(standIn.audio.Len()).To(Equal(3000))
+	})
+	It(`Invoke RecognizeUsingWebsocketWithContext without pacing`, func() {
+		callback := &recordingCallback{}
+		audio := ioutil.NopCloser(bytes.NewReader(make([]byte, 100*1024)))
+		options := speechToTextService.NewRecognizeUsingWebsocketOptions(audio, "audio/mp3")
+		options.SetPacing(speechtotextv1.RecognizeUsingWebsocketOptionsPacingNoneConst)
+
+		start := time.Now()
+		err := speechToTextService.RecognizeUsingWebsocketWithContext(context.Background(), options, callback)
+		Expect(err).To(BeNil())
+		Expect(time.Since(start)).To(BeNumerically("<", 500*time.Millisecond))
+		Expect(standIn.audio.Len()).To(Equal(100 * 1024))
+	})
+	It(`Invoke RecognizeUsingWebsocketWithContext with invalid pacing`, func() {
+		callback := &recordingCallback{}
+		audio := ioutil.NopCloser(bytes.NewReader([]byte{1}))
+		options := speechToTextService.NewRecognizeUsingWebsocketOptions(audio, "audio/mp3")
+
+		options.SetPacing(speechtotextv1.RecognizeUsingWebsocketOptionsPacingRealTimeConst)
+		Expect(speechToTextService.RecognizeUsingWebsocketWithContext(context.Background(), options, callback)).ToNot(BeNil())
+		options.SetContentType("audio/wav")
+		Expect(speechToTextService.RecognizeUsingWebsocketWithContext(context.Background(), options, callback)).To(MatchError(ContainSubstring("not supported")))
+
+		options.SetPacing(speechtotextv1.RecognizeUsingWebsocketOptionsPacingBytesPerSecondConst)
+		Expect(speechToTextService.RecognizeUsingWebsocketWithContext(context.Background(), options, callback)).ToNot(BeNil())
+
+		options.SetPacing(speechtotextv1.RecognizeUsingWebsocketOptionsPacingNoneConst).SetChunkSize(0)
+		Expect(speechToTextService.RecognizeUsingWebsocketWithContext(context.Background(), options, callback)).ToNot(BeNil())
+		Expect(callback.Events()).To(BeEmpty())
+		Expect(standIn.Actions()).To(BeEmpty())
+	})
+	It(`Invoke RecognizeUsingWebsocketWithContext and detect the content type`, func() {
+		callback := &recordingCallback{}
+		audio := ioutil.NopCloser(bytes.NewReader([]byte("RIFF\x24\x00\x00\x00WAVEfmt ")))
+		options := speechToTextService.NewRecognizeUsingWebsocketOptions(audio, "")
+
+		err := speechToTextService.RecognizeUsingWebsocketWithContext(context.Background(), options, callback)
+		Expect(err).To(BeNil())
+		Expect(*options.ContentType).To(Equal("audio/wav"))
+		Expect(standIn.audio.Len()).To(Equal(16))
+
+		audio = ioutil.NopCloser(bytes.NewReader([]byte{0, 1, 2, 3}))
+		options = speechToTextService.NewRecognizeUsingWebsocketOptions(audio, "")
+		err = speechToTextService.RecognizeUsingWebsocketWithContext(context.Background(), options, callback)
+		Expect(err).To(Equal(speechtotextv1.ErrUnknownAudioFormat))
+	})
+	It(`Invoke RecognizeUsingWebsocketWithContext with nil options`, func() {
+		callback := &recordingCallback{}
+		err := speechToTextService.RecognizeUsingWebsocketWithContext(context.Background(), nil, callback)
+		Expect(err).ToNot(BeNil())
+		Expect(callback.Events()).To(BeEmpty())
+	})
+	It(`Invoke RecognizeUsingWebsocketWithContext with invalid options`, func() {
+		callback := &recordingCallback{}
+		options := &speechtotextv1.RecognizeUsingWebsocketOptions{}
+		err := speechToTextService.RecognizeUsingWebsocketWithContext(context.Background(), options, callback)
+		Expect(err).ToNot(BeNil())
+		Expect(callback.Events()).To(BeEmpty())
+	})
+	It(`Invoke RecognizeUsingWebsocketWithContext with a failing dial`, func() {
+		testServer.Close()
+		callback := &recordingCallback{}
+		audio := ioutil.NopCloser(bytes.NewReader([]byte{1, 2, 3}))
+		options := speechToTextService.NewRecognizeUsingWebsocketOptions(audio, "audio/l16;rate=16000")
+
+		err := speechToTextService.RecognizeUsingWebsocketWithContext(context.Background(), options, callback)
+		Expect(err).ToNot(BeNil())
+		Expect(callback.Events()).To(BeEmpty())
+	})
+	It(`Invoke RecognizeUsingWebsocket with a complete session`, func() {
+		callback := &recordingCallback{}
+		audio := ioutil.NopCloser(bytes.NewReader(bytes.Repeat([]byte{1}, 3000)))
+		options := speechToTextService.NewRecognizeUsingWebsocketOptions(audio, "audio/l16;rate=16000")
+
+		speechToTextService.RecognizeUsingWebsocket(options, callback)
+		Expect(callback.Events()).To(Equal([]string{"open", "data", "close"}))
+		Expect(standIn.audio.Len()).To(Equal(3000))
+	})
+	It(`Invoke RecognizeUsingWebsocket with nil options`, func() {
+		Expect(func() {
+			speechToTextService.RecognizeUsingWebsocket(nil, &recordingCallback{})
+		}).To(Panic())
+	})
+	It(`Invoke RecognizeUsingWebsocket with a failing dial`, func() {
+		testServer.Close()
+		callback := &recordingCallback{}
+		audio := ioutil.NopCloser(bytes.NewReader([]byte{1, 2, 3}))
+		options := speechToTextService.NewRecognizeUsingWebsocketOptions(audio, "audio/l16;rate=16000")
+
+		speechToTextService.RecognizeUsingWebsocket(options, callback)
+		Expect(callback.Events()).To(Equal([]string{"error"}))
+	})
+	It(`Invoke RecognizeUsingWebsocketWithContext and cancel the context`, func() {
+		standIn.hangOpen = true
+		callback := &recordingCallback{}
+		audio := &blockingReader{closed: make(chan struct{})}
+		defer close(audio.closed)
+		options := speechToTextService.NewRecognizeUsingWebsocketOptions(audio, "audio/l16;rate=16000")
+
+		ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+		defer cancel()
+		err := speechToTextService.RecognizeUsingWebsocketWithContext(ctx, options, callback)
+		Expect(err).To(Equal(context.DeadlineExceeded))
+		Expect(callback.Events()).To(Equal([]string{"open", "close"}))
+	})
+	It(`Invoke RecognizeUsingWebsocketWithContext and receive a service error`, func() {
+		standIn.results = []string{`{"error":"unable to transcode data stream"}`}
+		callback := &recordingCallback{}
+		audio := ioutil.NopCloser(bytes.NewReader([]byte{1, 2, 3}))
+		options := speechToTextService.NewRecognizeUsingWebsocketOptions(audio, "audio/mp3")
+
+		err := speechToTextService.RecognizeUsingWebsocketWithContext(context.Background(), options, callback)
+		Expect(err).ToNot(BeNil())
+		Expect(err.Error()).To(Equal("unable to transcode data stream"))
+		Expect(callback.Events()).To(Equal([]string{"open", "error", "close"}))
+	})
+})
+
+var _ = Describe(`SpeechToTextV1 recognize session`, func() {
+	var testServer *httptest.Server
+	var standIn *recognizeStandIn
+	var speechToTextService *speechtotextv1.SpeechToTextV1
+
+	BeforeEach(func() {
+		standIn = &recognizeStandIn{
+			results: []string{`{"result_index":0,"results":[{"final":true,"alternatives":[{"transcript":"hello world"}]}]}`},
+		}
+		testServer = httptest.NewServer(standIn.handler())
+		var serviceErr error
+		speechToTextService, serviceErr = speechtotextv1.NewSpeechToTextV1(&speechtotextv1.SpeechToTextV1Options{
+			URL:           testServer.URL,
+			Authenticator: &core.NoAuthAuthenticator{},
+		})
+		Expect(serviceErr).To(BeNil())
+	})
+	AfterEach(func() {
+		testServer.Close()
+	})
+
+	It(`Invoke NewRecognizeSessionWithContext with several utterances`, func() {
+		callback := &recordingCallback{}
+		options := speechToTextService.NewRecognizeUsingWebsocketOptions(nil, "audio/l16;rate=8000")
+
+		session, err := speechToTextService.NewRecognizeSessionWithContext(context.Background(), options, callback)
+		Expect(err).To(BeNil())
+		Expect(session).ToNot(BeNil())
+
+		n, err := session.Write([]byte{1, 2, 3, 4})
+		Expect(err).To(BeNil())
+		Expect(n).To(Equal(4))
+		Expect(session.Stop()).To(BeNil())
+		Expect(callback.Events()).To(Equal([]string{"open", "data"}))
+
+		_, err = session.Write([]byte{1})
+		Expect(err).To(Equal(speechtotextv1.ErrSessionNotStarted))
+
+		Expect(session.Start(options)).To(BeNil())
+		Expect(session.Start(options)).To(Equal(speechtotextv1.ErrSessionStarted))
+		_, err = session.Write([]byte{5, 6})
+		Expect(err).To(BeNil())
+		Expect(session.Stop()).To(BeNil())
+
+		Expect(session.Close()).To(BeNil())
+		Expect(session.Err()).To(BeNil())
+		Expect(callback.Events()).To(Equal([]string{"open", "data", "data", "close"}))
+		Expect(standIn.Actions()).To(Equal([]string{"start", "stop", "start", "stop"}))
+		Expect(standIn.audio.Len()).To(Equal(6))
+	})
+	It(`Invoke NewRecognizeSessionWithContext and cancel the context`, func() {
+		callback := &recordingCallback{}
+		options := speechToTextService.NewRecognizeUsingWebsocketOptions(nil, "audio/l16;rate=8000")
+
+		ctx, cancel := context.WithCancel(context.Background())
+		session, err := speechToTextService.NewRecognizeSessionWithContext(ctx, options, callback)
+		Expect(err).To(BeNil())
+		cancel()
+		Eventually(session.Done()).Should(BeClosed())
+		Expect(session.Err()).To(Equal(context.Canceled))
+		_, err = session.Write([]byte{1})
+		Expect(err).To(Equal(context.Canceled))
+		Expect(callback.Events()).To(Equal([]string{"open", "close"}))
+	})
+	It(`Invoke NewRecognizeSessionWithContext with a failing dial`, func() {
+		testServer.Close()
+		callback := &recordingCallback{}
+		options := speechToTextService.NewRecognizeUsingWebsocketOptions(nil, "audio/l16;rate=8000")
+
+		session, err := speechToTextService.NewRecognizeSessionWithContext(context.Background(), options, callback)
+		Expect(err).ToNot(BeNil())
+		Expect(session).To(BeNil())
+		Expect(callback.Events()).To(BeEmpty())
+	})
+})
@@ -0,0 +1,345 @@
+/**
+ * (C) Copyright IBM Corp. 2021.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package texttospeechv1
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+)
+
+// maxWAVHeaderLength is the most header bytes an AudioSink reads while looking for the `data` chunk
+const maxWAVHeaderLength = 64 * 1024
+
+// ErrAudioSinkClosed is returned when audio is written to an AudioSink after Close
+var ErrAudioSinkClosed = errors.New("the audio sink is closed")
+
+// AudioSink : Writes synthesized audio to a writer, fixing up the header of WAV audio. The service streams WAV audio
+// with placeholder sizes in its RIFF header; the sink patches the RIFF and `data` chunk sizes when it is closed. When
+// the writer is an io.WriteSeeker the audio is written through and the header is patched in place; otherwise WAV
+// audio is buffered in memory until Close. Audio in other formats, such as Ogg or MP3, is passed through unchanged.
+//
+// The sink accepts the io.ReadCloser returned by Synthesize through ReadFrom, or the chunks passed to OnAudioStream
+// through Write. Several WAV segments of the same format can be joined into one file by calling StartSegment before
+// each segment after the first; the headers of the later segments are dropped.
+type AudioSink struct {
+	writer io.Writer
+	seeker io.WriteSeeker
+
+	// start is the offset of the audio in a seekable writer
+	start int64
+
+	// header holds the bytes of the current segment until its format is known
+	header     []byte
+	headerDone bool
+	segment    int
+
+	wav        bool
+	format     []byte
+	dataOffset int64
+	dataSize   int64
+
+	// buffered holds WAV audio for a writer that cannot seek
+	buffered bytes.Buffer
+
+	closed bool
+	err    error
+}
+
+// NewAudioSink : Instantiate an AudioSink that writes to writer
+func NewAudioSink(writer io.Writer) *AudioSink {
+	sink := &AudioSink{writer: writer}
+	if seeker, ok := writer.(io.WriteSeeker); ok {
+		sink.seeker = seeker
+	}
+	return sink
+}
+
+// StartSegment : Marks the start of another synthesized segment that is appended to the audio written so far. For
+// WAV audio the segment must have the same format as the first one, and only its samples are kept.
+func (sink *AudioSink) StartSegment() error {
+	if sink.err != nil {
+		return sink.err
+	}
+	if sink.closed {
+		return ErrAudioSinkClosed
+	}
+	if !sink.headerDone {
+		if len(sink.header) == 0 {
+			// Nothing of the current segment has been written, so the next one takes its place
+			return nil
+		}
+		return sink.fail(fmt.Errorf("segment %d ended before its header was complete", sink.segment+1))
+	}
+	sink.segment++
+	sink.headerDone = !sink.wav
+	return nil
+}
+
+// Write : Writes a chunk of audio, as passed to OnAudioStream
+func (sink *AudioSink) Write(p []byte) (int, error) {
+	if sink.err != nil {
+		return 0, sink.err
+	}
+	if sink.closed {
+		return 0, ErrAudioSinkClosed
+	}
+	if sink.headerDone {
+		if err := sink.writeAudio(p); err != nil {
+			return 0, sink.fail(err)
+		}
+		return len(p), nil
+	}
+
+	sink.header = append(sink.header, p...)
+	if err := sink.parseHeader(false); err != nil {
+		return 0, sink.fail(err)
+	}
+	return len(p), nil
+}
+
+// ReadFrom : Writes all of the audio read from reader, such as the body returned by Synthesize. The reader is not
+// closed.
+func (sink *AudioSink) ReadFrom(reader io.Reader) (n int64, err error) {
+	chunk := make([]byte, 32*1024)
+	for {
+		read, readErr := reader.Read(chunk)
+		if read > 0 {
+			if _, err = sink.Write(chunk[:read]); err != nil {
+				return
+			}
+			n += int64(read)
+		}
+		if readErr == io.EOF {
+			return n, nil
+		}
+		if readErr != nil {
+			return n, readErr
+		}
+	}
+}
+
+// Close : Completes the audio, patching the header of WAV audio and writing out any buffered audio. The underlying
+// writer is not closed.
+func (sink *AudioSink) Close() error {
+	if sink.err != nil {
+		return sink.err
+	}
+	if sink.closed {
+		return nil
+	}
+	sink.closed = true
+
+	if !sink.headerDone && (sink.segment == 0 || len(sink.header) > 0) {
+		if err := sink.parseHeader(true); err != nil {
+			return sink.fail(err)
+		}
+	}
+	if !sink.wav {
+		return nil
+	}
+
+	if sink.dataSize%2 == 1 {
+		// RIFF chunks are padded to an even length
+		if err := sink.output([]byte{0}); err != nil {
+			return sink.fail(err)
+		}
+	}
+	riffSize := sink.dataOffset + 8 + sink.dataSize + sink.dataSize%2 - 8
+	if riffSize > math.MaxUint32 {
+		return sink.fail(fmt.Errorf("the WAV audio is larger than 4 GB"))
+	}
+	var riffSizeBytes, dataSizeBytes [4]byte
+	binary.LittleEndian.PutUint32(riffSizeBytes[:], uint32(riffSize))
+	binary.LittleEndian.PutUint32(dataSizeBytes[:], uint32(sink.dataSize))
+
+	if sink.seeker == nil {
+		audio := sink.buffered.Bytes()
+		copy(audio[4:8], riffSizeBytes[:])
+		copy(audio[sink.dataOffset+4:sink.dataOffset+8], dataSizeBytes[:])
+		if _, err := sink.writer.Write(audio); err != nil {
+			return sink.fail(err)
+		}
+		sink.buffered.Reset()
+		return nil
+	}
+
+	end, err := sink.seeker.Seek(0, io.SeekCurrent)
+	if err == nil {
+		err = sink.writeAt(sink.start+4, riffSizeBytes[:])
+	}
+	if err == nil {
+		err = sink.writeAt(sink.start+sink.dataOffset+4, dataSizeBytes[:])
+	}
+	if err == nil {
+		_, err = sink.seeker.Seek(end, io.SeekStart)
+	}
+	if err != nil {
+		return sink.fail(err)
+	}
+	return nil
+}
+
+// parseHeader : Works out the format of the current segment from the bytes held in header. Until the header is
+// complete nothing is written, unless final is set, in which case the bytes are written as they are.
+func (sink *AudioSink) parseHeader(final bool) error {
+	header := sink.header
+	if sink.segment == 0 && !isWAVPrefix(header) {
+		// Not WAV audio, so there is nothing to patch
+		sink.headerDone = true
+		sink.header = nil
+		return sink.writeAudio(header)
+	}
+	if !isWAVPrefix(header) {
+		return fmt.Errorf("segment %d is not WAV audio", sink.segment+1)
+	}
+
+	format, dataOffset, complete, err := parseWAVHeader(header)
+	if err == nil && !complete && len(header) > maxWAVHeaderLength {
+		err = fmt.Errorf("no data chunk in the first %d bytes of the WAV audio", maxWAVHeaderLength)
+	}
+	if err == nil && !complete && final {
+		if sink.segment == 0 {
+			// Too short to be WAV audio; keep it as it is
+			sink.headerDone = true
+			sink.header = nil
+			return sink.writeAudio(header)
+		}
+		err = fmt.Errorf("segment %d ended before its header was complete", sink.segment+1)
+	}
+	if err != nil || !complete {
+		return err
+	}
+
+	sink.headerDone = true
+	sink.header = nil
+	samples := header[dataOffset+8:]
+	if sink.segment == 0 {
+		sink.wav = true
+		sink.format = format
+		sink.dataOffset = dataOffset
+		if sink.seeker != nil {
+			if sink.start, err = sink.seeker.Seek(0, io.SeekCurrent); err != nil {
+				return err
+			}
+		}
+		if err = sink.output(header[:dataOffset+8]); err != nil {
+			return err
+		}
+	} else if !bytes.Equal(format, sink.format) {
+		return fmt.Errorf("segment %d has a different WAV format from the first segment", sink.segment+1)
+	}
+	return sink.writeAudio(samples)
+}
+
+// writeAudio : Writes bytes that follow the header of the current segment
+func (sink *AudioSink) writeAudio(p []byte) error {
+	if len(p) == 0 {
+		return nil
+	}
+	if sink.wav {
+		sink.dataSize += int64(len(p))
+	}
+	return sink.output(p)
+}
+
+// output : Writes to the writer, or to the buffer when WAV audio is written to a writer that cannot seek
+func (sink *AudioSink) output(p []byte) error {
+	if sink.wav && sink.seeker == nil {
+		_, err := sink.buffered.Write(p)
+		return err
+	}
+	_, err := sink.writer.Write(p)
+	return err
+}
+
+func (sink *AudioSink) writeAt(offset int64, p []byte) error {
+	if _, err := sink.seeker.Seek(offset, io.SeekStart); err != nil {
+		return err
+	}
+	_, err := sink.seeker.Write(p)
+	return err
+}
+
+func (sink *AudioSink) fail(err error) error {
+	if sink.err == nil {
+		sink.err = err
+	}
+	return sink.err
+}
+
+// ConcatenateWAV : Joins several synthesized WAV segments of the same format into one WAV file written to writer.
+// Segments in other formats, such as Ogg, are simply written one after the other.
+func ConcatenateWAV(writer io.Writer, segments ...io.Reader) error {
+	sink := NewAudioSink(writer)
+	for _, segment := range segments {
+		if err := sink.StartSegment(); err != nil {
+			return err
+		}
+		if _, err := sink.ReadFrom(segment); err != nil {
+			return err
+		}
+	}
+	return sink.Close()
+}
+
+// isWAVPrefix : Reports whether head may be the start of WAV audio
+func isWAVPrefix(head []byte) bool {
+	riffWave := []byte("RIFF\x00\x00\x00\x00WAVE")
+	for i := 0; i < len(head) && i < len(riffWave); i++ {
+		if i >= 4 && i < 8 {
+			continue
+		}
+		if head[i] != riffWave[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// parseWAVHeader : Finds the `fmt ` chunk payload and the offset of the `data` chunk header in a WAV header.
+// complete is false while more bytes are needed.
+func parseWAVHeader(header []byte) (format []byte, dataOffset int64, complete bool, err error) {
+	if len(header) < 12 {
+		return nil, 0, false, nil
+	}
+	offset := int64(12)
+	for {
+		if int64(len(header)) < offset+8 {
+			return nil, 0, false, nil
+		}
+		id := string(header[offset : offset+4])
+		size := int64(binary.LittleEndian.Uint32(header[offset+4 : offset+8]))
+		if id == "data" {
+			if format == nil {
+				return nil, 0, false, fmt.Errorf("the WAV audio has no fmt chunk before its data")
+			}
+			return format, offset, true, nil
+		}
+		end := offset + 8 + size + size%2
+		if int64(len(header)) < end {
+			return nil, 0, false, nil
+		}
+		if id == "fmt " {
+			format = append([]byte(nil), header[offset+8:offset+8+size]...)
+		}
+		offset = end
+	}
+}
@@ -0,0 +1,143 @@
+/**
+ * (C) Copyright IBM Corp. 2021.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package texttospeechv1_test
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io/ioutil"
+	"os"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/watson-developer-cloud/go-sdk/v2/texttospeechv1"
+)
+
+// streamedWAV : Builds WAV audio as the service streams it, with placeholder sizes in the header
+func streamedWAV(rate uint32, samples []byte) []byte {
+	var wav bytes.Buffer
+	wav.WriteString("RIFF")
+	_ = binary.Write(&wav, binary.LittleEndian, uint32(0xFFFFFFFF))
+	wav.WriteString("WAVE")
+	wav.WriteString("fmt ")
+	_ = binary.Write(&wav, binary.LittleEndian, uint32(16))
+	_ = binary.Write(&wav, binary.LittleEndian, uint16(1))  // PCM
+	_ = binary.Write(&wav, binary.LittleEndian, uint16(1))  // channels
+	_ = binary.Write(&wav, binary.LittleEndian, rate)       // sample rate
+	_ = binary.Write(&wav, binary.LittleEndian, rate*2)     // byte rate
+	_ = binary.Write(&wav, binary.LittleEndian, uint16(2))  // block align
+	_ = binary.Write(&wav, binary.LittleEndian, uint16(16)) // bits per sample
+	wav.WriteString("LIST")
+	_ = binary.Write(&wav, binary.LittleEndian, uint32(4))
+	wav.WriteString("INFO")
+	wav.WriteString("data")
+	_ = binary.Write(&wav, binary.LittleEndian, uint32(0xFFFFFFFF))
+	wav.Write(samples)
+	return wav.Bytes()
+}
+
+// wavSizes : Returns the RIFF size, the data chunk size and the samples of a WAV file built by streamedWAV
+func wavSizes(wav []byte) (riffSize uint32, dataSize uint32, samples []byte) {
+	const dataOffset = 12 + 8 + 16 + 8 + 4
+	return binary.LittleEndian.Uint32(wav[4:8]), binary.LittleEndian.Uint32(wav[dataOffset+4 : dataOffset+8]), wav[dataOffset+8:]
+}
+
+var _ = Describe(`AudioSink`, func() {
+	samples := []byte("0123456789abcdef")
+
+	It(`Patch the header of WAV audio written in chunks to a non-seekable writer`, func() {
+		var output bytes.Buffer
+		sink := texttospeechv1.NewAudioSink(&output)
+		wav := streamedWAV(22050, samples)
+		for i := 0; i < len(wav); i += 5 {
+			end := i + 5
+			if end > len(wav) {
+				end = len(wav)
+			}
+			_, err := sink.Write(wav[i:end])
+			Expect(err).To(BeNil())
+		}
+		Expect(output.Len()).To(BeZero())
+		Expect(sink.Close()).To(Succeed())
+
+		riffSize, dataSize, written := wavSizes(output.Bytes())
+		Expect(riffSize).To(Equal(uint32(output.Len() - 8)))
+		Expect(dataSize).To(Equal(uint32(len(samples))))
+		Expect(written).To(Equal(samples))
+
+		_, err := sink.Write(samples)
+		Expect(err).To(Equal(texttospeechv1.ErrAudioSinkClosed))
+	})
+
+	It(`Patch the header of WAV audio in a seekable file`, func() {
+		file, err := ioutil.TempFile("", "audio-sink-*.wav")
+		Expect(err).To(BeNil())
+		defer os.Remove(file.Name())
+		defer file.Close()
+		_, err = file.Write([]byte("prefix"))
+		Expect(err).To(BeNil())
+
+		sink := texttospeechv1.NewAudioSink(file)
+		_, err = sink.ReadFrom(bytes.NewReader(streamedWAV(22050, samples[:15])))
+		Expect(err).To(BeNil())
+		Expect(sink.Close()).To(Succeed())
+
+		written, err := ioutil.ReadFile(file.Name())
+		Expect(err).To(BeNil())
+		Expect(string(written[:6])).To(Equal("prefix"))
+		riffSize, dataSize, writtenSamples := wavSizes(written[6:])
+		Expect(dataSize).To(Equal(uint32(15)))
+		Expect(writtenSamples).To(Equal(append(samples[:15:15], 0)))
+		Expect(riffSize).To(Equal(uint32(len(written) - 6 - 8)))
+	})
+
+	It(`Pass other audio formats through unchanged`, func() {
+		var output bytes.Buffer
+		sink := texttospeechv1.NewAudioSink(&output)
+		_, err := sink.Write([]byte("OggS"))
+		Expect(err).To(BeNil())
+		Expect(output.String()).To(Equal("OggS"))
+		Expect(sink.StartSegment()).To(Succeed())
+		_, err = sink.Write([]byte("OggS"))
+		Expect(err).To(BeNil())
+		Expect(sink.Close()).To(Succeed())
+		Expect(output.String()).To(Equal("OggSOggS"))
+	})
+
+	It(`Concatenate WAV segments`, func() {
+		var output bytes.Buffer
+		err := texttospeechv1.ConcatenateWAV(&output,
+			bytes.NewReader(streamedWAV(22050, samples[:8])),
+			bytes.NewReader(nil),
+			bytes.NewReader(streamedWAV(22050, samples[8:])))
+		Expect(err).To(BeNil())
+
+		riffSize, dataSize, written := wavSizes(output.Bytes())
+		Expect(riffSize).To(Equal(uint32(output.Len() - 8)))
+		Expect(dataSize).To(Equal(uint32(len(samples))))
+		Expect(written).To(Equal(samples))
+	})
+
+	It(`Reject WAV segments of different formats`, func() {
+		var output bytes.Buffer
+		err := texttospeechv1.ConcatenateWAV(&output,
+			bytes.NewReader(streamedWAV(22050, samples)),
+			bytes.NewReader(streamedWAV(16000, samples)))
+		Expect(err).ToNot(BeNil())
+		Expect(err.Error()).To(ContainSubstring("different WAV format"))
+	})
+})
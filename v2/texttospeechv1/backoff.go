@@ -0,0 +1,24 @@
+/**
+ * (C) Copyright IBM Corp. 2021.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package texttospeechv1
+
+import "github.com/watson-developer-cloud/go-sdk/v2/internal/polling"
+
+// Backoff : How often to poll for the status of server-side work. InitialInterval, the wait before the second poll,
+// defaults to one second; MaxInterval, the longest wait between two polls, to 30 seconds; and Multiplier, the factor
+// by which the wait grows after each poll, to 2.
+type Backoff = polling.Backoff
@@ -0,0 +1,297 @@
+/**
+ * (C) Copyright IBM Corp. 2021.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package texttospeechv1
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+
+	"github.com/IBM/go-sdk-core/v5/core"
+	"github.com/watson-developer-cloud/go-sdk/v2/internal/polling"
+)
+
+// Constants associated with the Prompt.Status property.
+// The status of the prompt.
+const (
+	PromptStatusAvailableConst  = "available"
+	PromptStatusFailedConst     = "failed"
+	PromptStatusProcessingConst = "processing"
+)
+
+// PromptManifestFileName is the name of the manifest that UploadCustomPrompts reads from the prompt directory
+const PromptManifestFileName = "manifest.json"
+
+// PromptManifest : The prompts of a directory, for UploadCustomPrompts
+type PromptManifest struct {
+	// The speaker of the prompts that do not name one.
+	SpeakerID string `json:"speaker_id,omitempty"`
+
+	Prompts []PromptManifestEntry `json:"prompts"`
+}
+
+// PromptManifestEntry : A prompt of a PromptManifest
+type PromptManifestEntry struct {
+	// The identifier of the prompt in the custom model.
+	PromptID string `json:"prompt_id"`
+
+	// The WAV file of the prompt, relative to the prompt directory.
+	File string `json:"file"`
+
+	// The transcript of the prompt.
+	PromptText string `json:"prompt_text"`
+
+	// The speaker of the prompt, if not that of the manifest.
+	SpeakerID string `json:"speaker_id,omitempty"`
+}
+
+// ReadPromptManifest : Reads a PromptManifest in its JSON form
+func ReadPromptManifest(reader io.Reader) (*PromptManifest, error) {
+	manifest := &PromptManifest{}
+	if err := json.NewDecoder(reader).Decode(manifest); err != nil {
+		return nil, err
+	}
+	return manifest, nil
+}
+
+// UploadPromptsOptions : Options for UploadCustomPrompts
+type UploadPromptsOptions struct {
+	// The prompts to upload. Defaults to the manifest.json file of the prompt directory.
+	Manifest *PromptManifest
+
+	// How many times a prompt that fails is uploaded again. Defaults to none.
+	Retries int
+
+	// How often to poll for the status of the prompts. May be nil.
+	Backoff *Backoff
+}
+
+// PromptUploadResult : The outcome of the upload of one prompt
+type PromptUploadResult struct {
+	PromptID string `json:"prompt_id"`
+	File     string `json:"file"`
+
+	// The final status of the prompt: `available` or `failed`.
+	Status string `json:"status"`
+
+	// Why the prompt failed: the error of the service for the prompt, or that of the upload.
+	Error string `json:"error,omitempty"`
+
+	// The number of times the prompt was uploaded.
+	Attempts int `json:"attempts"`
+}
+
+// PromptUploadReport : The outcome of UploadCustomPrompts, with the prompts in the order of the manifest
+type PromptUploadReport struct {
+	Prompts []PromptUploadResult `json:"prompts"`
+}
+
+// Failed : Returns the prompts that are not available
+func (report *PromptUploadReport) Failed() []PromptUploadResult {
+	var failed []PromptUploadResult
+	for _, prompt := range report.Prompts {
+		if prompt.Status != PromptStatusAvailableConst {
+			failed = append(failed, prompt)
+		}
+	}
+	return failed
+}
+
+// UploadCustomPrompts : Adds the prompts of a directory to a custom model and waits until the service has processed
+// them. Each prompt of the manifest is uploaded with AddCustomPrompt, then the prompts are polled with
+// ListCustomPrompts until none is processing; prompts that failed, and uploads the service rejected, are tried again
+// as many times as the options allow. The options may be nil.
+//
+// Prompts that fail do not make the call fail: the report says how each prompt ended. An error is returned when the
+// manifest or a file is invalid, in which case nothing is uploaded, or when ctx ends, in which case the report covers
+// the prompts as far as they got.
+func (textToSpeech *TextToSpeechV1) UploadCustomPrompts(ctx context.Context, customizationID string, dir string, uploadOptions *UploadPromptsOptions) (*PromptUploadReport, error) {
+	if uploadOptions == nil {
+		uploadOptions = &UploadPromptsOptions{}
+	}
+	manifest := uploadOptions.Manifest
+	if manifest == nil {
+		file, err := os.Open(filepath.Join(dir, PromptManifestFileName))
+		if err != nil {
+			return nil, err
+		}
+		manifest, err = ReadPromptManifest(file)
+		file.Close()
+		if err != nil {
+			return nil, fmt.Errorf("%s: %s", PromptManifestFileName, err.Error())
+		}
+	}
+	if err := checkPromptManifest(manifest, dir); err != nil {
+		return nil, err
+	}
+
+	report := &PromptUploadReport{Prompts: make([]PromptUploadResult, len(manifest.Prompts))}
+	pending := make([]int, len(manifest.Prompts))
+	for i, entry := range manifest.Prompts {
+		report.Prompts[i] = PromptUploadResult{PromptID: entry.PromptID, File: entry.File, Status: PromptStatusProcessingConst}
+		pending[i] = i
+	}
+
+	for attempt := 0; attempt <= uploadOptions.Retries && len(pending) > 0; attempt++ {
+		var processing []int
+		for _, i := range pending {
+			result := &report.Prompts[i]
+			result.Attempts++
+			result.Status, result.Error = PromptStatusProcessingConst, ""
+			if err := textToSpeech.addManifestPrompt(ctx, customizationID, dir, manifest, manifest.Prompts[i]); err != nil {
+				if ctx.Err() != nil {
+					return report, ctx.Err()
+				}
+				result.Status, result.Error = PromptStatusFailedConst, err.Error()
+				continue
+			}
+			processing = append(processing, i)
+		}
+
+		if err := textToSpeech.waitForPrompts(ctx, customizationID, report, processing, uploadOptions.Backoff); err != nil {
+			return report, err
+		}
+
+		pending = pending[:0]
+		for i := range report.Prompts {
+			if report.Prompts[i].Status == PromptStatusFailedConst {
+				pending = append(pending, i)
+			}
+		}
+	}
+	return report, nil
+}
+
+// checkPromptManifest : Checks that the prompts of a manifest are complete, unique and have their files
+func checkPromptManifest(manifest *PromptManifest, dir string) error {
+	seen := map[string]bool{}
+	for i, entry := range manifest.Prompts {
+		switch {
+		case entry.PromptID == "":
+			return fmt.Errorf("prompt %d has no prompt_id", i+1)
+		case seen[entry.PromptID]:
+			return fmt.Errorf("prompt %q is listed more than once", entry.PromptID)
+		case entry.File == "":
+			return fmt.Errorf("prompt %q has no file", entry.PromptID)
+		case entry.PromptText == "":
+			return fmt.Errorf("prompt %q has no prompt_text", entry.PromptID)
+		}
+		seen[entry.PromptID] = true
+		info, err := os.Stat(filepath.Join(dir, entry.File))
+		if err != nil {
+			return err
+		}
+		if info.IsDir() {
+			return fmt.Errorf("prompt %q: %s is a directory", entry.PromptID, entry.File)
+		}
+	}
+	return nil
+}
+
+func (textToSpeech *TextToSpeechV1) addManifestPrompt(ctx context.Context, customizationID string, dir string, manifest *PromptManifest, entry PromptManifestEntry) error {
+	metadata, err := textToSpeech.NewPromptMetadata(entry.PromptText)
+	if err != nil {
+		return err
+	}
+	speakerID := entry.SpeakerID
+	if speakerID == "" {
+		speakerID = manifest.SpeakerID
+	}
+	if speakerID != "" {
+		metadata.SpeakerID = core.StringPtr(speakerID)
+	}
+
+	file, err := os.Open(filepath.Join(dir, entry.File))
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	_, _, err = textToSpeech.AddCustomPromptWithContext(ctx, textToSpeech.NewAddCustomPromptOptions(customizationID, entry.PromptID, metadata, file))
+	return err
+}
+
+// waitForPrompts : Polls the prompts of a custom model until none of the given prompts is processing, recording
+// their status in the report
+func (textToSpeech *TextToSpeechV1) waitForPrompts(ctx context.Context, customizationID string, report *PromptUploadReport, processing []int, backoff *Backoff) error {
+	if len(processing) == 0 {
+		return nil
+	}
+	return polling.Poll(ctx, backoff, func() (bool, error) {
+		prompts, _, err := textToSpeech.ListCustomPromptsWithContext(ctx, textToSpeech.NewListCustomPromptsOptions(customizationID))
+		if err != nil {
+			return false, err
+		}
+		byID := make(map[string]Prompt, len(prompts.Prompts))
+		for _, prompt := range prompts.Prompts {
+			byID[core.StringNilMapper(prompt.PromptID)] = prompt
+		}
+		done := true
+		for _, i := range processing {
+			result := &report.Prompts[i]
+			prompt, ok := byID[result.PromptID]
+			if !ok {
+				result.Status, result.Error = PromptStatusFailedConst, "the prompt is not in the custom model"
+				continue
+			}
+			result.Status, result.Error = core.StringNilMapper(prompt.Status), core.StringNilMapper(prompt.Error)
+			if result.Status == PromptStatusProcessingConst {
+				done = false
+			}
+		}
+		return done, nil
+	})
+}
+
+// WaitForCustomPrompt : Polls a prompt of a custom model until it is no longer processing. The prompt is returned
+// whatever its final status; check for `failed`. The backoff may be nil.
+func (textToSpeech *TextToSpeechV1) WaitForCustomPrompt(ctx context.Context, customizationID string, promptID string, backoff *Backoff) (prompt *Prompt, err error) {
+	err = polling.Poll(ctx, backoff, func() (bool, error) {
+		var getErr error
+		prompt, _, getErr = textToSpeech.GetCustomPromptWithContext(ctx, textToSpeech.NewGetCustomPromptOptions(customizationID, promptID))
+		if getErr != nil {
+			return false, getErr
+		}
+		return core.StringNilMapper(prompt.Status) != PromptStatusProcessingConst, nil
+	})
+	return
+}
+
+// EnrollSpeakerModel : Creates a speaker model from audio and waits until GetSpeakerModel finds it, retrying while
+// the service answers 404 Not Found. The audio is read from the reader, which is closed afterwards. The backoff may
+// be nil.
+func (textToSpeech *TextToSpeechV1) EnrollSpeakerModel(ctx context.Context, speakerName string, audio io.ReadCloser, backoff *Backoff) (*SpeakerModel, error) {
+	speaker, _, err := textToSpeech.CreateSpeakerModelWithContext(ctx, textToSpeech.NewCreateSpeakerModelOptions(speakerName, audio))
+	if audio != nil {
+		audio.Close()
+	}
+	if err != nil {
+		return nil, err
+	}
+	getSpeakerModelOptions := textToSpeech.NewGetSpeakerModelOptions(core.StringNilMapper(speaker.SpeakerID))
+	err = polling.Poll(ctx, backoff, func() (bool, error) {
+		_, response, err := textToSpeech.GetSpeakerModelWithContext(ctx, getSpeakerModelOptions)
+		if err != nil && response != nil && response.StatusCode == http.StatusNotFound {
+			return false, nil
+		}
+		return err == nil, err
+	})
+	return speaker, err
+}
@@ -0,0 +1,257 @@
+/**
+ * (C) Copyright IBM Corp. 2021.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package texttospeechv1_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/IBM/go-sdk-core/v5/core"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/watson-developer-cloud/go-sdk/v2/texttospeechv1"
+)
+
+// promptsStandIn : A stand-in for the prompts and speakers of the service. A prompt is processing for two polls;
+// then it is available unless its audio starts with "bad", or with "flaky" on the first upload. A speaker is found
+// from the second poll on.
+type promptsStandIn struct {
+	mutex    sync.Mutex
+	uploads  map[string]int
+	polls    map[string]int
+	prompts  map[string]*texttospeechv1.Prompt
+	speakers map[string]int
+	metadata map[string]texttospeechv1.PromptMetadata
+}
+
+func newPromptsStandIn() *promptsStandIn {
+	return &promptsStandIn{
+		uploads:  map[string]int{},
+		polls:    map[string]int{},
+		prompts:  map[string]*texttospeechv1.Prompt{},
+		speakers: map[string]int{},
+		metadata: map[string]texttospeechv1.PromptMetadata{},
+	}
+}
+
+func (s *promptsStandIn) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	w.Header().Set("Content-Type", "application/json")
+	const prompts = "/v1/customizations/custom1/prompts"
+	switch {
+	case r.Method == "POST" && strings.HasPrefix(r.URL.Path, prompts+"/"):
+		promptID := strings.TrimPrefix(r.URL.Path, prompts+"/")
+		var metadata texttospeechv1.PromptMetadata
+		_ = json.Unmarshal([]byte(r.FormValue("metadata")), &metadata)
+		file, _, err := r.FormFile("file")
+		if err != nil {
+			w.WriteHeader(400)
+			fmt.Fprint(w, `{"error":"No file","code":400}`)
+			return
+		}
+		audio, _ := ioutil.ReadAll(file)
+		if string(audio) == "rejected" {
+			w.WriteHeader(400)
+			fmt.Fprint(w, `{"error":"Invalid audio","code":400}`)
+			return
+		}
+		s.uploads[promptID]++
+		s.polls[promptID] = 0
+		s.metadata[promptID] = metadata
+		prompt := &texttospeechv1.Prompt{PromptID: core.StringPtr(promptID), Prompt: metadata.PromptText, Status: core.StringPtr("processing")}
+		s.prompts[promptID] = prompt
+		if strings.HasPrefix(string(audio), "bad") || (strings.HasPrefix(string(audio), "flaky") && s.uploads[promptID] == 1) {
+			prompt.Error = core.StringPtr("The audio is too noisy")
+		}
+		w.WriteHeader(201)
+		_ = json.NewEncoder(w).Encode(prompt)
+	case r.Method == "GET" && r.URL.Path == prompts:
+		var list texttospeechv1.Prompts
+		for id, prompt := range s.prompts {
+			s.polls[id]++
+			if s.polls[id] > 2 {
+				if prompt.Error != nil {
+					prompt.Status = core.StringPtr("failed")
+				} else {
+					prompt.Status = core.StringPtr("available")
+				}
+			}
+			copied := *prompt
+			if *copied.Status != "failed" {
+				copied.Error = nil
+			}
+			list.Prompts = append(list.Prompts, copied)
+		}
+		_ = json.NewEncoder(w).Encode(list)
+	case r.Method == "GET" && strings.HasPrefix(r.URL.Path, prompts+"/"):
+		promptID := strings.TrimPrefix(r.URL.Path, prompts+"/")
+		prompt, ok := s.prompts[promptID]
+		if !ok {
+			w.WriteHeader(404)
+			fmt.Fprint(w, `{"error":"Not found","code":404}`)
+			return
+		}
+		s.polls[promptID]++
+		if s.polls[promptID] > 2 {
+			prompt.Status = core.StringPtr("available")
+		}
+		_ = json.NewEncoder(w).Encode(prompt)
+	case r.Method == "POST" && r.URL.Path == "/v1/speakers":
+		name := r.URL.Query().Get("speaker_name")
+		s.speakers[name] = 0
+		w.WriteHeader(201)
+		fmt.Fprintf(w, `{"speaker_id":"id-%s"}`, name)
+	case r.Method == "GET" && strings.HasPrefix(r.URL.Path, "/v1/speakers/id-"):
+		name := strings.TrimPrefix(r.URL.Path, "/v1/speakers/id-")
+		if _, ok := s.speakers[name]; ok {
+			s.speakers[name]++
+			if s.speakers[name] > 1 {
+				fmt.Fprint(w, `{"customizations":[]}`)
+				return
+			}
+		}
+		w.WriteHeader(404)
+		fmt.Fprint(w, `{"error":"Speaker model not found","code":404}`)
+	default:
+		w.WriteHeader(404)
+		fmt.Fprint(w, `{"error":"Not found","code":404}`)
+	}
+}
+
+var _ = Describe(`Custom prompt helpers`, func() {
+	var standIn *promptsStandIn
+	var testServer *httptest.Server
+	var textToSpeechService *texttospeechv1.TextToSpeechV1
+	var dir string
+	backoff := &texttospeechv1.Backoff{InitialInterval: time.Millisecond, MaxInterval: 5 * time.Millisecond}
+
+	writeFile := func(name string, content string) {
+		Expect(ioutil.WriteFile(filepath.Join(dir, name), []byte(content), 0644)).To(Succeed())
+	}
+
+	BeforeEach(func() {
+		standIn = newPromptsStandIn()
+		testServer = httptest.NewServer(standIn)
+		var err error
+		textToSpeechService, err = texttospeechv1.NewTextToSpeechV1(&texttospeechv1.TextToSpeechV1Options{
+			URL:           testServer.URL,
+			Authenticator: &core.NoAuthAuthenticator{},
+		})
+		Expect(err).To(BeNil())
+		dir, err = ioutil.TempDir("", "prompts")
+		Expect(err).To(BeNil())
+
+		writeFile("hello.wav", "good")
+		writeFile("goodbye.wav", "flaky")
+		writeFile("noisy.wav", "bad")
+		writeFile("empty.wav", "rejected")
+		writeFile(texttospeechv1.PromptManifestFileName, `{
+			"speaker_id": "speaker1",
+			"prompts": [
+				{"prompt_id": "hello", "file": "hello.wav", "prompt_text": "Hello"},
+				{"prompt_id": "goodbye", "file": "goodbye.wav", "prompt_text": "Goodbye", "speaker_id": "speaker2"},
+				{"prompt_id": "noisy", "file": "noisy.wav", "prompt_text": "Noisy"},
+				{"prompt_id": "empty", "file": "empty.wav", "prompt_text": "Empty"}
+			]
+		}`)
+	})
+	AfterEach(func() {
+		testServer.Close()
+		os.RemoveAll(dir)
+	})
+
+	It(`Invoke UploadCustomPrompts with retries`, func() {
+		report, err := textToSpeechService.UploadCustomPrompts(context.Background(), "custom1", dir, &texttospeechv1.UploadPromptsOptions{Retries: 1, Backoff: backoff})
+		Expect(err).To(BeNil())
+		Expect(report.Prompts).To(Equal([]texttospeechv1.PromptUploadResult{
+			{PromptID: "hello", File: "hello.wav", Status: "available", Attempts: 1},
+			{PromptID: "goodbye", File: "goodbye.wav", Status: "available", Attempts: 2},
+			{PromptID: "noisy", File: "noisy.wav", Status: "failed", Error: "The audio is too noisy", Attempts: 2},
+			{PromptID: "empty", File: "empty.wav", Status: "failed", Error: "Invalid audio", Attempts: 2},
+		}))
+		Expect(report.Failed()).To(HaveLen(2))
+		Expect(*standIn.metadata["hello"].SpeakerID).To(Equal("speaker1"))
+		Expect(*standIn.metadata["goodbye"].SpeakerID).To(Equal("speaker2"))
+		Expect(*standIn.metadata["goodbye"].PromptText).To(Equal("Goodbye"))
+	})
+
+	It(`Invoke UploadCustomPrompts without retries`, func() {
+		report, err := textToSpeechService.UploadCustomPrompts(context.Background(), "custom1", dir, &texttospeechv1.UploadPromptsOptions{Backoff: backoff})
+		Expect(err).To(BeNil())
+		Expect(report.Failed()).To(HaveLen(3))
+		Expect(standIn.uploads["goodbye"]).To(Equal(1))
+	})
+
+	It(`Invoke UploadCustomPrompts with an invalid manifest`, func() {
+		manifest := &texttospeechv1.PromptManifest{Prompts: []texttospeechv1.PromptManifestEntry{
+			{PromptID: "hello", File: "hello.wav", PromptText: "Hello"},
+			{PromptID: "missing", File: "missing.wav", PromptText: "Missing"},
+		}}
+		_, err := textToSpeechService.UploadCustomPrompts(context.Background(), "custom1", dir, &texttospeechv1.UploadPromptsOptions{Manifest: manifest})
+		Expect(err).ToNot(BeNil())
+		Expect(standIn.uploads).To(BeEmpty())
+
+		manifest.Prompts[1] = manifest.Prompts[0]
+		_, err = textToSpeechService.UploadCustomPrompts(context.Background(), "custom1", dir, &texttospeechv1.UploadPromptsOptions{Manifest: manifest})
+		Expect(err).ToNot(BeNil())
+	})
+
+	It(`Invoke UploadCustomPrompts with a cancelled context`, func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+		defer cancel()
+		slow := &texttospeechv1.Backoff{InitialInterval: time.Second}
+		report, err := textToSpeechService.UploadCustomPrompts(ctx, "custom1", dir, &texttospeechv1.UploadPromptsOptions{Backoff: slow})
+		Expect(err).To(Equal(context.DeadlineExceeded))
+		Expect(report.Prompts[0].Status).To(Equal("processing"))
+	})
+
+	It(`Invoke WaitForCustomPrompt`, func() {
+		_, err := textToSpeechService.UploadCustomPrompts(context.Background(), "custom1", dir, &texttospeechv1.UploadPromptsOptions{
+			Manifest: &texttospeechv1.PromptManifest{Prompts: []texttospeechv1.PromptManifestEntry{{PromptID: "hello", File: "hello.wav", PromptText: "Hello"}}},
+			Backoff:  backoff,
+		})
+		Expect(err).To(BeNil())
+		prompt, err := textToSpeechService.WaitForCustomPrompt(context.Background(), "custom1", "hello", backoff)
+		Expect(err).To(BeNil())
+		Expect(*prompt.Status).To(Equal("available"))
+
+		_, err = textToSpeechService.WaitForCustomPrompt(context.Background(), "custom1", "unknown", backoff)
+		Expect(err).ToNot(BeNil())
+	})
+
+	It(`Invoke EnrollSpeakerModel`, func() {
+		speaker, err := textToSpeechService.EnrollSpeakerModel(context.Background(), "Ann", ioutil.NopCloser(strings.NewReader("audio")), backoff)
+		Expect(err).To(BeNil())
+		Expect(*speaker.SpeakerID).To(Equal("id-Ann"))
+		Expect(standIn.speakers["Ann"]).To(Equal(2))
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		_, err = textToSpeechService.EnrollSpeakerModel(ctx, "Bob", ioutil.NopCloser(strings.NewReader("audio")), backoff)
+		Expect(err).ToNot(BeNil())
+	})
+})
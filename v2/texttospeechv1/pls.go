@@ -0,0 +1,316 @@
+/**
+ * (C) Copyright IBM Corp. 2021.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package texttospeechv1
+
+import (
+	"context"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/IBM/go-sdk-core/v5/core"
+	"github.com/watson-developer-cloud/go-sdk/v2/internal/wordsync"
+)
+
+// PLSNamespace is the XML namespace of W3C Pronunciation Lexicon Specification documents
+const PLSNamespace = "http://www.w3.org/2005/01/pronunciation-lexicon"
+
+// Phonetic alphabets of the translations of custom words
+const (
+	PhoneticAlphabetIPAConst = "ipa"
+	PhoneticAlphabetIBMConst = "ibm"
+)
+
+type plsLexicon struct {
+	XMLName  xml.Name    `xml:"lexicon"`
+	Version  string      `xml:"version,attr"`
+	XMLNS    string      `xml:"xmlns,attr,omitempty"`
+	Alphabet string      `xml:"alphabet,attr,omitempty"`
+	Lang     string      `xml:"http://www.w3.org/XML/1998/namespace lang,attr,omitempty"`
+	Lexemes  []plsLexeme `xml:"lexeme"`
+}
+
+type plsLexeme struct {
+	Role      string       `xml:"role,attr,omitempty"`
+	Graphemes []string     `xml:"grapheme"`
+	Phonemes  []plsPhoneme `xml:"phoneme"`
+	Aliases   []string     `xml:"alias"`
+}
+
+type plsPhoneme struct {
+	Alphabet string `xml:"alphabet,attr,omitempty"`
+	Value    string `xml:",chardata"`
+}
+
+// partsOfSpeech holds the parts of speech of Japanese custom words
+var partsOfSpeech = map[string]bool{
+	WordPartOfSpeechDosiConst: true,
+	WordPartOfSpeechFukuConst: true,
+	WordPartOfSpeechGobiConst: true,
+	WordPartOfSpeechHokaConst: true,
+	WordPartOfSpeechJodoConst: true,
+	WordPartOfSpeechJosiConst: true,
+	WordPartOfSpeechKatoConst: true,
+	WordPartOfSpeechKedoConst: true,
+	WordPartOfSpeechKeyoConst: true,
+	WordPartOfSpeechKigoConst: true,
+	WordPartOfSpeechKoyuConst: true,
+	WordPartOfSpeechMesiConst: true,
+	WordPartOfSpeechRetaConst: true,
+	WordPartOfSpeechStbiConst: true,
+	WordPartOfSpeechSttoConst: true,
+	WordPartOfSpeechStzoConst: true,
+	WordPartOfSpeechSujiConst: true,
+}
+
+var phonemeTranslationPattern = regexp.MustCompile(`^<phoneme\s+alphabet="([^"]*)"\s+ph="([^"]*)"\s*(/>|>\s*</phoneme>)$`)
+
+// PhonemeTranslation : Returns the SSML translation of a custom word for a pronunciation in the `ipa` or `ibm`
+// alphabet
+func PhonemeTranslation(alphabet string, ph string) string {
+	if strings.EqualFold(alphabet, PhoneticAlphabetIPAConst) {
+		alphabet = "IPA"
+	}
+	return fmt.Sprintf(`<phoneme alphabet="%s" ph="%s"></phoneme>`, escapeXMLAttribute(alphabet), escapeXMLAttribute(ph))
+}
+
+// ParsePhonemeTranslation : Returns the alphabet, in lower case, and the pronunciation of a phonetic translation.
+// ok is false for a sounds-like translation.
+func ParsePhonemeTranslation(translation string) (alphabet string, ph string, ok bool) {
+	match := phonemeTranslationPattern.FindStringSubmatch(strings.TrimSpace(translation))
+	if match == nil {
+		return "", "", false
+	}
+	return strings.ToLower(unescapeXMLAttribute(match[1])), unescapeXMLAttribute(match[2]), true
+}
+
+// ReadPLS : Reads the entries of a W3C PLS lexicon as custom words. Each grapheme of a lexeme becomes a word. The
+// first phoneme of a lexeme becomes a phonetic translation in the alphabet of the phoneme or of the lexicon, where
+// `x-ibm-spr` is accepted for `ibm`; a lexeme without a phoneme uses its first alias as a sounds-like translation.
+// For a `ja-JP` lexicon, the role of a lexeme is taken as the part of speech of its words and must be one of the
+// WordPartOfSpeech constants; the roles of lexicons in other languages are ignored.
+func ReadPLS(reader io.Reader) ([]Word, error) {
+	var lexicon plsLexicon
+	if err := xml.NewDecoder(reader).Decode(&lexicon); err != nil {
+		return nil, err
+	}
+	if lexicon.XMLName.Space != "" && lexicon.XMLName.Space != PLSNamespace {
+		return nil, fmt.Errorf("unexpected lexicon namespace %q", lexicon.XMLName.Space)
+	}
+
+	japanese := strings.EqualFold(lexicon.Lang, CreateCustomModelOptionsLanguageJaJpConst)
+	var words []Word
+	for i, lexeme := range lexicon.Lexemes {
+		if japanese && lexeme.Role != "" && !partsOfSpeech[lexeme.Role] {
+			return nil, fmt.Errorf("lexeme %d: unsupported part of speech %q", i+1, lexeme.Role)
+		}
+		var translation string
+		switch {
+		case len(lexeme.Phonemes) > 0:
+			phoneme := lexeme.Phonemes[0]
+			alphabet := phoneme.Alphabet
+			if alphabet == "" {
+				alphabet = lexicon.Alphabet
+			}
+			switch strings.ToLower(alphabet) {
+			case PhoneticAlphabetIPAConst:
+			case PhoneticAlphabetIBMConst, "x-ibm-spr":
+				alphabet = PhoneticAlphabetIBMConst
+			default:
+				return nil, fmt.Errorf("lexeme %d: unsupported phonetic alphabet %q", i+1, alphabet)
+			}
+			translation = PhonemeTranslation(alphabet, strings.TrimSpace(phoneme.Value))
+		case len(lexeme.Aliases) > 0:
+			translation = strings.TrimSpace(lexeme.Aliases[0])
+		default:
+			return nil, fmt.Errorf("lexeme %d has neither a phoneme nor an alias", i+1)
+		}
+		if len(lexeme.Graphemes) == 0 {
+			return nil, fmt.Errorf("lexeme %d has no grapheme", i+1)
+		}
+		for _, grapheme := range lexeme.Graphemes {
+			word := Word{Word: core.StringPtr(strings.TrimSpace(grapheme)), Translation: core.StringPtr(translation)}
+			if japanese && lexeme.Role != "" {
+				word.PartOfSpeech = core.StringPtr(lexeme.Role)
+			}
+			words = append(words, word)
+		}
+	}
+	return words, nil
+}
+
+// WritePLS : Writes custom words as a W3C PLS lexicon for the language, such as `en-US`. Words are written in
+// alphabetical order, one lexeme each; phonetic translations become phonemes and sounds-like translations aliases.
+// As ReadPLS expects, parts of speech are written as the roles of lexemes only for a `ja-JP` lexicon.
+func WritePLS(writer io.Writer, words []Word, language string) error {
+	lexicon := plsLexicon{Version: "1.0", XMLNS: PLSNamespace, Alphabet: PhoneticAlphabetIPAConst, Lang: language}
+	japanese := strings.EqualFold(language, CreateCustomModelOptionsLanguageJaJpConst)
+	sorted := append([]Word(nil), words...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return core.StringNilMapper(sorted[i].Word) < core.StringNilMapper(sorted[j].Word)
+	})
+	for _, word := range sorted {
+		lexeme := plsLexeme{Graphemes: []string{core.StringNilMapper(word.Word)}}
+		if japanese {
+			lexeme.Role = core.StringNilMapper(word.PartOfSpeech)
+		}
+		if alphabet, ph, ok := ParsePhonemeTranslation(core.StringNilMapper(word.Translation)); ok {
+			phoneme := plsPhoneme{Value: ph}
+			if alphabet != PhoneticAlphabetIPAConst {
+				phoneme.Alphabet = alphabet
+			}
+			lexeme.Phonemes = []plsPhoneme{phoneme}
+		} else {
+			lexeme.Aliases = []string{core.StringNilMapper(word.Translation)}
+		}
+		lexicon.Lexemes = append(lexicon.Lexemes, lexeme)
+	}
+
+	if _, err := io.WriteString(writer, xml.Header); err != nil {
+		return err
+	}
+	encoder := xml.NewEncoder(writer)
+	encoder.Indent("", "  ")
+	if err := encoder.Encode(lexicon); err != nil {
+		return err
+	}
+	_, err := io.WriteString(writer, "\n")
+	return err
+}
+
+// SyncWordsOptions : Options for SyncCustomModelWords
+type SyncWordsOptions struct {
+	// Delete the words of the model that are not in the desired set.
+	DeleteExtra bool
+
+	// Compute the change plan without changing the model.
+	DryRun bool
+
+	// The number of words sent in one AddWords request. Defaults to 1000.
+	BatchSize int
+}
+
+// WordSyncPlan : The changes that SyncCustomModelWords makes, or would make in dry-run mode, to a custom model
+type WordSyncPlan struct {
+	// The desired words that the model does not have.
+	Add []Word `json:"add,omitempty"`
+
+	// The desired words whose translation or part of speech differ in the model.
+	Update []Word `json:"update,omitempty"`
+
+	// The words of the model that are not in the desired set. Filled in only with DeleteExtra.
+	Delete []string `json:"delete,omitempty"`
+
+	// The number of desired words that the model already has as desired.
+	Unchanged int `json:"unchanged"`
+}
+
+// HasChanges : Reports whether the plan changes the model
+func (plan *WordSyncPlan) HasChanges() bool {
+	return len(plan.Add) > 0 || len(plan.Update) > 0 || len(plan.Delete) > 0
+}
+
+// SyncCustomModelWords : Makes the words of a custom model match desired, such as the words read from a PLS
+// lexicon with ReadPLS. The words of the model are listed with ListWords; words that are missing or whose
+// translation or part of speech differ are added in batches with AddWords, which replaces existing entries, and,
+// with DeleteExtra, words that are not desired are deleted with DeleteWord. Phonetic translations are compared by
+// alphabet and pronunciation, so differences in markup alone do not count as changes. The options may be nil.
+//
+// The plan is returned in all cases; in dry-run mode the model is not changed.
+func (textToSpeech *TextToSpeechV1) SyncCustomModelWords(ctx context.Context, customizationID string, desired []Word, syncOptions *SyncWordsOptions) (plan *WordSyncPlan, err error) {
+	if syncOptions == nil {
+		syncOptions = &SyncWordsOptions{}
+	}
+	desiredWords := make([]string, len(desired))
+	for i, word := range desired {
+		desiredWords[i] = core.StringNilMapper(word.Word)
+		if word.Word != nil && word.Translation == nil {
+			return nil, fmt.Errorf("word %q has no translation", *word.Word)
+		}
+	}
+
+	existing, _, err := textToSpeech.ListWordsWithContext(ctx, textToSpeech.NewListWordsOptions(customizationID))
+	if err != nil {
+		return nil, err
+	}
+	existingWords := make([]string, len(existing.Words))
+	for i, word := range existing.Words {
+		existingWords[i] = core.StringNilMapper(word.Word)
+	}
+	changes, err := wordsync.NewPlan(desiredWords, existingWords, func(desiredIndex int, existingIndex int) bool {
+		return wordMatches(desired[desiredIndex], &existing.Words[existingIndex])
+	}, syncOptions.DeleteExtra)
+	if err != nil {
+		return nil, err
+	}
+
+	plan = &WordSyncPlan{
+		Add:       pickWords(desired, changes.Add),
+		Update:    pickWords(desired, changes.Update),
+		Delete:    changes.Delete,
+		Unchanged: changes.Unchanged,
+	}
+	if syncOptions.DryRun {
+		return plan, nil
+	}
+	err = changes.Apply(syncOptions.BatchSize, func(batch []int) error {
+		_, err := textToSpeech.AddWordsWithContext(ctx, textToSpeech.NewAddWordsOptions(customizationID, pickWords(desired, batch)))
+		return err
+	}, func(word string) error {
+		_, err := textToSpeech.DeleteWordWithContext(ctx, textToSpeech.NewDeleteWordOptions(customizationID, word))
+		return err
+	})
+	return plan, err
+}
+
+// wordMatches : Reports whether the model's entry already has the translation and part of speech of desired
+func wordMatches(desired Word, current *Word) bool {
+	if core.StringNilMapper(desired.PartOfSpeech) != core.StringNilMapper(current.PartOfSpeech) {
+		return false
+	}
+	desiredTranslation := strings.TrimSpace(*desired.Translation)
+	currentTranslation := strings.TrimSpace(core.StringNilMapper(current.Translation))
+	desiredAlphabet, desiredPH, desiredPhonetic := ParsePhonemeTranslation(desiredTranslation)
+	currentAlphabet, currentPH, currentPhonetic := ParsePhonemeTranslation(currentTranslation)
+	if desiredPhonetic || currentPhonetic {
+		return desiredPhonetic == currentPhonetic && desiredAlphabet == currentAlphabet && desiredPH == currentPH
+	}
+	return desiredTranslation == currentTranslation
+}
+
+// pickWords : Returns the words at the indexes
+func pickWords(words []Word, indexes []int) []Word {
+	var picked []Word
+	for _, i := range indexes {
+		picked = append(picked, words[i])
+	}
+	return picked
+}
+
+var xmlAttributeEscaper = strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;", `"`, "&quot;")
+var xmlAttributeUnescaper = strings.NewReplacer("&lt;", "<", "&gt;", ">", "&quot;", `"`, "&apos;", "'", "&amp;", "&")
+
+func escapeXMLAttribute(value string) string {
+	return xmlAttributeEscaper.Replace(value)
+}
+
+func unescapeXMLAttribute(value string) string {
+	return xmlAttributeUnescaper.Replace(value)
+}
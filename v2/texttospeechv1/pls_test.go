@@ -0,0 +1,260 @@
+/**
+ * (C) Copyright IBM Corp. 2021.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package texttospeechv1_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+
+	"github.com/IBM/go-sdk-core/v5/core"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/watson-developer-cloud/go-sdk/v2/texttospeechv1"
+)
+
+const lexicon = `<?xml version="1.0" encoding="UTF-8"?>
+<lexicon version="1.0" xmlns="http://www.w3.org/2005/01/pronunciation-lexicon" alphabet="ipa" xml:lang="en-US">
+  <lexeme>
+    <grapheme>tomato</grapheme>
+    <grapheme>Tomato</grapheme>
+    <phoneme>təˈmɑtoʊ</phoneme>
+  </lexeme>
+  <lexeme>
+    <grapheme>IEEE</grapheme>
+    <alias>I triple E</alias>
+  </lexeme>
+  <lexeme>
+    <grapheme>GIF</grapheme>
+    <phoneme alphabet="x-ibm-spr">.1dZIf</phoneme>
+  </lexeme>
+  <lexeme role="noun">
+    <grapheme>IBM</grapheme>
+    <alias>I B M</alias>
+  </lexeme>
+</lexicon>`
+
+const japaneseLexicon = `<?xml version="1.0" encoding="UTF-8"?>
+<lexicon version="1.0" xmlns="http://www.w3.org/2005/01/pronunciation-lexicon" alphabet="ipa" xml:lang="ja-JP">
+  <lexeme role="Mesi">
+    <grapheme>IBM</grapheme>
+    <alias>アイビーエム</alias>
+  </lexeme>
+  <lexeme>
+    <grapheme>NHK</grapheme>
+    <alias>エヌエイチケー</alias>
+  </lexeme>
+</lexicon>`
+
+var _ = Describe(`PLS`, func() {
+	It(`Read a lexicon as words`, func() {
+		words, err := texttospeechv1.ReadPLS(strings.NewReader(lexicon))
+		Expect(err).To(BeNil())
+		Expect(words).To(Equal([]texttospeechv1.Word{
+			{Word: core.StringPtr("tomato"), Translation: core.StringPtr(`<phoneme alphabet="IPA" ph="təˈmɑtoʊ"></phoneme>`)},
+			{Word: core.StringPtr("Tomato"), Translation: core.StringPtr(`<phoneme alphabet="IPA" ph="təˈmɑtoʊ"></phoneme>`)},
+			{Word: core.StringPtr("IEEE"), Translation: core.StringPtr("I triple E")},
+			{Word: core.StringPtr("GIF"), Translation: core.StringPtr(`<phoneme alphabet="ibm" ph=".1dZIf"></phoneme>`)},
+			{Word: core.StringPtr("IBM"), Translation: core.StringPtr("I B M")},
+		}))
+	})
+
+	It(`Read the roles of Japanese lexicons as parts of speech`, func() {
+		words, err := texttospeechv1.ReadPLS(strings.NewReader(japaneseLexicon))
+		Expect(err).To(BeNil())
+		Expect(words).To(Equal([]texttospeechv1.Word{
+			{Word: core.StringPtr("IBM"), Translation: core.StringPtr("アイビーエム"), PartOfSpeech: core.StringPtr(texttospeechv1.WordPartOfSpeechMesiConst)},
+			{Word: core.StringPtr("NHK"), Translation: core.StringPtr("エヌエイチケー")},
+		}))
+
+		var output bytes.Buffer
+		Expect(texttospeechv1.WritePLS(&output, words, "ja-JP")).To(Succeed())
+		Expect(output.String()).To(ContainSubstring(`<lexeme role="Mesi">`))
+		again, err := texttospeechv1.ReadPLS(&output)
+		Expect(err).To(BeNil())
+		Expect(again).To(Equal(words))
+
+		_, err = texttospeechv1.ReadPLS(strings.NewReader(strings.Replace(japaneseLexicon, `role="Mesi"`, `role="noun"`, 1)))
+		Expect(err).ToNot(BeNil())
+	})
+
+	It(`Write words and read them back`, func() {
+		words, err := texttospeechv1.ReadPLS(strings.NewReader(lexicon))
+		Expect(err).To(BeNil())
+
+		var output bytes.Buffer
+		Expect(texttospeechv1.WritePLS(&output, words, "en-US")).To(Succeed())
+		Expect(output.String()).ToNot(ContainSubstring(`role=`))
+		Expect(output.String()).To(ContainSubstring(`<phoneme alphabet="ibm">.1dZIf</phoneme>`))
+		Expect(output.String()).To(ContainSubstring(`xml:lang="en-US"`))
+
+		again, err := texttospeechv1.ReadPLS(&output)
+		Expect(err).To(BeNil())
+		Expect(again).To(HaveLen(len(words)))
+		Expect(*again[0].Word).To(Equal("GIF"))
+		Expect(again).To(ConsistOf(words))
+	})
+
+	It(`Write parts of speech only for Japanese lexicons`, func() {
+		words := []texttospeechv1.Word{
+			{Word: core.StringPtr("IBM"), Translation: core.StringPtr("I B M"), PartOfSpeech: core.StringPtr(texttospeechv1.WordPartOfSpeechMesiConst)},
+		}
+		var output bytes.Buffer
+		Expect(texttospeechv1.WritePLS(&output, words, "en-US")).To(Succeed())
+		Expect(output.String()).ToNot(ContainSubstring(`role=`))
+		again, err := texttospeechv1.ReadPLS(&output)
+		Expect(err).To(BeNil())
+		Expect(again).To(Equal([]texttospeechv1.Word{{Word: core.StringPtr("IBM"), Translation: core.StringPtr("I B M")}}))
+
+		output.Reset()
+		Expect(texttospeechv1.WritePLS(&output, words, "ja-JP")).To(Succeed())
+		again, err = texttospeechv1.ReadPLS(&output)
+		Expect(err).To(BeNil())
+		Expect(again).To(Equal(words))
+	})
+
+	It(`Reject lexemes without a pronunciation or with an unknown alphabet`, func() {
+		_, err := texttospeechv1.ReadPLS(strings.NewReader(`<lexicon version="1.0"><lexeme><grapheme>a</grapheme></lexeme></lexicon>`))
+		Expect(err).ToNot(BeNil())
+		_, err = texttospeechv1.ReadPLS(strings.NewReader(`<lexicon version="1.0" alphabet="x-sampa"><lexeme><grapheme>a</grapheme><phoneme>a</phoneme></lexeme></lexicon>`))
+		Expect(err).ToNot(BeNil())
+	})
+
+	It(`Parse phonetic translations`, func() {
+		alphabet, ph, ok := texttospeechv1.ParsePhonemeTranslation(`<phoneme alphabet="IPA" ph="t&amp;"/>`)
+		Expect(ok).To(BeTrue())
+		Expect(alphabet).To(Equal("ipa"))
+		Expect(ph).To(Equal("t&"))
+		_, _, ok = texttospeechv1.ParsePhonemeTranslation("sounds like")
+		Expect(ok).To(BeFalse())
+	})
+})
+
+// wordsStandIn : A stand-in for the words of a custom model
+type wordsStandIn struct {
+	mutex   sync.Mutex
+	words   map[string]texttospeechv1.Word
+	adds    int
+	deletes []string
+}
+
+func (s *wordsStandIn) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	w.Header().Set("Content-Type", "application/json")
+	switch {
+	case r.Method == "GET" && r.URL.Path == "/v1/customizations/custom1/words":
+		var words texttospeechv1.Words
+		for _, word := range s.words {
+			words.Words = append(words.Words, word)
+		}
+		_ = json.NewEncoder(w).Encode(words)
+	case r.Method == "POST" && r.URL.Path == "/v1/customizations/custom1/words":
+		var words texttospeechv1.Words
+		_ = json.NewDecoder(r.Body).Decode(&words)
+		for _, word := range words.Words {
+			s.words[*word.Word] = word
+		}
+		s.adds++
+		w.WriteHeader(200)
+		_, _ = w.Write([]byte(`{}`))
+	case r.Method == "DELETE" && strings.HasPrefix(r.URL.Path, "/v1/customizations/custom1/words/"):
+		word := strings.TrimPrefix(r.URL.Path, "/v1/customizations/custom1/words/")
+		delete(s.words, word)
+		s.deletes = append(s.deletes, word)
+		w.WriteHeader(204)
+	default:
+		w.WriteHeader(404)
+		_, _ = w.Write([]byte(`{"error":"Not found","code":404}`))
+	}
+}
+
+var _ = Describe(`SyncCustomModelWords`, func() {
+	var standIn *wordsStandIn
+	var testServer *httptest.Server
+	var textToSpeechService *texttospeechv1.TextToSpeechV1
+	var desired []texttospeechv1.Word
+
+	BeforeEach(func() {
+		standIn = &wordsStandIn{words: map[string]texttospeechv1.Word{
+			"tomato": {Word: core.StringPtr("tomato"), Translation: core.StringPtr(`<phoneme alphabet="IPA" ph="təˈmɑtoʊ"/>`)},
+			"IEEE":   {Word: core.StringPtr("IEEE"), Translation: core.StringPtr("I E E E")},
+			"NCAA":   {Word: core.StringPtr("NCAA"), Translation: core.StringPtr("N C double A")},
+		}}
+		testServer = httptest.NewServer(standIn)
+		var serviceErr error
+		textToSpeechService, serviceErr = texttospeechv1.NewTextToSpeechV1(&texttospeechv1.TextToSpeechV1Options{
+			URL:           testServer.URL,
+			Authenticator: &core.NoAuthAuthenticator{},
+		})
+		Expect(serviceErr).To(BeNil())
+
+		var err error
+		desired, err = texttospeechv1.ReadPLS(strings.NewReader(lexicon))
+		Expect(err).To(BeNil())
+	})
+	AfterEach(func() {
+		testServer.Close()
+	})
+
+	It(`Invoke SyncCustomModelWords in dry-run mode`, func() {
+		plan, err := textToSpeechService.SyncCustomModelWords(context.Background(), "custom1", desired, &texttospeechv1.SyncWordsOptions{DeleteExtra: true, DryRun: true})
+		Expect(err).To(BeNil())
+		Expect(plan.HasChanges()).To(BeTrue())
+		Expect(plan.Unchanged).To(Equal(1))
+		Expect(plan.Add).To(HaveLen(3))
+		Expect(*plan.Add[0].Word).To(Equal("GIF"))
+		Expect(plan.Update).To(HaveLen(1))
+		Expect(*plan.Update[0].Word).To(Equal("IEEE"))
+		Expect(plan.Delete).To(Equal([]string{"NCAA"}))
+		Expect(standIn.adds).To(Equal(0))
+		Expect(standIn.words).To(HaveLen(3))
+	})
+
+	It(`Invoke SyncCustomModelWords in batches`, func() {
+		plan, err := textToSpeechService.SyncCustomModelWords(context.Background(), "custom1", desired, &texttospeechv1.SyncWordsOptions{DeleteExtra: true, BatchSize: 3})
+		Expect(err).To(BeNil())
+		Expect(plan.Delete).To(Equal([]string{"NCAA"}))
+		Expect(standIn.adds).To(Equal(2))
+		Expect(standIn.deletes).To(Equal([]string{"NCAA"}))
+		Expect(standIn.words).To(HaveLen(5))
+		Expect(*standIn.words["IEEE"].Translation).To(Equal("I triple E"))
+		Expect(*standIn.words["IBM"].Translation).To(Equal("I B M"))
+
+		plan, err = textToSpeechService.SyncCustomModelWords(context.Background(), "custom1", desired, nil)
+		Expect(err).To(BeNil())
+		Expect(plan.HasChanges()).To(BeFalse())
+		Expect(plan.Unchanged).To(Equal(5))
+	})
+
+	It(`Invoke SyncCustomModelWords without deleting extra words`, func() {
+		plan, err := textToSpeechService.SyncCustomModelWords(context.Background(), "custom1", desired, nil)
+		Expect(err).To(BeNil())
+		Expect(plan.Delete).To(BeEmpty())
+		Expect(standIn.words).To(HaveKey("NCAA"))
+	})
+
+	It(`Invoke SyncCustomModelWords with duplicate words`, func() {
+		_, err := textToSpeechService.SyncCustomModelWords(context.Background(), "custom1", append(desired, desired[0]), nil)
+		Expect(err).ToNot(BeNil())
+		Expect(standIn.adds).To(Equal(0))
+	})
+})
@@ -0,0 +1,311 @@
+/**
+ * (C) Copyright IBM Corp. 2021.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package ssml builds and validates SSML for the text of Text to Speech synthesis requests.
+//
+// A Builder produces a `<speak>` document from text and the SSML elements that the service supports, escaping
+// text and attribute values as it goes. Validate checks SSML, whether built or written by hand, against the
+// elements and attributes that a voice supports, as described by the Voice returned by GetVoice.
+package ssml
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/watson-developer-cloud/go-sdk/v2/texttospeechv1"
+)
+
+// Strengths of a `<break>`
+const (
+	BreakStrengthNoneConst    = "none"
+	BreakStrengthXWeakConst   = "x-weak"
+	BreakStrengthWeakConst    = "weak"
+	BreakStrengthMediumConst  = "medium"
+	BreakStrengthStrongConst  = "strong"
+	BreakStrengthXStrongConst = "x-strong"
+)
+
+// Alphabets of a `<phoneme>`
+const (
+	PhonemeAlphabetIBMConst = "ibm"
+	PhonemeAlphabetIPAConst = "ipa"
+)
+
+// Styles of an `<express-as>`
+const (
+	ExpressAsStyleCheerfulConst   = "cheerful"
+	ExpressAsStyleEmpatheticConst = "empathetic"
+	ExpressAsStyleNeutralConst    = "neutral"
+	ExpressAsStyleUncertainConst  = "uncertain"
+)
+
+// Prosody : The attributes of a `<prosody>` element. Empty attributes are omitted.
+type Prosody struct {
+	// The baseline pitch, such as `+10%`, `-2st`, `150Hz`, or one of `x-low`, `low`, `medium`, `high`, `x-high` and
+	// `default`.
+	Pitch string
+
+	// The speaking rate, such as `+20%`, a number of words per minute, or one of `x-slow`, `slow`, `medium`, `fast`,
+	// `x-fast` and `default`.
+	Rate string
+
+	// The volume, such as `+6dB`, or one of `silent`, `x-soft`, `soft`, `medium`, `loud`, `x-loud` and `default`.
+	// Not supported by neural voices.
+	Volume string
+}
+
+// Break : The attributes of a `<break>` element. Set either the strength or the time; a zero Break is a pause of
+// medium strength.
+type Break struct {
+	// The strength of the pause, such as `medium`.
+	Strength string
+
+	// The length of the pause.
+	Time time.Duration
+}
+
+// SayAs : The attributes of a `<say-as>` element
+type SayAs struct {
+	// How the text is to be interpreted, such as `digits`, `letters` or `date`.
+	InterpretAs string
+
+	// The format of the text, such as `mdy` for a date. Optional.
+	Format string
+}
+
+// Phoneme : The attributes of a `<phoneme>` element
+type Phoneme struct {
+	// The phonetic alphabet of the pronunciation: `ipa` or `ibm`. Defaults to `ipa`.
+	Alphabet string
+
+	// The pronunciation.
+	PH string
+}
+
+// ExpressAs : The attributes of an `<express-as>` element, which only expressive neural voices support
+type ExpressAs struct {
+	// The speaking style, such as `cheerful`.
+	Style string
+}
+
+// Builder : Builds an SSML document. The methods return the builder so that calls can be chained; the first
+// invalid argument is remembered and reported by Build.
+type Builder struct {
+	body  strings.Builder
+	marks []string
+	names map[string]bool
+	err   error
+
+	// root is the builder that owns the marks and the error, for the builders passed to nested content
+	root *Builder
+}
+
+// New : Instantiate a Builder for a `<speak>` document
+func New() *Builder {
+	builder := &Builder{names: map[string]bool{}}
+	builder.root = builder
+	return builder
+}
+
+// Text : Adds text, escaping the characters that have a meaning in SSML
+func (builder *Builder) Text(text string) *Builder {
+	builder.body.WriteString(Escape(text))
+	return builder
+}
+
+// Prosody : Adds a `<prosody>` element around the content added by content
+func (builder *Builder) Prosody(prosody Prosody, content func(*Builder)) *Builder {
+	if prosody.Pitch == "" && prosody.Rate == "" && prosody.Volume == "" {
+		builder.fail(fmt.Errorf("prosody needs a pitch, rate or volume"))
+	}
+	if content == nil {
+		content = func(*Builder) {}
+	}
+	return builder.element("prosody", []string{"pitch", prosody.Pitch, "rate", prosody.Rate, "volume", prosody.Volume}, content)
+}
+
+// Break : Adds a `<break>` element
+func (builder *Builder) Break(pause Break) *Builder {
+	var attributes []string
+	switch {
+	case pause.Strength != "" && pause.Time != 0:
+		builder.fail(fmt.Errorf("a break has either a strength or a time"))
+	case pause.Strength != "":
+		if !contains(breakStrengths, pause.Strength) {
+			builder.fail(fmt.Errorf("unknown break strength %q", pause.Strength))
+		}
+		attributes = []string{"strength", pause.Strength}
+	case pause.Time < 0:
+		builder.fail(fmt.Errorf("a break cannot have a negative time"))
+	case pause.Time > 0:
+		attributes = []string{"time", strconv.FormatInt(int64(pause.Time/time.Millisecond), 10) + "ms"}
+	}
+	return builder.element("break", attributes, nil)
+}
+
+// SayAs : Adds a `<say-as>` element around text
+func (builder *Builder) SayAs(sayAs SayAs, text string) *Builder {
+	if !contains(interpretAsValues, sayAs.InterpretAs) {
+		builder.fail(fmt.Errorf("unknown interpret-as %q", sayAs.InterpretAs))
+	}
+	return builder.element("say-as", []string{"interpret-as", sayAs.InterpretAs, "format", sayAs.Format}, textContent(text))
+}
+
+// Phoneme : Adds a `<phoneme>` element around text
+func (builder *Builder) Phoneme(phoneme Phoneme, text string) *Builder {
+	alphabet := phoneme.Alphabet
+	if alphabet == "" {
+		alphabet = PhonemeAlphabetIPAConst
+	}
+	if alphabet != PhonemeAlphabetIPAConst && alphabet != PhonemeAlphabetIBMConst {
+		builder.fail(fmt.Errorf("unknown phoneme alphabet %q", phoneme.Alphabet))
+	}
+	if phoneme.PH == "" {
+		builder.fail(fmt.Errorf("a phoneme needs a pronunciation"))
+	}
+	return builder.element("phoneme", []string{"alphabet", alphabet, "ph", phoneme.PH}, textContent(text))
+}
+
+// Sub : Adds a `<sub>` element that speaks alias in place of text
+func (builder *Builder) Sub(alias string, text string) *Builder {
+	if alias == "" {
+		builder.fail(fmt.Errorf("a sub needs an alias"))
+	}
+	return builder.element("sub", []string{"alias", alias}, textContent(text))
+}
+
+// Mark : Adds a `<mark>` element. The service reports the time of each mark to the websocket OnMarks callback
+// under the same name; names must be unique within a document.
+func (builder *Builder) Mark(name string) *Builder {
+	root := builder.root
+	switch {
+	case name == "":
+		builder.fail(fmt.Errorf("a mark needs a name"))
+	case root.names[name]:
+		builder.fail(fmt.Errorf("mark %q is used more than once", name))
+	default:
+		root.names[name] = true
+		root.marks = append(root.marks, name)
+	}
+	return builder.element("mark", []string{"name", name}, nil)
+}
+
+// ExpressAs : Adds an `<express-as>` element around the content added by content
+func (builder *Builder) ExpressAs(expressAs ExpressAs, content func(*Builder)) *Builder {
+	if !contains(expressAsStyles, expressAs.Style) {
+		builder.fail(fmt.Errorf("unknown express-as style %q", expressAs.Style))
+	}
+	if content == nil {
+		content = func(*Builder) {}
+	}
+	return builder.element("express-as", []string{"style", expressAs.Style}, content)
+}
+
+// Marks : Returns the names of the marks added so far, in document order
+func (builder *Builder) Marks() []string {
+	return append([]string(nil), builder.root.marks...)
+}
+
+// AlignMarks : Orders the mark times reported to the websocket OnMarks callback as the marks of the document, and
+// reports marks that the service did not return or that are not in the document
+func (builder *Builder) AlignMarks(timings []texttospeechv1.MarkTiming) ([]texttospeechv1.MarkTiming, error) {
+	byName := make(map[string]texttospeechv1.MarkTiming, len(timings))
+	for _, timing := range timings {
+		if !builder.root.names[timing.Name] {
+			return nil, fmt.Errorf("mark %q is not in the document", timing.Name)
+		}
+		byName[timing.Name] = timing
+	}
+	aligned := make([]texttospeechv1.MarkTiming, 0, len(builder.root.marks))
+	for _, name := range builder.root.marks {
+		timing, ok := byName[name]
+		if !ok {
+			return aligned, fmt.Errorf("no time was reported for mark %q", name)
+		}
+		aligned = append(aligned, timing)
+	}
+	return aligned, nil
+}
+
+// Build : Returns the `<speak>` document, or the first error of the builder
+func (builder *Builder) Build() (string, error) {
+	if builder.root.err != nil {
+		return "", builder.root.err
+	}
+	return builder.String(), nil
+}
+
+// String : Returns the `<speak>` document, even if the builder has an error
+func (builder *Builder) String() string {
+	return `<speak version="1.0">` + builder.body.String() + `</speak>`
+}
+
+// element : Writes an element with the non-empty attributes given as name, value pairs
+func (builder *Builder) element(name string, attributes []string, content func(*Builder)) *Builder {
+	builder.body.WriteString("<" + name)
+	for i := 0; i+1 < len(attributes); i += 2 {
+		if attributes[i+1] == "" {
+			continue
+		}
+		builder.body.WriteString(" " + attributes[i] + `="` + Escape(attributes[i+1]) + `"`)
+	}
+	if content == nil {
+		builder.body.WriteString("/>")
+		return builder
+	}
+	builder.body.WriteString(">")
+	nested := &Builder{root: builder.root}
+	content(nested)
+	builder.body.WriteString(nested.body.String())
+	builder.body.WriteString("</" + name + ">")
+	return builder
+}
+
+func (builder *Builder) fail(err error) {
+	if builder.root.err == nil {
+		builder.root.err = err
+	}
+}
+
+func textContent(text string) func(*Builder) {
+	return func(builder *Builder) {
+		builder.Text(text)
+	}
+}
+
+// Escape : Escapes the characters that have a meaning in SSML text and attribute values
+func Escape(text string) string {
+	return escaper.Replace(text)
+}
+
+var escaper = strings.NewReplacer(
+	"&", "&amp;",
+	"<", "&lt;",
+	">", "&gt;",
+	`"`, "&quot;",
+	"'", "&apos;",
+)
+
+func contains(values []string, value string) bool {
+	for _, candidate := range values {
+		if candidate == value {
+			return true
+		}
+	}
+	return false
+}
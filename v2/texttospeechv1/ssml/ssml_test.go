@@ -0,0 +1,142 @@
+/**
+ * (C) Copyright IBM Corp. 2021.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package ssml_test
+
+import (
+	"time"
+
+	"github.com/IBM/go-sdk-core/v5/core"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/watson-developer-cloud/go-sdk/v2/texttospeechv1"
+	"github.com/watson-developer-cloud/go-sdk/v2/texttospeechv1/ssml"
+)
+
+func voice(name string, voiceTransformation bool) *texttospeechv1.Voice {
+	return &texttospeechv1.Voice{
+		Name: core.StringPtr(name),
+		SupportedFeatures: &texttospeechv1.SupportedFeatures{
+			CustomPronunciation: core.BoolPtr(true),
+			VoiceTransformation: core.BoolPtr(voiceTransformation),
+		},
+	}
+}
+
+var _ = Describe(`Builder`, func() {
+	It(`Build a document with every element`, func() {
+		builder := ssml.New().
+			Text("Fish & <chips> ").
+			Prosody(ssml.Prosody{Rate: "slow", Pitch: "+10%"}, func(b *ssml.Builder) {
+				b.Text("slowly").Mark("after-slowly")
+			}).
+			Break(ssml.Break{Time: 1500 * time.Millisecond}).
+			Break(ssml.Break{Strength: ssml.BreakStrengthStrongConst}).
+			SayAs(ssml.SayAs{InterpretAs: "digits"}, "123").
+			Phoneme(ssml.Phoneme{PH: "təˈmɑtoʊ"}, "tomato").
+			Sub("World Wide Web Consortium", "W3C").
+			ExpressAs(ssml.ExpressAs{Style: ssml.ExpressAsStyleCheerfulConst}, func(b *ssml.Builder) {
+				b.Text("Great!").Mark(`"end"`)
+			})
+
+		text, err := builder.Build()
+		Expect(err).To(BeNil())
+		Expect(text).To(Equal(`<speak version="1.0">Fish &amp; &lt;chips&gt; ` +
+			`<prosody pitch="+10%" rate="slow">slowly<mark name="after-slowly"/></prosody>` +
+			`<break time="1500ms"/><break strength="strong"/>` +
+			`<say-as interpret-as="digits">123</say-as>` +
+			`<phoneme alphabet="ipa" ph="təˈmɑtoʊ">tomato</phoneme>` +
+			`<sub alias="World Wide Web Consortium">W3C</sub>` +
+			`<express-as style="cheerful">Great!<mark name="&quot;end&quot;"/></express-as></speak>`))
+		Expect(builder.Marks()).To(Equal([]string{"after-slowly", `"end"`}))
+		Expect(ssml.Validate(text, voice("en-US_AllisonExpressive", false))).To(Succeed())
+	})
+
+	It(`Report invalid arguments`, func() {
+		_, err := ssml.New().Mark("a").Mark("a").Build()
+		Expect(err).ToNot(BeNil())
+		_, err = ssml.New().Break(ssml.Break{Strength: "loud"}).Build()
+		Expect(err).ToNot(BeNil())
+		_, err = ssml.New().SayAs(ssml.SayAs{InterpretAs: "roman"}, "XIV").Build()
+		Expect(err).ToNot(BeNil())
+		_, err = ssml.New().ExpressAs(ssml.ExpressAs{Style: "angry"}, nil).Build()
+		Expect(err).ToNot(BeNil())
+	})
+
+	It(`Align mark times with the marks of the document`, func() {
+		builder := ssml.New().Mark("first").Text("Hello").Mark("second")
+		aligned, err := builder.AlignMarks([]texttospeechv1.MarkTiming{{Name: "second", Time: 0.4}, {Name: "first", Time: 0}})
+		Expect(err).To(BeNil())
+		Expect(aligned).To(Equal([]texttospeechv1.MarkTiming{{Name: "first", Time: 0}, {Name: "second", Time: 0.4}}))
+
+		_, err = builder.AlignMarks([]texttospeechv1.MarkTiming{{Name: "first", Time: 0}})
+		Expect(err).ToNot(BeNil())
+		_, err = builder.AlignMarks([]texttospeechv1.MarkTiming{{Name: "third", Time: 0}})
+		Expect(err).ToNot(BeNil())
+	})
+})
+
+var _ = Describe(`Validate`, func() {
+	It(`Accept plain text and fragments`, func() {
+		Expect(ssml.Validate("Hello world", nil)).To(Succeed())
+		Expect(ssml.Validate(`Hello <break time="2s"/> world`, voice("en-US_MichaelV3Voice", false))).To(Succeed())
+	})
+
+	It(`Report malformed markup`, func() {
+		err := ssml.Validate(`Hello <break time="2s"> world`, nil)
+		Expect(err).To(BeAssignableToTypeOf(&ssml.ValidationError{}))
+	})
+
+	It(`Report elements and attributes that a neural voice does not support`, func() {
+		text := `<speak><prosody volume="loud">Hi</prosody> <emphasis>there</emphasis> <express-as style="cheerful">!</express-as><voice-transformation type="Young">x</voice-transformation></speak>`
+		err := ssml.Validate(text, voice("en-US_MichaelV3Voice", false))
+		Expect(err).ToNot(BeNil())
+		issues := err.(*ssml.ValidationError).Issues
+		Expect(issues).To(HaveLen(4))
+		Expect(issues[0].Element).To(Equal("prosody"))
+		Expect(issues[0].Attribute).To(Equal("volume"))
+		Expect(issues[0].Offset).To(Equal(int64(7)))
+		Expect(issues[1].Element).To(Equal("emphasis"))
+		Expect(issues[2].Element).To(Equal("express-as"))
+		Expect(issues[3].Element).To(Equal("voice-transformation"))
+
+		Expect(ssml.Validate(text[:len(`<speak><prosody volume="loud">Hi</prosody> <emphasis>there</emphasis>`)]+`</speak>`, voice("en-US_AllisonVoice", true))).To(Succeed())
+	})
+
+	It(`Tell neural voices by name when the supported features are missing`, func() {
+		text := `<speak><prosody volume="loud">Hi</prosody> <voice-transformation type="Young">x</voice-transformation></speak>`
+		err := ssml.Validate(text, &texttospeechv1.Voice{Name: core.StringPtr("en-GB_CharlotteV3Voice")})
+		Expect(err).ToNot(BeNil())
+		issues := err.(*ssml.ValidationError).Issues
+		Expect(issues).To(HaveLen(2))
+		Expect(issues[0].Attribute).To(Equal("volume"))
+		Expect(issues[1].Element).To(Equal("voice-transformation"))
+
+		Expect(ssml.Validate(text, &texttospeechv1.Voice{Name: core.StringPtr("en-US_AllisonVoice")})).To(Succeed())
+		Expect(ssml.Validate(text+`<express-as style="cheerful">!</express-as><phoneme ph="x">y</phoneme>`, &texttospeechv1.Voice{})).To(Succeed())
+	})
+
+	It(`Report invalid attribute values`, func() {
+		err := ssml.Validate(`<break time="2 seconds"/><say-as>1</say-as><mark name="a"/><mark name="a"/><audio src="x"/>`, nil)
+		Expect(err).ToNot(BeNil())
+		issues := err.(*ssml.ValidationError).Issues
+		Expect(issues).To(HaveLen(4))
+		Expect(issues[0].Attribute).To(Equal("time"))
+		Expect(issues[1].Attribute).To(Equal("interpret-as"))
+		Expect(issues[2].Attribute).To(Equal("name"))
+		Expect(issues[3].Element).To(Equal("audio"))
+	})
+})
@@ -0,0 +1,252 @@
+/**
+ * (C) Copyright IBM Corp. 2021.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package ssml
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+
+	"github.com/IBM/go-sdk-core/v5/core"
+	"github.com/watson-developer-cloud/go-sdk/v2/texttospeechv1"
+)
+
+var breakStrengths = []string{
+	BreakStrengthNoneConst, BreakStrengthXWeakConst, BreakStrengthWeakConst,
+	BreakStrengthMediumConst, BreakStrengthStrongConst, BreakStrengthXStrongConst,
+}
+
+var interpretAsValues = []string{
+	"address", "cardinal", "characters", "date", "digits", "fraction", "interjection", "letters", "number",
+	"ordinal", "telephone", "time", "vxml:boolean", "vxml:currency", "vxml:date", "vxml:digits", "vxml:number",
+	"vxml:phone", "vxml:time",
+}
+
+var expressAsStyles = []string{
+	ExpressAsStyleCheerfulConst, ExpressAsStyleEmpatheticConst, ExpressAsStyleNeutralConst, ExpressAsStyleUncertainConst,
+}
+
+// elementAttributes lists the elements the service knows with their attributes
+var elementAttributes = map[string][]string{
+	"speak":                {"version"},
+	"p":                    nil,
+	"paragraph":            nil,
+	"s":                    nil,
+	"sentence":             nil,
+	"prosody":              {"pitch", "rate", "volume", "contour", "range", "duration"},
+	"break":                {"strength", "time"},
+	"say-as":               {"interpret-as", "format", "detail"},
+	"phoneme":              {"alphabet", "ph"},
+	"sub":                  {"alias"},
+	"mark":                 {"name"},
+	"express-as":           {"style"},
+	"emphasis":             {"level"},
+	"voice-transformation": {"type", "breathiness", "pitch", "pitch_range", "rate", "glottal_tension", "timbre", "timbre_extent", "strength"},
+}
+
+// neuralProsodyAttributes are the `<prosody>` attributes that neural voices support
+var neuralProsodyAttributes = []string{"pitch", "rate"}
+
+// requiredAttributes lists the attributes without which an element is rejected
+var requiredAttributes = map[string][]string{
+	"say-as":     {"interpret-as"},
+	"phoneme":    {"ph"},
+	"sub":        {"alias"},
+	"mark":       {"name"},
+	"express-as": {"style"},
+}
+
+var breakTimePattern = regexp.MustCompile(`^[0-9]+(\.[0-9]+)?(ms|s)$`)
+
+// Issue : A problem found by Validate
+type Issue struct {
+	// The byte offset in the text at which the problem was found.
+	Offset int64
+
+	// The element and attribute concerned; the attribute is empty for problems with the element.
+	Element   string
+	Attribute string
+
+	Message string
+}
+
+func (issue Issue) String() string {
+	return fmt.Sprintf("offset %d: %s", issue.Offset, issue.Message)
+}
+
+// ValidationError : The issues found by Validate
+type ValidationError struct {
+	Issues []Issue
+}
+
+func (e *ValidationError) Error() string {
+	messages := make([]string, len(e.Issues))
+	for i, issue := range e.Issues {
+		messages[i] = issue.String()
+	}
+	return "invalid SSML: " + strings.Join(messages, "; ")
+}
+
+// voiceSupport : What a voice supports beyond the elements that every voice supports
+type voiceSupport struct {
+	neural              bool
+	expressive          bool
+	voiceTransformation bool
+	customPronunciation bool
+}
+
+// supportOf : Derives what a voice supports from its name and, when GetVoice returned them, its supported features.
+// Neural voices are told apart by their name, which ends in `V3Voice` or has `Expressive` in it; a voice without a
+// name, or without supported features, is given the benefit of the doubt.
+func supportOf(voice *texttospeechv1.Voice) voiceSupport {
+	if voice == nil {
+		return voiceSupport{expressive: true, voiceTransformation: true, customPronunciation: true}
+	}
+	name := core.StringNilMapper(voice.Name)
+	support := voiceSupport{
+		neural:              strings.HasSuffix(name, "V3Voice") || strings.Contains(name, "Expressive"),
+		expressive:          name == "" || strings.Contains(name, "Expressive"),
+		customPronunciation: true,
+	}
+	// Voice transformation was only ever available for the standard, non-neural voices
+	support.voiceTransformation = !support.neural
+	if voice.SupportedFeatures != nil {
+		if voice.SupportedFeatures.VoiceTransformation != nil {
+			support.voiceTransformation = *voice.SupportedFeatures.VoiceTransformation
+		}
+		if voice.SupportedFeatures.CustomPronunciation != nil {
+			support.customPronunciation = *voice.SupportedFeatures.CustomPronunciation
+		}
+	}
+	return support
+}
+
+// Validate : Checks SSML, or plain text with SSML elements, for markup that the service rejects. With a voice, as
+// returned by GetVoice, the elements and attributes are also checked against what the voice supports:
+// `<voice-transformation>` needs the voice_transformation feature, `<phoneme>` the custom_pronunciation feature,
+// and `<express-as>` an expressive voice, while neural voices, whose names end in `V3Voice` or contain `Expressive`,
+// take only the pitch and rate of `<prosody>` and no `<emphasis>`. What the voice does not tell, such as the features
+// of a voice listed without them, is not checked. The voice may be nil. The issues found are returned as a
+// *ValidationError.
+func Validate(text string, voice *texttospeechv1.Voice) error {
+	const wrapper = "<ssml>"
+	support := supportOf(voice)
+	decoder := xml.NewDecoder(strings.NewReader(wrapper + text + "</ssml>"))
+	marks := map[string]bool{}
+	var issues []Issue
+
+	for {
+		offset := decoder.InputOffset() - int64(len(wrapper))
+		token, err := decoder.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			if offset < 0 {
+				offset = 0
+			}
+			issues = append(issues, Issue{Offset: offset, Message: err.Error()})
+			break
+		}
+		start, ok := token.(xml.StartElement)
+		if !ok || offset < 0 {
+			continue
+		}
+		issues = append(issues, validateElement(start, offset, support, marks)...)
+	}
+
+	if len(issues) > 0 {
+		return &ValidationError{Issues: issues}
+	}
+	return nil
+}
+
+func validateElement(start xml.StartElement, offset int64, support voiceSupport, marks map[string]bool) (issues []Issue) {
+	name := start.Name.Local
+	report := func(attribute string, format string, args ...interface{}) {
+		issues = append(issues, Issue{Offset: offset, Element: name, Attribute: attribute, Message: fmt.Sprintf(format, args...)})
+	}
+
+	allowed, known := elementAttributes[name]
+	switch {
+	case !known:
+		report("", "unsupported element <%s>", name)
+		return
+	case name == "voice-transformation" && !support.voiceTransformation:
+		report("", "the voice does not support <voice-transformation>")
+	case name == "phoneme" && !support.customPronunciation:
+		report("", "the voice does not support <phoneme>")
+	case name == "express-as" && !support.expressive:
+		report("", "only expressive voices support <express-as>")
+	case name == "emphasis" && support.neural:
+		report("", "neural voices do not support <emphasis>")
+	}
+
+	values := map[string]string{}
+	for _, attr := range start.Attr {
+		if attr.Name.Space != "" || attr.Name.Local == "xmlns" {
+			// Namespace declarations and xml:lang
+			continue
+		}
+		attribute := attr.Name.Local
+		values[attribute] = attr.Value
+		if !contains(allowed, attribute) {
+			report(attribute, "unsupported attribute %q of <%s>", attribute, name)
+			continue
+		}
+		if name == "prosody" && support.neural && !contains(neuralProsodyAttributes, attribute) {
+			report(attribute, "neural voices do not support the %q attribute of <prosody>", attribute)
+		}
+	}
+	for _, attribute := range requiredAttributes[name] {
+		if values[attribute] == "" {
+			report(attribute, "<%s> needs a %q attribute", name, attribute)
+		}
+	}
+
+	switch name {
+	case "break":
+		if strength, ok := values["strength"]; ok && !contains(breakStrengths, strength) {
+			report("strength", "unknown break strength %q", strength)
+		}
+		if time, ok := values["time"]; ok && !breakTimePattern.MatchString(time) {
+			report("time", "break time %q is not a number of seconds or milliseconds, such as 500ms", time)
+		}
+	case "say-as":
+		if interpretAs := values["interpret-as"]; interpretAs != "" && !contains(interpretAsValues, interpretAs) {
+			report("interpret-as", "unknown interpret-as %q", interpretAs)
+		}
+	case "phoneme":
+		if alphabet, ok := values["alphabet"]; ok && alphabet != PhonemeAlphabetIPAConst && alphabet != PhonemeAlphabetIBMConst {
+			report("alphabet", "unknown phoneme alphabet %q", alphabet)
+		}
+	case "express-as":
+		if style := values["style"]; style != "" && !contains(expressAsStyles, style) {
+			report("style", "unknown express-as style %q", style)
+		}
+	case "mark":
+		if markName := values["name"]; markName != "" {
+			if marks[markName] {
+				report("name", "mark %q is used more than once", markName)
+			}
+			marks[markName] = true
+		}
+	}
+	return
+}
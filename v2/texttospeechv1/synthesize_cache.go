@@ -0,0 +1,426 @@
+/**
+ * (C) Copyright IBM Corp. 2021.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package texttospeechv1
+
+import (
+	"bufio"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/IBM/go-sdk-core/v5/core"
+)
+
+// ErrCacheMiss is returned by a SynthesizeCacheStore that has no entry for a key
+var ErrCacheMiss = errors.New("synthesize cache miss")
+
+// SynthesizeCacheStore : The storage of a SynthesizeCache. Implementations must be safe for concurrent use.
+type SynthesizeCacheStore interface {
+	// Get opens the entry for the key, or returns ErrCacheMiss.
+	Get(key string) (io.ReadCloser, error)
+
+	// Put starts a new entry for the key. The entry becomes visible to Get only when it is committed.
+	Put(key string) (SynthesizeCacheEntry, error)
+
+	// Delete removes the entry for the key, if there is one.
+	Delete(key string) error
+}
+
+// SynthesizeCacheEntry : An entry being written to a SynthesizeCacheStore
+type SynthesizeCacheEntry interface {
+	io.Writer
+
+	// Commit stores the entry.
+	Commit() error
+
+	// Abort discards the entry.
+	Abort() error
+}
+
+// SynthesizeCache : A caching layer around Synthesize. Audio is cached under a key made of the service URL and all
+// the fields of the SynthesizeOptions, headers included, so requests that differ in any of them are cached apart.
+type SynthesizeCache struct {
+	service *TextToSpeechV1
+	store   SynthesizeCacheStore
+}
+
+// NewSynthesizeCache : Instantiate SynthesizeCache
+func NewSynthesizeCache(service *TextToSpeechV1, store SynthesizeCacheStore) *SynthesizeCache {
+	return &SynthesizeCache{service: service, store: store}
+}
+
+// Synthesize : Synthesize audio, from the cache when possible
+func (cache *SynthesizeCache) Synthesize(synthesizeOptions *SynthesizeOptions) (result io.ReadCloser, response *core.DetailedResponse, err error) {
+	return cache.SynthesizeWithContext(context.Background(), synthesizeOptions)
+}
+
+// SynthesizeWithContext : Synthesize audio, from the cache when possible. A hit is answered from the store without a
+// request to the service, with a response whose status code is 200 and whose Content-Type header is that of the
+// cached audio. On a miss, the audio is streamed to the caller as it arrives and written to the store at the same
+// time; the entry is committed only when the caller has read the audio to the end, so closing the result early or a
+// failed read leaves nothing in the cache. Errors of the store never fail the request: the service is used instead.
+func (cache *SynthesizeCache) SynthesizeWithContext(ctx context.Context, synthesizeOptions *SynthesizeOptions) (result io.ReadCloser, response *core.DetailedResponse, err error) {
+	err = core.ValidateNotNil(synthesizeOptions, "synthesizeOptions cannot be nil")
+	if err != nil {
+		return
+	}
+	err = core.ValidateStruct(synthesizeOptions, "synthesizeOptions")
+	if err != nil {
+		return
+	}
+
+	key, err := SynthesizeCacheKey(cache.service.GetServiceURL(), synthesizeOptions)
+	if err != nil {
+		return
+	}
+	if result, response = cache.get(key); result != nil {
+		return
+	}
+
+	result, response, err = cache.service.SynthesizeWithContext(ctx, synthesizeOptions)
+	if err != nil || result == nil || response.StatusCode != http.StatusOK {
+		return
+	}
+	entry, putErr := cache.store.Put(key)
+	if putErr != nil {
+		return
+	}
+	if _, putErr = io.WriteString(entry, response.Headers.Get("Content-Type")+"\n"); putErr != nil {
+		_ = entry.Abort()
+		return
+	}
+	result = &cachingReader{body: result, entry: entry}
+	return
+}
+
+// get : Opens the entry for the key, skipping its content type line
+func (cache *SynthesizeCache) get(key string) (io.ReadCloser, *core.DetailedResponse) {
+	cached, err := cache.store.Get(key)
+	if err != nil {
+		return nil, nil
+	}
+	reader := bufio.NewReader(cached)
+	contentType, err := reader.ReadString('\n')
+	if err != nil {
+		// A damaged entry is dropped and synthesized again
+		cached.Close()
+		_ = cache.store.Delete(key)
+		return nil, nil
+	}
+	headers := http.Header{}
+	if contentType = strings.TrimSuffix(contentType, "\n"); contentType != "" {
+		headers.Set("Content-Type", contentType)
+	}
+	result := &cachedReader{Reader: reader, Closer: cached}
+	return result, &core.DetailedResponse{StatusCode: http.StatusOK, Headers: headers, Result: result}
+}
+
+// Invalidate : Removes the cached audio for the options
+func (cache *SynthesizeCache) Invalidate(synthesizeOptions *SynthesizeOptions) error {
+	key, err := SynthesizeCacheKey(cache.service.GetServiceURL(), synthesizeOptions)
+	if err != nil {
+		return err
+	}
+	return cache.store.Delete(key)
+}
+
+// SynthesizeCacheKey : Returns the cache key of a synthesize request: a hex SHA-256 digest of the service URL and of
+// every field of the options
+func SynthesizeCacheKey(serviceURL string, synthesizeOptions *SynthesizeOptions) (string, error) {
+	if synthesizeOptions == nil {
+		return "", fmt.Errorf("synthesizeOptions cannot be nil")
+	}
+	// Every field counts, so that fields added to SynthesizeOptions are part of the key without changes here;
+	// the JSON encoding of maps sorts the keys, which makes the encoding deterministic.
+	fields := map[string]interface{}{"url": serviceURL}
+	value := reflect.ValueOf(*synthesizeOptions)
+	for i := 0; i < value.NumField(); i++ {
+		fields[value.Type().Field(i).Name] = value.Field(i).Interface()
+	}
+	encoded, err := json.Marshal(fields)
+	if err != nil {
+		return "", err
+	}
+	digest := sha256.Sum256(encoded)
+	return hex.EncodeToString(digest[:]), nil
+}
+
+type cachedReader struct {
+	io.Reader
+	io.Closer
+}
+
+// cachingReader : Passes the audio to the caller while writing it to a cache entry
+type cachingReader struct {
+	body  io.ReadCloser
+	entry SynthesizeCacheEntry
+	done  bool
+}
+
+func (reader *cachingReader) Read(p []byte) (n int, err error) {
+	n, err = reader.body.Read(p)
+	if n > 0 && !reader.done {
+		if _, writeErr := reader.entry.Write(p[:n]); writeErr != nil {
+			reader.finish(false)
+		}
+	}
+	if err != nil {
+		reader.finish(err == io.EOF)
+	}
+	return
+}
+
+func (reader *cachingReader) Close() error {
+	reader.finish(false)
+	return reader.body.Close()
+}
+
+func (reader *cachingReader) finish(complete bool) {
+	if reader.done {
+		return
+	}
+	reader.done = true
+	if complete {
+		_ = reader.entry.Commit()
+	} else {
+		_ = reader.entry.Abort()
+	}
+}
+
+// FileCacheStore : A SynthesizeCacheStore that keeps one file per entry in a directory. Entries older than the TTL
+// are misses, and when the entries take up more than the maximum size the least recently used are removed.
+type FileCacheStore struct {
+	dir     string
+	maxSize int64
+	ttl     time.Duration
+
+	mutex   sync.Mutex
+	entries map[string]*fileCacheEntry
+	size    int64
+}
+
+type fileCacheEntry struct {
+	size     int64
+	created  time.Time
+	lastUsed time.Time
+}
+
+const fileCacheTempPattern = ".tmp-*"
+
+// NewFileCacheStore : Instantiate FileCacheStore on a directory, which is created if needed. Entries already in the
+// directory are kept. A maxSize or ttl of zero means no limit.
+func NewFileCacheStore(dir string, maxSize int64, ttl time.Duration) (*FileCacheStore, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+	files, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	store := &FileCacheStore{dir: dir, maxSize: maxSize, ttl: ttl, entries: map[string]*fileCacheEntry{}}
+	for _, file := range files {
+		if file.IsDir() {
+			continue
+		}
+		if strings.HasPrefix(file.Name(), ".tmp-") {
+			// Left behind by a process that stopped while writing
+			_ = os.Remove(filepath.Join(dir, file.Name()))
+			continue
+		}
+		store.entries[file.Name()] = &fileCacheEntry{size: file.Size(), created: file.ModTime(), lastUsed: file.ModTime()}
+		store.size += file.Size()
+	}
+	store.mutex.Lock()
+	store.evict("")
+	store.mutex.Unlock()
+	return store, nil
+}
+
+// Size : Returns the total size of the entries in bytes
+func (store *FileCacheStore) Size() int64 {
+	store.mutex.Lock()
+	defer store.mutex.Unlock()
+	return store.size
+}
+
+// Get : Opens the entry for the key
+func (store *FileCacheStore) Get(key string) (io.ReadCloser, error) {
+	if err := checkCacheKey(key); err != nil {
+		return nil, err
+	}
+	store.mutex.Lock()
+	defer store.mutex.Unlock()
+
+	entry, ok := store.entries[key]
+	if !ok {
+		return nil, ErrCacheMiss
+	}
+	if store.ttl > 0 && time.Since(entry.created) > store.ttl {
+		store.remove(key)
+		return nil, ErrCacheMiss
+	}
+	file, err := os.Open(store.path(key))
+	if os.IsNotExist(err) {
+		store.forget(key)
+		return nil, ErrCacheMiss
+	}
+	if err != nil {
+		return nil, err
+	}
+	entry.lastUsed = time.Now()
+	return file, nil
+}
+
+// Put : Starts an entry for the key in a temporary file, which is renamed into place on commit
+func (store *FileCacheStore) Put(key string) (SynthesizeCacheEntry, error) {
+	if err := checkCacheKey(key); err != nil {
+		return nil, err
+	}
+	file, err := ioutil.TempFile(store.dir, fileCacheTempPattern)
+	if err != nil {
+		return nil, err
+	}
+	return &fileCacheWriter{store: store, key: key, file: file}, nil
+}
+
+// Delete : Removes the entry for the key
+func (store *FileCacheStore) Delete(key string) error {
+	if err := checkCacheKey(key); err != nil {
+		return err
+	}
+	store.mutex.Lock()
+	defer store.mutex.Unlock()
+	store.forget(key)
+	if err := os.Remove(store.path(key)); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
+func (store *FileCacheStore) path(key string) string {
+	return filepath.Join(store.dir, key)
+}
+
+// commit : Moves a written entry into place and makes room for it
+func (store *FileCacheStore) commit(key string, tempPath string, size int64) error {
+	store.mutex.Lock()
+	defer store.mutex.Unlock()
+	if err := os.Rename(tempPath, store.path(key)); err != nil {
+		_ = os.Remove(tempPath)
+		return err
+	}
+	store.forget(key)
+	now := time.Now()
+	store.entries[key] = &fileCacheEntry{size: size, created: now, lastUsed: now}
+	store.size += size
+	store.evict(key)
+	return nil
+}
+
+// evict : Removes the least recently used entries until the store fits its maximum size, keeping the entry for
+// keep unless it does not fit on its own. The caller holds the mutex.
+func (store *FileCacheStore) evict(keep string) {
+	if store.maxSize <= 0 || store.size <= store.maxSize {
+		return
+	}
+	keys := make([]string, 0, len(store.entries))
+	for key := range store.entries {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i] == keep || keys[j] == keep {
+			return keys[j] == keep
+		}
+		return store.entries[keys[i]].lastUsed.Before(store.entries[keys[j]].lastUsed)
+	})
+	for _, key := range keys {
+		if store.size <= store.maxSize {
+			break
+		}
+		store.remove(key)
+	}
+}
+
+// remove : Deletes the entry's file. The caller holds the mutex.
+func (store *FileCacheStore) remove(key string) {
+	store.forget(key)
+	_ = os.Remove(store.path(key))
+}
+
+// forget : Drops the entry from the index. The caller holds the mutex.
+func (store *FileCacheStore) forget(key string) {
+	if entry, ok := store.entries[key]; ok {
+		store.size -= entry.size
+		delete(store.entries, key)
+	}
+}
+
+// checkCacheKey : Rejects keys that are not plain file names
+func checkCacheKey(key string) error {
+	if key == "" || strings.ContainsAny(key, `/\`) || strings.HasPrefix(key, ".") {
+		return fmt.Errorf("invalid cache key %q", key)
+	}
+	return nil
+}
+
+type fileCacheWriter struct {
+	store *FileCacheStore
+	key   string
+	file  *os.File
+	size  int64
+	done  bool
+}
+
+func (writer *fileCacheWriter) Write(p []byte) (int, error) {
+	n, err := writer.file.Write(p)
+	writer.size += int64(n)
+	return n, err
+}
+
+func (writer *fileCacheWriter) Commit() error {
+	if writer.done {
+		return fmt.Errorf("cache entry already finished")
+	}
+	writer.done = true
+	if err := writer.file.Close(); err != nil {
+		_ = os.Remove(writer.file.Name())
+		return err
+	}
+	return writer.store.commit(writer.key, writer.file.Name(), writer.size)
+}
+
+func (writer *fileCacheWriter) Abort() error {
+	if writer.done {
+		return nil
+	}
+	writer.done = true
+	writer.file.Close()
+	return os.Remove(writer.file.Name())
+}
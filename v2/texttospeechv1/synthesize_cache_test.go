@@ -0,0 +1,180 @@
+/**
+ * (C) Copyright IBM Corp. 2021.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package texttospeechv1_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/IBM/go-sdk-core/v5/core"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/watson-developer-cloud/go-sdk/v2/texttospeechv1"
+)
+
+var _ = Describe(`SynthesizeCache`, func() {
+	var requests int32
+	var testServer *httptest.Server
+	var textToSpeechService *texttospeechv1.TextToSpeechV1
+	var dir string
+
+	BeforeEach(func() {
+		atomic.StoreInt32(&requests, 0)
+		testServer = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			atomic.AddInt32(&requests, 1)
+			var request struct {
+				Text string `json:"text"`
+			}
+			_ = json.NewDecoder(r.Body).Decode(&request)
+			if request.Text == "fail" {
+				w.Header().Set("Content-Type", "application/json")
+				w.WriteHeader(400)
+				fmt.Fprint(w, `{"error":"Invalid text","code":400}`)
+				return
+			}
+			w.Header().Set("Content-Type", r.Header.Get("Accept"))
+			w.WriteHeader(200)
+			fmt.Fprintf(w, "%s|%s|%s", r.URL.Query().Get("voice"), r.Header.Get("Accept"), request.Text)
+		}))
+		var serviceErr error
+		textToSpeechService, serviceErr = texttospeechv1.NewTextToSpeechV1(&texttospeechv1.TextToSpeechV1Options{
+			URL:           testServer.URL,
+			Authenticator: &core.NoAuthAuthenticator{},
+		})
+		Expect(serviceErr).To(BeNil())
+		dir, serviceErr = ioutil.TempDir("", "synthesize-cache")
+		Expect(serviceErr).To(BeNil())
+	})
+	AfterEach(func() {
+		testServer.Close()
+		os.RemoveAll(dir)
+	})
+
+	synthesize := func(cache *texttospeechv1.SynthesizeCache, options *texttospeechv1.SynthesizeOptions) (string, *core.DetailedResponse) {
+		result, response, err := cache.Synthesize(options)
+		Expect(err).To(BeNil())
+		audio, err := ioutil.ReadAll(result)
+		Expect(err).To(BeNil())
+		Expect(result.Close()).To(Succeed())
+		return string(audio), response
+	}
+
+	It(`Answer repeated requests from the cache`, func() {
+		store, err := texttospeechv1.NewFileCacheStore(dir, 0, 0)
+		Expect(err).To(BeNil())
+		cache := texttospeechv1.NewSynthesizeCache(textToSpeechService, store)
+		options := textToSpeechService.NewSynthesizeOptions("Welcome").
+			SetAccept("audio/wav").
+			SetVoice(texttospeechv1.SynthesizeOptionsVoiceEnUsAllisonv3voiceConst)
+
+		audio, _ := synthesize(cache, options)
+		Expect(audio).To(Equal("en-US_AllisonV3Voice|audio/wav|Welcome"))
+		audio, response := synthesize(cache, options)
+		Expect(audio).To(Equal("en-US_AllisonV3Voice|audio/wav|Welcome"))
+		Expect(response.StatusCode).To(Equal(200))
+		Expect(response.Headers.Get("Content-Type")).To(Equal("audio/wav"))
+		Expect(atomic.LoadInt32(&requests)).To(Equal(int32(1)))
+
+		// Any other option is another entry
+		audio, _ = synthesize(cache, textToSpeechService.NewSynthesizeOptions("Welcome").SetAccept("audio/mp3").
+			SetVoice(texttospeechv1.SynthesizeOptionsVoiceEnUsAllisonv3voiceConst))
+		Expect(audio).To(Equal("en-US_AllisonV3Voice|audio/mp3|Welcome"))
+		Expect(atomic.LoadInt32(&requests)).To(Equal(int32(2)))
+
+		// Entries outlive the store
+		store, err = texttospeechv1.NewFileCacheStore(dir, 0, 0)
+		Expect(err).To(BeNil())
+		cache = texttospeechv1.NewSynthesizeCache(textToSpeechService, store)
+		synthesize(cache, options)
+		Expect(atomic.LoadInt32(&requests)).To(Equal(int32(2)))
+
+		Expect(cache.Invalidate(options)).To(Succeed())
+		synthesize(cache, options)
+		Expect(atomic.LoadInt32(&requests)).To(Equal(int32(3)))
+	})
+
+	It(`Cache only audio that was read to the end`, func() {
+		store, err := texttospeechv1.NewFileCacheStore(dir, 0, 0)
+		Expect(err).To(BeNil())
+		cache := texttospeechv1.NewSynthesizeCache(textToSpeechService, store)
+		options := textToSpeechService.NewSynthesizeOptions("Hello there")
+
+		result, _, err := cache.Synthesize(options)
+		Expect(err).To(BeNil())
+		_, err = result.Read(make([]byte, 4))
+		Expect(err).To(BeNil())
+		Expect(result.Close()).To(Succeed())
+		Expect(store.Size()).To(Equal(int64(0)))
+
+		_, _, err = cache.Synthesize(textToSpeechService.NewSynthesizeOptions("fail"))
+		Expect(err).ToNot(BeNil())
+		Expect(store.Size()).To(Equal(int64(0)))
+
+		files, err := ioutil.ReadDir(dir)
+		Expect(err).To(BeNil())
+		Expect(files).To(BeEmpty())
+	})
+
+	It(`Expire entries after the TTL`, func() {
+		store, err := texttospeechv1.NewFileCacheStore(dir, 0, 50*time.Millisecond)
+		Expect(err).To(BeNil())
+		cache := texttospeechv1.NewSynthesizeCache(textToSpeechService, store)
+		options := textToSpeechService.NewSynthesizeOptions("Hello")
+
+		synthesize(cache, options)
+		synthesize(cache, options)
+		Expect(atomic.LoadInt32(&requests)).To(Equal(int32(1)))
+		time.Sleep(100 * time.Millisecond)
+		synthesize(cache, options)
+		Expect(atomic.LoadInt32(&requests)).To(Equal(int32(2)))
+	})
+
+	It(`Evict the least recently used entries`, func() {
+		// Each entry is the content type line and 16 bytes of audio
+		entrySize := int64(len("audio/basic\n") + len("|audio/basic|") + 3)
+		store, err := texttospeechv1.NewFileCacheStore(dir, 2*entrySize, 0)
+		Expect(err).To(BeNil())
+		cache := texttospeechv1.NewSynthesizeCache(textToSpeechService, store)
+
+		synthesize(cache, textToSpeechService.NewSynthesizeOptions("one"))
+		time.Sleep(10 * time.Millisecond)
+		synthesize(cache, textToSpeechService.NewSynthesizeOptions("two"))
+		time.Sleep(10 * time.Millisecond)
+		synthesize(cache, textToSpeechService.NewSynthesizeOptions("one"))
+		Expect(atomic.LoadInt32(&requests)).To(Equal(int32(2)))
+		time.Sleep(10 * time.Millisecond)
+
+		synthesize(cache, textToSpeechService.NewSynthesizeOptions("six"))
+		Expect(store.Size()).To(Equal(2 * entrySize))
+		synthesize(cache, textToSpeechService.NewSynthesizeOptions("one"))
+		Expect(atomic.LoadInt32(&requests)).To(Equal(int32(3)))
+		synthesize(cache, textToSpeechService.NewSynthesizeOptions("two"))
+		Expect(atomic.LoadInt32(&requests)).To(Equal(int32(4)))
+
+		_, err = store.Get("../escape")
+		Expect(err).ToNot(BeNil())
+		_, err = store.Get(strings.Repeat("0", 64))
+		Expect(err).To(Equal(texttospeechv1.ErrCacheMiss))
+	})
+})
@@ -18,10 +18,13 @@ package texttospeechv1
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io/ioutil"
 	"net/http"
+	"sync"
 
 	"github.com/IBM/go-sdk-core/v5/core"
 	"github.com/gorilla/websocket"
@@ -34,33 +37,52 @@ const (
 type SynthesizeListener struct {
 	IsClosed chan bool
 	Callback SynthesizeCallbackWrapper
+
+	ctx   context.Context
+	state *listenerState
+}
+
+// listenerState : Records the first error reported during a synthesis
+type listenerState struct {
+	mutex sync.Mutex
+	err   error
 }
 
 func decode(b []byte, target interface{}) {
 	_ = json.NewDecoder(bytes.NewReader(b)).Decode(&target)
 }
 
-// OnError: Callback when error encountered
+// OnError: Callback when error encountered. Only the first error of a synthesis is passed to the callback.
 func (listener SynthesizeListener) OnError(err error) {
+	if listener.state != nil {
+		listener.state.mutex.Lock()
+		first := listener.state.err == nil
+		if first {
+			listener.state.err = err
+		}
+		listener.state.mutex.Unlock()
+		if !first {
+			return
+		}
+	}
 	listener.Callback.OnError(err)
 }
 
 // SendText: Sends the text message
 // Note: The service handles one request per connection
 func (listener SynthesizeListener) SendText(conn *websocket.Conn, req *http.Request) {
-	listener.OnOpen(conn)
-
 	body, err := ioutil.ReadAll(req.Body)
 	if err != nil {
 		listener.OnError(err)
+		return
 	}
 	err = conn.WriteMessage(websocket.TextMessage, body)
-	if err != nil {
+	if err != nil && !listener.cancelled() {
 		listener.OnError(err)
 	}
 }
 
-// OnOpen: Sends start message to server when connection created
+// OnOpen: Callback when connection created
 func (listener SynthesizeListener) OnOpen(conn *websocket.Conn) {
 	listener.Callback.OnOpen()
 }
@@ -71,69 +93,177 @@ func (listener SynthesizeListener) OnClose() {
 	listener.Callback.OnClose()
 }
 
-// OnData: Callback when websocket connection receives data
+// OnData: Callback when websocket connection receives data. Audio, timings and marks that arrive before the audio
+// content type are held back until it has been delivered, or until the synthesis ends without one, and the connection
+// is closed once the service ends the synthesis or reports an error.
 func (listener SynthesizeListener) OnData(conn *websocket.Conn) {
+	defer func() {
+		conn.Close()
+		listener.IsClosed <- true
+	}()
+
+	contentTypeSent := false
+	var held []func()
+	deliver := func(event func()) {
+		if contentTypeSent {
+			event()
+		} else {
+			held = append(held, event)
+		}
+	}
+	// Whatever is still held when the synthesis ends is delivered anyway, before any error, so that no audio is lost
+	// and OnError stays the last event before OnClose
+	flush := func() {
+		for _, event := range held {
+			event()
+		}
+		held = nil
+	}
+	defer flush()
+	fail := func(err error) {
+		flush()
+		listener.OnError(err)
+	}
+
 	for {
 		messageType, result, err := conn.ReadMessage()
 
 		// The service will close the connection. We need to decipher
 		// if the error is a normal close signal
 		if err != nil {
-			listener.IsClosed <- true
-
-			if !websocket.IsCloseError(err, websocket.CloseNormalClosure) {
-				listener.OnError(err)
-				conn.Close()
+			if !listener.cancelled() && !websocket.IsCloseError(err, websocket.CloseNormalClosure) {
+				fail(err)
 			}
-			break
+			return
 		}
 
 		if messageType == websocket.TextMessage {
 			var r map[string]interface{}
 			err = json.NewDecoder(bytes.NewReader(result)).Decode(&r)
 			if err != nil {
-				listener.OnError(fmt.Errorf(err.Error()))
-				listener.IsClosed <- true
-				break
+				fail(err)
+				return
 			}
-			if err, ok := r["error"]; ok {
-				listener.OnError(fmt.Errorf(err.(string)))
-				listener.IsClosed <- true
-				break
+			if serviceErr, ok := r["error"]; ok {
+				fail(errors.New(fmt.Sprint(serviceErr)))
+				return
 			}
 
 			if _, ok := r["binary_streams"]; ok {
 				audioContentTypeWrapper := new(AudioContentTypeWrapper)
 				decode(result, audioContentTypeWrapper)
-				listener.Callback.OnContentType(audioContentTypeWrapper.BinaryStreams[0].ContentType)
+				if len(audioContentTypeWrapper.BinaryStreams) > 0 && !contentTypeSent {
+					listener.Callback.OnContentType(audioContentTypeWrapper.BinaryStreams[0].ContentType)
+					contentTypeSent = true
+					flush()
+				}
 			} else if _, ok := r["words"]; ok {
 				timings := new(Timings)
 				decode(result, timings)
-				listener.Callback.OnTimingInformation(*timings)
+				var wordTimings []WordTiming
+				if timingsCallback, ok := listener.Callback.(SynthesizeTimingsCallback); ok {
+					if wordTimings, err = timings.WordTimings(); err != nil {
+						listener.OnError(err)
+						return
+					}
+					deliver(func() {
+						listener.Callback.OnTimingInformation(*timings)
+						timingsCallback.OnWordTimings(wordTimings)
+					})
+				} else {
+					deliver(func() { listener.Callback.OnTimingInformation(*timings) })
+				}
 			} else if _, ok := r["marks"]; ok {
 				marks := new(Marks)
 				decode(result, marks)
-				listener.Callback.OnMarks(*marks)
+				var markTimings []MarkTiming
+				if timingsCallback, ok := listener.Callback.(SynthesizeTimingsCallback); ok {
+					if markTimings, err = marks.MarkTimings(); err != nil {
+						listener.OnError(err)
+						return
+					}
+					deliver(func() {
+						listener.Callback.OnMarks(*marks)
+						timingsCallback.OnMarkTimings(markTimings)
+					})
+				} else {
+					deliver(func() { listener.Callback.OnMarks(*marks) })
+				}
 			}
 		} else if messageType == websocket.BinaryMessage {
-			listener.Callback.OnAudioStream(result)
+			audio := result
+			deliver(func() { listener.Callback.OnAudioStream(audio) })
 		}
 
 		detailResponse := core.DetailedResponse{}
 		detailResponse.Result = result
 		detailResponse.StatusCode = SUCCESS
-		listener.Callback.OnData(&detailResponse)
+		deliver(func() { listener.Callback.OnData(&detailResponse) })
+	}
+}
+
+// cancelled : Reports whether the synthesis context has been cancelled
+func (listener SynthesizeListener) cancelled() bool {
+	return listener.ctx != nil && listener.ctx.Err() != nil
+}
+
+// err : Returns the error that ended the synthesis, if any
+func (listener SynthesizeListener) err() error {
+	if listener.state != nil {
+		listener.state.mutex.Lock()
+		defer listener.state.mutex.Unlock()
+		if listener.state.err != nil {
+			return listener.state.err
+		}
+	}
+	if listener.cancelled() {
+		return listener.ctx.Err()
 	}
+	return nil
 }
 
-func (textToSpeechV1 *TextToSpeechV1) NewSynthesizeListener(callback SynthesizeCallbackWrapper, req *http.Request) {
-	synthesizeListener := SynthesizeListener{Callback: callback, IsClosed: make(chan bool, 1)}
-	conn, _, err := websocket.DefaultDialer.Dial(req.URL.String(), req.Header)
+// NewSynthesizeListener : Runs a synthesis over a websocket connection until the connection is closed
+func (textToSpeechV1 *TextToSpeechV1) NewSynthesizeListener(callback SynthesizeCallbackWrapper, req *http.Request) error {
+	return textToSpeechV1.NewSynthesizeListenerWithContext(context.Background(), callback, req)
+}
+
+// NewSynthesizeListenerWithContext : Runs a synthesis bound to ctx over a websocket connection until the connection
+// is closed. A dial failure is returned without invoking the callback. Otherwise the callback sees OnOpen, then the
+// content type, then the audio, timings and marks, and finally OnClose, all on the calling goroutine, and the first
+// error of the synthesis (or the context error) is returned.
+func (textToSpeechV1 *TextToSpeechV1) NewSynthesizeListenerWithContext(ctx context.Context, callback SynthesizeCallbackWrapper, req *http.Request) error {
+	conn, _, err := websocket.DefaultDialer.DialContext(ctx, req.URL.String(), req.Header)
 	if err != nil {
-		synthesizeListener.OnError(err)
+		return err
+	}
+
+	synthesizeListener := SynthesizeListener{
+		Callback: callback,
+		IsClosed: make(chan bool, 1),
+		ctx:      ctx,
+		state:    &listenerState{},
 	}
 
-	go synthesizeListener.OnData(conn)
-	go synthesizeListener.SendText(conn, req)
+	// Close the connection if the context is cancelled before the synthesis ends,
+	// which unblocks the reader and lets the synthesis wind down.
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		select {
+		case <-ctx.Done():
+			conn.Close()
+		case <-done:
+		}
+	}()
+
+	synthesizeListener.OnOpen(conn)
+	synthesizeListener.SendText(conn, req)
+	if synthesizeListener.err() != nil {
+		conn.Close()
+		synthesizeListener.IsClosed <- true
+	} else {
+		synthesizeListener.OnData(conn)
+	}
 	synthesizeListener.OnClose()
+	return synthesizeListener.err()
 }
@@ -0,0 +1,264 @@
+/**
+ * (C) Copyright IBM Corp. 2021.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package texttospeechv1_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"runtime"
+	"sync"
+	"time"
+
+	"github.com/IBM/go-sdk-core/v5/core"
+	"github.com/gorilla/websocket"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/watson-developer-cloud/go-sdk/v2/texttospeechv1"
+)
+
+// synthesizeRecorder : Records every callback invocation in order
+type synthesizeRecorder struct {
+	mutex       sync.Mutex
+	events      []string
+	errors      []error
+	contentType string
+	audio       []byte
+	timings     []texttospeechv1.Timings
+	marks       []texttospeechv1.Marks
+
+	// onAudio is called after each audio chunk is recorded
+	onAudio func()
+}
+
+func (cb *synthesizeRecorder) record(event string) {
+	cb.mutex.Lock()
+	defer cb.mutex.Unlock()
+	cb.events = append(cb.events, event)
+}
+
+func (cb *synthesizeRecorder) OnOpen()  { cb.record("open") }
+func (cb *synthesizeRecorder) OnClose() { cb.record("close") }
+func (cb *synthesizeRecorder) OnError(err error) {
+	cb.mutex.Lock()
+	cb.errors = append(cb.errors, err)
+	cb.mutex.Unlock()
+	cb.record("error")
+}
+func (cb *synthesizeRecorder) OnContentType(contentType string) {
+	cb.contentType = contentType
+	cb.record("content_type")
+}
+func (cb *synthesizeRecorder) OnTimingInformation(timings texttospeechv1.Timings) {
+	cb.timings = append(cb.timings, timings)
+	cb.record("timings")
+}
+func (cb *synthesizeRecorder) OnMarks(marks texttospeechv1.Marks) {
+	cb.marks = append(cb.marks, marks)
+	cb.record("marks")
+}
+func (cb *synthesizeRecorder) OnAudioStream(audio []byte) {
+	cb.audio = append(cb.audio, audio...)
+	cb.record("audio")
+	if cb.onAudio != nil {
+		cb.onAudio()
+	}
+}
+func (cb *synthesizeRecorder) OnData(resp *core.DetailedResponse) {}
+
+func (cb *synthesizeRecorder) Events() []string {
+	cb.mutex.Lock()
+	defer cb.mutex.Unlock()
+	return append([]string(nil), cb.events...)
+}
+
+// standInMessage : A message sent by synthesizeStandIn
+type standInMessage struct {
+	messageType int
+	data        string
+}
+
+// synthesizeStandIn : A minimal stand-in for the /v1/synthesize websocket endpoint
+type synthesizeStandIn struct {
+	mutex    sync.Mutex
+	query    string
+	request  map[string]interface{}
+	messages []standInMessage
+	hangOpen bool
+}
+
+func (s *synthesizeStandIn) handler() http.HandlerFunc {
+	upgrader := websocket.Upgrader{}
+	return func(w http.ResponseWriter, r *http.Request) {
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		_, message, err := conn.ReadMessage()
+		if err != nil {
+			return
+		}
+		s.mutex.Lock()
+		s.query = r.URL.RawQuery
+		_ = json.Unmarshal(message, &s.request)
+		s.mutex.Unlock()
+
+		for _, msg := range s.messages {
+			if err := conn.WriteMessage(msg.messageType, []byte(msg.data)); err != nil {
+				return
+			}
+		}
+		if s.hangOpen {
+			for {
+				if _, _, err := conn.ReadMessage(); err != nil {
+					return
+				}
+			}
+		}
+		_ = conn.WriteMessage(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseNormalClosure, ""))
+		for {
+			if _, _, err := conn.ReadMessage(); err != nil {
+				return
+			}
+		}
+	}
+}
+
+var _ = Describe(`SynthesizeUsingWebsocket`, func() {
+	var standIn *synthesizeStandIn
+	var testServer *httptest.Server
+	var textToSpeechService *texttospeechv1.TextToSpeechV1
+	var goroutines int
+
+	contentType := standInMessage{websocket.TextMessage, `{"binary_streams":[{"content_type":"audio/ogg;codecs=opus"}]}`}
+	words := standInMessage{websocket.TextMessage, `{"words":[["Hello",0.0,0.35],["world",0.35,0.8]]}`}
+	marks := standInMessage{websocket.TextMessage, `{"marks":[["here",0.35]]}`}
+	audio := standInMessage{websocket.BinaryMessage, "OggS-audio"}
+
+	BeforeEach(func() {
+		goroutines = runtime.NumGoroutine()
+		standIn = &synthesizeStandIn{}
+		testServer = httptest.NewServer(standIn.handler())
+		var serviceErr error
+		textToSpeechService, serviceErr = texttospeechv1.NewTextToSpeechV1(&texttospeechv1.TextToSpeechV1Options{
+			URL:           testServer.URL,
+			Authenticator: &core.NoAuthAuthenticator{},
+		})
+		Expect(serviceErr).To(BeNil())
+	})
+	AfterEach(func() {
+		testServer.CloseClientConnections()
+		testServer.Close()
+		Eventually(runtime.NumGoroutine, time.Second).Should(BeNumerically("<=", goroutines))
+	})
+
+	It(`Invoke SynthesizeUsingWebsocketWithContext and receive events in order`, func() {
+		standIn.messages = []standInMessage{contentType, words, audio, marks, audio}
+		callback := &synthesizeRecorder{}
+		options := textToSpeechService.NewSynthesizeUsingWebsocketOptions("Hello <mark name=\"here\"/>world", callback).
+			SetTimings([]string{"words"})
+		options.SetVoice("en-US_AllisonV3Voice")
+		options.SetAccept("audio/ogg;codecs=opus")
+
+		err := textToSpeechService.SynthesizeUsingWebsocketWithContext(context.Background(), options)
+		Expect(err).To(BeNil())
+		Expect(callback.Events()).To(Equal([]string{"open", "content_type", "timings", "audio", "marks", "audio", "close"}))
+		Expect(callback.contentType).To(Equal("audio/ogg;codecs=opus"))
+		Expect(string(callback.audio)).To(Equal("OggS-audioOggS-audio"))
+		Expect(callback.timings[0].Words).To(HaveLen(2))
+		Expect(callback.marks[0].Marks).To(HaveLen(1))
+
+		Expect(standIn.query).To(ContainSubstring("voice=en-US_AllisonV3Voice"))
+		Expect(standIn.request["text"]).To(Equal("Hello <mark name=\"here\"/>world"))
+		Expect(standIn.request["accept"]).To(Equal("audio/ogg;codecs=opus"))
+		Expect(standIn.request["timings"]).To(Equal([]interface{}{"words"}))
+	})
+
+	It(`Invoke SynthesizeUsingWebsocket and hold back audio until the content type arrives`, func() {
+		standIn.messages = []standInMessage{audio, marks, contentType, audio}
+		callback := &synthesizeRecorder{}
+		err := textToSpeechService.SynthesizeUsingWebsocket(textToSpeechService.NewSynthesizeUsingWebsocketOptions("Hello", callback))
+		Expect(err).To(BeNil())
+		Expect(callback.Events()).To(Equal([]string{"open", "content_type", "audio", "marks", "audio", "close"}))
+	})
+
+	It(`Invoke SynthesizeUsingWebsocket with a service error`, func() {
+		standIn.messages = []standInMessage{
+			contentType,
+			{websocket.TextMessage, `{"error":"Model en-US_Foo not found"}`},
+			audio,
+		}
+		callback := &synthesizeRecorder{}
+		err := textToSpeechService.SynthesizeUsingWebsocket(textToSpeechService.NewSynthesizeUsingWebsocketOptions("Hello", callback))
+		Expect(err).ToNot(BeNil())
+		Expect(err.Error()).To(Equal("Model en-US_Foo not found"))
+		Expect(callback.Events()).To(Equal([]string{"open", "content_type", "error", "close"}))
+		Expect(callback.errors).To(HaveLen(1))
+	})
+
+	It(`Invoke SynthesizeUsingWebsocket with a service error before the content type`, func() {
+		standIn.messages = []standInMessage{
+			audio,
+			marks,
+			{websocket.TextMessage, `{"error":"Model en-US_Foo not found"}`},
+		}
+		callback := &synthesizeRecorder{}
+		err := textToSpeechService.SynthesizeUsingWebsocket(textToSpeechService.NewSynthesizeUsingWebsocketOptions("Hello", callback))
+		Expect(err).ToNot(BeNil())
+		Expect(callback.Events()).To(Equal([]string{"open", "audio", "marks", "error", "close"}))
+	})
+
+	It(`Invoke SynthesizeUsingWebsocketWithContext and cancel`, func() {
+		standIn.messages = []standInMessage{contentType, audio}
+		standIn.hangOpen = true
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		callback := &synthesizeRecorder{onAudio: cancel}
+
+		err := textToSpeechService.SynthesizeUsingWebsocketWithContext(ctx, textToSpeechService.NewSynthesizeUsingWebsocketOptions("Hello", callback))
+		Expect(err).To(Equal(context.Canceled))
+		Expect(callback.Events()).To(Equal([]string{"open", "content_type", "audio", "close"}))
+	})
+
+	It(`Invoke SynthesizeUsingWebsocketWithContext with a deadline`, func() {
+		standIn.messages = []standInMessage{contentType}
+		standIn.hangOpen = true
+		ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+		defer cancel()
+		callback := &synthesizeRecorder{}
+
+		err := textToSpeechService.SynthesizeUsingWebsocketWithContext(ctx, textToSpeechService.NewSynthesizeUsingWebsocketOptions("Hello", callback))
+		Expect(err).To(Equal(context.DeadlineExceeded))
+		Expect(callback.errors).To(BeEmpty())
+		Expect(callback.Events()).To(Equal([]string{"open", "content_type", "close"}))
+	})
+
+	It(`Invoke SynthesizeUsingWebsocket when the dial fails`, func() {
+		testServer.Close()
+		callback := &synthesizeRecorder{}
+		err := textToSpeechService.SynthesizeUsingWebsocket(textToSpeechService.NewSynthesizeUsingWebsocketOptions("Hello", callback))
+		Expect(err).ToNot(BeNil())
+		Expect(callback.Events()).To(BeEmpty())
+	})
+
+	It(`Invoke SynthesizeUsingWebsocket without options`, func() {
+		err := textToSpeechService.SynthesizeUsingWebsocket(nil)
+		Expect(err).ToNot(BeNil())
+	})
+})
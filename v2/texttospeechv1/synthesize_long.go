@@ -0,0 +1,546 @@
+/**
+ * (C) Copyright IBM Corp. 2021.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package texttospeechv1
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"mime"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/IBM/go-sdk-core/v5/core"
+)
+
+const (
+	// MaxSynthesizeTextLength is the most bytes of text the service accepts in one synthesis request
+	MaxSynthesizeTextLength = 5 * 1024
+
+	defaultSynthesizeLongConcurrency = 4
+	defaultSynthesizeAccept          = "audio/ogg;codecs=opus"
+)
+
+// SynthesizeLongOptions : The SynthesizeLong options
+type SynthesizeLongOptions struct {
+	SynthesizeOptions
+
+	// The writer to which the stitched audio is written. WAV audio is streamed to an io.WriteSeeker, such as a file, and
+	// buffered in memory for other writers; see AudioSink.
+	Writer io.Writer `json:"-" validate:"required"`
+
+	// The number of chunks synthesized at the same time. Defaults to 4. At most twice as many chunks are synthesized or
+	// held in memory while they wait for an earlier chunk.
+	Concurrency int `json:"-"`
+
+	// The most bytes of text, including SSML markup, in one chunk. Defaults to and cannot exceed
+	// MaxSynthesizeTextLength.
+	MaxChunkLength int `json:"-"`
+
+	// Return the word timings of the whole text, offset by the start time of each chunk. The chunks are then
+	// synthesized over websocket connections, and the audio must be WAV or a raw format (`audio/l16`, `audio/mulaw`,
+	// `audio/alaw` or `audio/basic`) so that the length of each chunk is known.
+	WordTimings bool `json:"-"`
+}
+
+// NewSynthesizeLongOptions : Instantiate SynthesizeLongOptions
+func (textToSpeech *TextToSpeechV1) NewSynthesizeLongOptions(text string, writer io.Writer) *SynthesizeLongOptions {
+	return &SynthesizeLongOptions{
+		SynthesizeOptions: *textToSpeech.NewSynthesizeOptions(text),
+		Writer:            writer,
+	}
+}
+
+// SetConcurrency : Allow user to set Concurrency
+func (_options *SynthesizeLongOptions) SetConcurrency(concurrency int) *SynthesizeLongOptions {
+	_options.Concurrency = concurrency
+	return _options
+}
+
+// SetMaxChunkLength : Allow user to set MaxChunkLength
+func (_options *SynthesizeLongOptions) SetMaxChunkLength(maxChunkLength int) *SynthesizeLongOptions {
+	_options.MaxChunkLength = maxChunkLength
+	return _options
+}
+
+// SetWordTimings : Allow user to set WordTimings
+func (_options *SynthesizeLongOptions) SetWordTimings(wordTimings bool) *SynthesizeLongOptions {
+	_options.WordTimings = wordTimings
+	return _options
+}
+
+// SynthesizeLongResult : The outcome of SynthesizeLong
+type SynthesizeLongResult struct {
+	// The number of chunks the text was split into.
+	Chunks int
+
+	// The length of the audio in seconds, for WAV and raw audio; zero otherwise.
+	Duration float64
+
+	// The word timings of the whole text, when they were requested.
+	Words []WordTiming
+}
+
+// SynthesizeLong : Synthesizes text of any length. The text is split into chunks of at most MaxChunkLength bytes at
+// sentence boundaries, or at word boundaries for longer sentences, as SplitSynthesizeText does. The chunks are
+// synthesized concurrently and their audio is written to the Writer in order as it becomes available; WAV segments
+// are joined into one WAV file and raw audio is concatenated. Other formats are written one after the other, which
+// gives a chained stream for Ogg. The first error cancels the chunks still being synthesized.
+func (textToSpeech *TextToSpeechV1) SynthesizeLong(ctx context.Context, synthesizeLongOptions *SynthesizeLongOptions) (result *SynthesizeLongResult, err error) {
+	err = core.ValidateNotNil(synthesizeLongOptions, "synthesizeLongOptions cannot be nil")
+	if err != nil {
+		return
+	}
+	err = core.ValidateStruct(synthesizeLongOptions, "synthesizeLongOptions")
+	if err != nil {
+		return
+	}
+
+	maxChunkLength := synthesizeLongOptions.MaxChunkLength
+	if maxChunkLength <= 0 || maxChunkLength > MaxSynthesizeTextLength {
+		maxChunkLength = MaxSynthesizeTextLength
+	}
+	concurrency := synthesizeLongOptions.Concurrency
+	if concurrency <= 0 {
+		concurrency = defaultSynthesizeLongConcurrency
+	}
+	accept := defaultSynthesizeAccept
+	if synthesizeLongOptions.Accept != nil {
+		accept = *synthesizeLongOptions.Accept
+	}
+	rawBytesPerSecond, raw, err := rawAudioBytesPerSecond(accept)
+	if err != nil {
+		return
+	}
+	wav := strings.HasPrefix(accept, "audio/wav")
+	if synthesizeLongOptions.WordTimings && !wav && !raw {
+		err = fmt.Errorf("word timings need WAV or raw audio, not %q", accept)
+		return
+	}
+
+	chunks, err := SplitSynthesizeText(*synthesizeLongOptions.Text, maxChunkLength)
+	if err != nil {
+		return
+	}
+	result = &SynthesizeLongResult{Chunks: len(chunks)}
+
+	ctx, cancel := context.WithCancel(ctx)
+	var failure struct {
+		sync.Mutex
+		err error
+	}
+	fail := func(chunkErr error) {
+		failure.Lock()
+		if failure.err == nil {
+			failure.err = chunkErr
+		}
+		failure.Unlock()
+		cancel()
+	}
+	firstErr := func(err error) error {
+		failure.Lock()
+		defer failure.Unlock()
+		if failure.err != nil {
+			return failure.err
+		}
+		return err
+	}
+
+	// Each chunk is synthesized by one of the workers; its outcome is passed back on its own channel so that the
+	// audio can be written in order
+	type chunkResult struct {
+		audio []byte
+		words []WordTiming
+		err   error
+	}
+	outcomes := make([]chan chunkResult, len(chunks))
+	for i := range outcomes {
+		outcomes[i] = make(chan chunkResult, 1)
+	}
+	indexes := make(chan int)
+	var workers sync.WaitGroup
+	for w := 0; w < concurrency && w < len(chunks); w++ {
+		workers.Add(1)
+		go func() {
+			defer workers.Done()
+			for i := range indexes {
+				audio, words, chunkErr := textToSpeech.synthesizeChunk(ctx, synthesizeLongOptions, chunks[i])
+				if chunkErr != nil {
+					fail(chunkErr)
+				}
+				outcomes[i] <- chunkResult{audio: audio, words: words, err: chunkErr}
+			}
+		}()
+	}
+	// A chunk is only started once there is room for its audio: at most twice the concurrency of chunks are being
+	// synthesized or waiting for an earlier chunk to be written, so a slow chunk does not let the audio of all later
+	// chunks pile up in memory
+	slots := make(chan struct{}, 2*concurrency)
+	go func() {
+		defer close(indexes)
+		for i := range chunks {
+			select {
+			case slots <- struct{}{}:
+			case <-ctx.Done():
+				return
+			}
+			select {
+			case indexes <- i:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	defer func() {
+		cancel()
+		workers.Wait()
+	}()
+
+	sink := NewAudioSink(synthesizeLongOptions.Writer)
+	offset := 0.0
+	for i := range chunks {
+		var outcome chunkResult
+		select {
+		case outcome = <-outcomes[i]:
+		case <-ctx.Done():
+			return result, firstErr(ctx.Err())
+		}
+		if outcome.err != nil {
+			return result, firstErr(outcome.err)
+		}
+		for _, word := range outcome.words {
+			result.Words = append(result.Words, WordTiming{Word: word.Word, Start: word.Start + offset, End: word.End + offset})
+		}
+		if wav {
+			offset += wavDuration(outcome.audio)
+		} else if raw {
+			offset += float64(len(outcome.audio)) / float64(rawBytesPerSecond)
+		}
+
+		if err = sink.StartSegment(); err != nil {
+			return
+		}
+		if _, err = sink.Write(outcome.audio); err != nil {
+			return
+		}
+		<-slots
+	}
+	if err = sink.Close(); err != nil {
+		return
+	}
+	result.Duration = offset
+	return
+}
+
+// synthesizeChunk : Synthesizes one chunk of text, over a websocket connection when word timings are needed
+func (textToSpeech *TextToSpeechV1) synthesizeChunk(ctx context.Context, synthesizeLongOptions *SynthesizeLongOptions, text string) (audio []byte, words []WordTiming, err error) {
+	synthesizeOptions := synthesizeLongOptions.SynthesizeOptions
+	synthesizeOptions.Text = core.StringPtr(text)
+
+	if !synthesizeLongOptions.WordTimings {
+		body, _, synthesizeErr := textToSpeech.SynthesizeWithContext(ctx, &synthesizeOptions)
+		if synthesizeErr != nil {
+			return nil, nil, synthesizeErr
+		}
+		defer body.Close()
+		audio, err = ioutil.ReadAll(body)
+		return
+	}
+
+	callback := &chunkCallback{}
+	collector := NewTimingCollector(callback)
+	synthesizeWSOptions := &SynthesizeUsingWebsocketOptions{
+		SynthesizeOptions: synthesizeOptions,
+		Callback:          collector,
+		Timings:           []string{"words"},
+	}
+	if err = textToSpeech.SynthesizeUsingWebsocketWithContext(ctx, synthesizeWSOptions); err != nil {
+		return
+	}
+	return callback.audio.Bytes(), collector.Words(), nil
+}
+
+// chunkCallback : Gathers the audio of a websocket synthesis
+type chunkCallback struct {
+	audio bytes.Buffer
+}
+
+func (callback *chunkCallback) OnOpen()                       {}
+func (callback *chunkCallback) OnError(error)                 {}
+func (callback *chunkCallback) OnContentType(string)          {}
+func (callback *chunkCallback) OnTimingInformation(Timings)   {}
+func (callback *chunkCallback) OnMarks(Marks)                 {}
+func (callback *chunkCallback) OnData(*core.DetailedResponse) {}
+func (callback *chunkCallback) OnClose()                      {}
+func (callback *chunkCallback) OnAudioStream(audio []byte)    { callback.audio.Write(audio) }
+
+// rawAudioBytesPerSecond : Returns the byte rate of a raw audio format, and whether the format is raw
+func rawAudioBytesPerSecond(accept string) (int64, bool, error) {
+	mediaType, params, err := mime.ParseMediaType(accept)
+	if err != nil {
+		return 0, false, err
+	}
+	var bytesPerSample int64
+	switch mediaType {
+	case "audio/basic":
+		return 8000, true, nil
+	case "audio/l16":
+		bytesPerSample = 2
+	case "audio/mulaw", "audio/alaw":
+		bytesPerSample = 1
+	default:
+		return 0, false, nil
+	}
+	rate, err := strconv.ParseInt(params["rate"], 10, 64)
+	if err != nil || rate <= 0 {
+		return 0, false, fmt.Errorf("%s audio needs a rate, such as %s;rate=22050", mediaType, mediaType)
+	}
+	return rate * bytesPerSample, true, nil
+}
+
+// wavDuration : Returns the length in seconds of a complete WAV file
+func wavDuration(wav []byte) float64 {
+	format, dataOffset, complete, err := parseWAVHeader(wav)
+	if err != nil || !complete || len(format) < 12 {
+		return 0
+	}
+	byteRate := binary.LittleEndian.Uint32(format[8:12])
+	if byteRate == 0 {
+		return 0
+	}
+	return float64(int64(len(wav))-dataOffset-8) / float64(byteRate)
+}
+
+var speakTagPattern = regexp.MustCompile(`^<speak(\s[^>]*)?>`)
+
+// wrappingElements are the SSML elements that a chunk may end inside of. The chunk closes them, and the next chunk
+// opens them again with the same attributes. Other elements, such as `<say-as>` or `<phoneme>`, are kept whole.
+var wrappingElements = map[string]bool{
+	"emphasis":             true,
+	"express-as":           true,
+	"p":                    true,
+	"paragraph":            true,
+	"prosody":              true,
+	"s":                    true,
+	"sentence":             true,
+	"voice-transformation": true,
+}
+
+// SplitSynthesizeText : Splits text into chunks of at most maxLength bytes for synthesis. Chunks end at sentence
+// boundaries where possible, then at word boundaries, and never inside an SSML tag or entity. Elements are kept whole
+// where possible; otherwise a chunk may end inside wrapping elements such as `<prosody>` or `<p>`, which are closed at
+// the end of the chunk and opened again at the start of the next, but never inside other elements. A `<speak>` element
+// around the text is repeated around every chunk.
+func SplitSynthesizeText(text string, maxLength int) ([]string, error) {
+	text = strings.TrimSpace(text)
+	openTag, closeTag := "", ""
+	if tag := speakTagPattern.FindString(text); tag != "" && strings.HasSuffix(text, "</speak>") {
+		openTag, closeTag = tag, "</speak>"
+		text = strings.TrimSpace(text[len(tag) : len(text)-len(closeTag)])
+	}
+	limit := maxLength - len(openTag) - len(closeTag)
+	if limit <= 0 {
+		return nil, fmt.Errorf("a chunk of %d bytes cannot hold the <speak> element", maxLength)
+	}
+
+	points := splitPoints(text)
+	var chunks []string
+	for start := 0; start < len(text); {
+		reopen := strings.Join(points.open[start], "")
+		end := len(text)
+		if len(reopen)+end-start > limit {
+			end = points.best(text, start, limit-len(reopen))
+			if end <= start {
+				return nil, fmt.Errorf("the SSML element at byte %d is longer than a chunk of %d bytes", start, maxLength)
+			}
+		}
+		// A chunk that would only open wrapping elements is left out; the next chunk opens them again anyway
+		if chunk := strings.TrimSpace(text[start:end]); chunk != "" && !onlyOpens(chunk, points.open[start], points.open[end]) {
+			chunks = append(chunks, openTag+reopen+chunk+closingTags(points.open[end])+closeTag)
+		}
+		start = end
+	}
+	return chunks, nil
+}
+
+// textSplitPoints : The byte positions of a text at which a chunk may end
+type textSplitPoints struct {
+	// Whether a chunk may end at the position.
+	safe []bool
+
+	// Whether the position ends a sentence.
+	sentenceEnd []bool
+
+	// The start tags of the wrapping elements that are open at the position, outermost first.
+	open [][]string
+}
+
+// splitPoints : Marks the byte positions of text at which a chunk may end, and those that end a sentence
+func splitPoints(text string) *textSplitPoints {
+	points := &textSplitPoints{
+		safe:        make([]bool, len(text)+1),
+		sentenceEnd: make([]bool, len(text)+1),
+		open:        make([][]string, len(text)+1),
+	}
+	var open []string
+	// The number of open elements that a chunk may not end inside of
+	blocked := 0
+	var blockedAt []bool
+	inTag, inEntity := false, false
+	for i := 0; i < len(text); {
+		r, size := utf8.DecodeRuneInString(text[i:])
+		if !inTag && !inEntity && blocked == 0 {
+			points.safe[i] = true
+		}
+		points.open[i] = open
+		switch {
+		case inTag:
+			if r == '>' {
+				inTag = false
+				tagStart := strings.LastIndexByte(text[:i], '<')
+				tag := text[tagStart:i]
+				switch {
+				case strings.HasSuffix(tag, "/"), strings.HasPrefix(tag, "<!"), strings.HasPrefix(tag, "<?"):
+					// Self-closing elements, comments and processing instructions
+				case strings.HasPrefix(tag, "</"):
+					if len(blockedAt) > 0 {
+						if blockedAt[len(blockedAt)-1] {
+							blocked--
+						} else {
+							open = open[:len(open)-1]
+						}
+						blockedAt = blockedAt[:len(blockedAt)-1]
+					}
+				case wrappingElements[tagName(tag)] && blocked == 0:
+					// A new slice, since the positions seen so far share the old one
+					open = append(append([]string{}, open...), text[tagStart:i+1])
+					blockedAt = append(blockedAt, false)
+				default:
+					blocked++
+					blockedAt = append(blockedAt, true)
+				}
+			}
+		case inEntity:
+			if r == ';' || unicode.IsSpace(r) {
+				inEntity = false
+			}
+		case r == '<':
+			inTag = true
+		case r == '&':
+			inEntity = true
+		case r == '。' || r == '！' || r == '？':
+			if blocked == 0 {
+				points.sentenceEnd[i+size] = true
+			}
+		case r == '.' || r == '!' || r == '?':
+			next := i + size
+			if blocked == 0 && (next == len(text) || isSpaceAt(text, next)) {
+				points.sentenceEnd[next] = true
+			}
+		}
+		i += size
+	}
+	points.safe[len(text)] = true
+	points.open[len(text)] = open
+	return points
+}
+
+// best : Finds the end of a chunk that starts at start and holds at most length bytes of text, including the tags
+// that close the open wrapping elements at its end. The last position that ends a sentence is preferred, then the last
+// whitespace, then the last safe position, and positions outside of any element are preferred over those inside
+// wrapping elements. Zero is returned when there is none.
+func (points *textSplitPoints) best(text string, start int, length int) int {
+	end := start + length
+	if end > len(text) {
+		end = len(text)
+	}
+	// The candidates by preference: outside of any element a sentence end and whitespace, then the same inside
+	// wrapping elements, then any safe position
+	var candidates [5]int
+	for i := end; i > start; i-- {
+		if !points.safe[i] || i-start+len(closingTags(points.open[i])) > length {
+			continue
+		}
+		nested := 0
+		if len(points.open[i]) > 0 {
+			nested = 2
+		}
+		if points.sentenceEnd[i] && candidates[nested] == 0 {
+			candidates[nested] = i
+		}
+		if isSpaceAt(text, i) && candidates[nested+1] == 0 {
+			candidates[nested+1] = i
+		}
+		if candidates[4] == 0 {
+			candidates[4] = i
+		}
+	}
+	for _, candidate := range candidates {
+		if candidate != 0 {
+			return candidate
+		}
+	}
+	return 0
+}
+
+// onlyOpens : Tells whether a chunk holds nothing but the start tags that take the open wrapping elements from before
+// to after
+func onlyOpens(chunk string, before []string, after []string) bool {
+	if len(after) <= len(before) {
+		return false
+	}
+	removeSpace := func(r rune) rune {
+		if unicode.IsSpace(r) {
+			return -1
+		}
+		return r
+	}
+	return strings.Map(removeSpace, chunk) == strings.Map(removeSpace, strings.Join(after[len(before):], ""))
+}
+
+// tagName : Returns the element name of a start tag, without the closing `>`
+func tagName(tag string) string {
+	name := strings.TrimPrefix(tag, "<")
+	if i := strings.IndexFunc(name, func(r rune) bool { return unicode.IsSpace(r) || r == '/' }); i >= 0 {
+		name = name[:i]
+	}
+	return name
+}
+
+// closingTags : Returns the end tags that close the start tags, innermost first
+func closingTags(startTags []string) string {
+	var closing strings.Builder
+	for i := len(startTags) - 1; i >= 0; i-- {
+		closing.WriteString("</" + tagName(strings.TrimSuffix(startTags[i], ">")) + ">")
+	}
+	return closing.String()
+}
+
+func isSpaceAt(text string, i int) bool {
+	if i >= len(text) {
+		return false
+	}
+	r, _ := utf8.DecodeRuneInString(text[i:])
+	return unicode.IsSpace(r)
+}
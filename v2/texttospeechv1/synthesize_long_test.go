@@ -0,0 +1,261 @@
+/**
+ * (C) Copyright IBM Corp. 2021.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package texttospeechv1_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/IBM/go-sdk-core/v5/core"
+	"github.com/gorilla/websocket"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/watson-developer-cloud/go-sdk/v2/texttospeechv1"
+)
+
+var _ = Describe(`SplitSynthesizeText`, func() {
+	It(`Split at sentence boundaries`, func() {
+		chunks, err := texttospeechv1.SplitSynthesizeText("One two. Three four five. Six? Pi is 3.14 exactly.", 20)
+		Expect(err).To(BeNil())
+		Expect(chunks).To(Equal([]string{"One two.", "Three four five.", "Six?", "Pi is 3.14 exactly."}))
+	})
+
+	It(`Split long sentences at word boundaries`, func() {
+		chunks, err := texttospeechv1.SplitSynthesizeText("alpha beta gamma delta epsilon", 12)
+		Expect(err).To(BeNil())
+		Expect(chunks).To(Equal([]string{"alpha beta", "gamma delta", "epsilon"}))
+	})
+
+	It(`Split Japanese sentences`, func() {
+		chunks, err := texttospeechv1.SplitSynthesizeText("こんにちは。元気ですか？", 20)
+		Expect(err).To(BeNil())
+		Expect(chunks).To(Equal([]string{"こんにちは。", "元気ですか？"}))
+	})
+
+	It(`Keep SSML elements whole and repeat the speak element`, func() {
+		text := `<speak version="1.0">Hi. <prosody rate="slow">One. Two.</prosody> Fish &amp; chips.</speak>`
+		chunks, err := texttospeechv1.SplitSynthesizeText(text, 70)
+		Expect(err).To(BeNil())
+		Expect(chunks).To(Equal([]string{
+			`<speak version="1.0">Hi.</speak>`,
+			`<speak version="1.0"><prosody rate="slow">One. Two.</prosody></speak>`,
+			`<speak version="1.0">Fish &amp; chips.</speak>`,
+		}))
+
+		_, err = texttospeechv1.SplitSynthesizeText(text, 40)
+		Expect(err).ToNot(BeNil())
+	})
+
+	It(`Split inside wrapping elements that are longer than a chunk`, func() {
+		text := `<speak><p><prosody rate="slow">One two. Three <say-as interpret-as="digits">1234</say-as>.</prosody></p> Four.</speak>`
+		chunks, err := texttospeechv1.SplitSynthesizeText(text, 100)
+		Expect(err).To(BeNil())
+		Expect(chunks).To(Equal([]string{
+			`<speak><p><prosody rate="slow">One two.</prosody></p></speak>`,
+			`<speak><p><prosody rate="slow">Three</prosody></p></speak>`,
+			`<speak><p><prosody rate="slow"><say-as interpret-as="digits">1234</say-as>.</prosody></p></speak>`,
+			`<speak>Four.</speak>`,
+		}))
+		for _, chunk := range chunks {
+			Expect(len(chunk)).To(BeNumerically("<=", 100))
+		}
+
+		_, err = texttospeechv1.SplitSynthesizeText(`<prosody rate="slow"><say-as interpret-as="digits">1234567890</say-as></prosody>`, 60)
+		Expect(err).ToNot(BeNil())
+	})
+})
+
+// longStandIn : A stand-in for the /v1/synthesize endpoint that answers both HTTP and websocket requests. The
+// audio of a chunk is its text, padded to an even length, so that the order of the stitched audio can be checked.
+type longStandIn struct{}
+
+func chunkSamples(text string) []byte {
+	if len(text)%2 == 1 {
+		text += " "
+	}
+	return []byte(text)
+}
+
+func (longStandIn) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if websocket.IsWebSocketUpgrade(r) {
+		upgrader := websocket.Upgrader{}
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		var request struct {
+			Text   string `json:"text"`
+			Accept string `json:"accept"`
+		}
+		if err := conn.ReadJSON(&request); err != nil {
+			return
+		}
+		// Half a second of 1 kHz l16 audio per chunk, with one timing per word
+		_ = conn.WriteMessage(websocket.TextMessage, []byte(fmt.Sprintf(`{"binary_streams":[{"content_type":%q}]}`, request.Accept)))
+		var words [][]interface{}
+		for i, word := range strings.Fields(request.Text) {
+			words = append(words, []interface{}{word, 0.1 * float64(i), 0.1 * float64(i+1)})
+		}
+		timings, _ := json.Marshal(map[string]interface{}{"words": words})
+		_ = conn.WriteMessage(websocket.TextMessage, timings)
+		_ = conn.WriteMessage(websocket.BinaryMessage, make([]byte, 1000))
+		_ = conn.WriteMessage(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseNormalClosure, ""))
+		_, _, _ = conn.ReadMessage()
+		return
+	}
+
+	var request struct {
+		Text string `json:"text"`
+	}
+	_ = json.NewDecoder(r.Body).Decode(&request)
+	if strings.Contains(request.Text, "fail") {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(400)
+		fmt.Fprint(w, `{"error":"Invalid text","code":400}`)
+		return
+	}
+	// Later chunks answer sooner, so the audio arrives out of order
+	time.Sleep(time.Duration(100-len(request.Text)) * time.Millisecond / 10)
+	w.Header().Set("Content-Type", "audio/wav")
+	w.WriteHeader(200)
+	_, _ = w.Write(streamedWAV(22050, chunkSamples(request.Text)))
+}
+
+var _ = Describe(`SynthesizeLong`, func() {
+	var testServer *httptest.Server
+	var textToSpeechService *texttospeechv1.TextToSpeechV1
+
+	BeforeEach(func() {
+		testServer = httptest.NewServer(longStandIn{})
+		var serviceErr error
+		textToSpeechService, serviceErr = texttospeechv1.NewTextToSpeechV1(&texttospeechv1.TextToSpeechV1Options{
+			URL:           testServer.URL,
+			Authenticator: &core.NoAuthAuthenticator{},
+		})
+		Expect(serviceErr).To(BeNil())
+	})
+	AfterEach(func() {
+		testServer.Close()
+	})
+
+	text := "The first sentence. A second, longer sentence. The third. And the fourth sentence ends it."
+
+	It(`Invoke SynthesizeLong and stitch WAV chunks in order`, func() {
+		var output bytes.Buffer
+		options := textToSpeechService.NewSynthesizeLongOptions(text, &output).
+			SetMaxChunkLength(40).
+			SetConcurrency(3)
+		options.SetAccept("audio/wav")
+
+		result, err := textToSpeechService.SynthesizeLong(context.Background(), options)
+		Expect(err).To(BeNil())
+		Expect(result.Chunks).To(Equal(3))
+
+		riffSize, dataSize, samples := wavSizes(output.Bytes())
+		Expect(riffSize).To(Equal(uint32(output.Len() - 8)))
+		Expect(dataSize).To(Equal(uint32(len(samples))))
+		Expect(string(samples)).To(Equal("The first sentence. A second, longer sentence. The third. And the fourth sentence ends it."))
+		Expect(result.Duration).To(BeNumerically("~", float64(len(samples))/44100, 1e-9))
+	})
+
+	It(`Invoke SynthesizeLong and limit the chunks held for a slow chunk`, func() {
+		var started, startedBeforeSlow int32
+		slowServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			atomic.AddInt32(&started, 1)
+			var request struct {
+				Text string `json:"text"`
+			}
+			_ = json.NewDecoder(r.Body).Decode(&request)
+			if strings.HasPrefix(request.Text, "Slow") {
+				time.Sleep(100 * time.Millisecond)
+				atomic.StoreInt32(&startedBeforeSlow, atomic.LoadInt32(&started))
+			}
+			w.Header().Set("Content-Type", "audio/wav")
+			w.WriteHeader(200)
+			_, _ = w.Write(streamedWAV(22050, chunkSamples(request.Text)))
+		}))
+		defer slowServer.Close()
+		service, err := texttospeechv1.NewTextToSpeechV1(&texttospeechv1.TextToSpeechV1Options{
+			URL:           slowServer.URL,
+			Authenticator: &core.NoAuthAuthenticator{},
+		})
+		Expect(err).To(BeNil())
+
+		longText := "Slow one." + strings.Repeat(" Fast one.", 9)
+		var output bytes.Buffer
+		options := service.NewSynthesizeLongOptions(longText, &output).
+			SetMaxChunkLength(10).
+			SetConcurrency(2)
+		options.SetAccept("audio/wav")
+
+		result, err := service.SynthesizeLong(context.Background(), options)
+		Expect(err).To(BeNil())
+		Expect(result.Chunks).To(Equal(10))
+		Expect(atomic.LoadInt32(&startedBeforeSlow)).To(Equal(int32(4)))
+		_, _, samples := wavSizes(output.Bytes())
+		Expect(strings.Replace(string(samples), " ", "", -1)).To(Equal(strings.Replace(longText, " ", "", -1)))
+	})
+
+	It(`Invoke SynthesizeLong with word timings`, func() {
+		var output bytes.Buffer
+		options := textToSpeechService.NewSynthesizeLongOptions("One two. Three.", &output).
+			SetMaxChunkLength(10).
+			SetWordTimings(true)
+		options.SetAccept("audio/l16;rate=1000")
+
+		result, err := textToSpeechService.SynthesizeLong(context.Background(), options)
+		Expect(err).To(BeNil())
+		Expect(output.Len()).To(Equal(2000))
+		Expect(result.Duration).To(BeNumerically("~", 1.0, 1e-9))
+		Expect(result.Words).To(HaveLen(3))
+		Expect(result.Words[1].Word).To(Equal("two."))
+		Expect(result.Words[1].Start).To(BeNumerically("~", 0.1, 1e-9))
+		Expect(result.Words[2].Word).To(Equal("Three."))
+		Expect(result.Words[2].Start).To(BeNumerically("~", 0.5, 1e-9))
+		Expect(result.Words[2].End).To(BeNumerically("~", 0.6, 1e-9))
+	})
+
+	It(`Invoke SynthesizeLong with word timings for compressed audio`, func() {
+		options := textToSpeechService.NewSynthesizeLongOptions(text, &bytes.Buffer{}).SetWordTimings(true)
+		_, err := textToSpeechService.SynthesizeLong(context.Background(), options)
+		Expect(err).ToNot(BeNil())
+	})
+
+	It(`Invoke SynthesizeLong when a chunk fails`, func() {
+		var output bytes.Buffer
+		options := textToSpeechService.NewSynthesizeLongOptions("This one works. This will fail. This is never needed.", &output).
+			SetMaxChunkLength(20)
+		options.SetAccept("audio/wav")
+
+		_, err := textToSpeechService.SynthesizeLong(context.Background(), options)
+		Expect(err).ToNot(BeNil())
+		Expect(err.Error()).To(ContainSubstring("Invalid text"))
+	})
+
+	It(`Invoke SynthesizeLong without a writer`, func() {
+		_, err := textToSpeechService.SynthesizeLong(context.Background(), textToSpeechService.NewSynthesizeLongOptions(text, nil))
+		Expect(err).ToNot(BeNil())
+	})
+})
@@ -0,0 +1,218 @@
+/**
+ * (C) Copyright IBM Corp. 2021.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package texttospeechv1
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/IBM/go-sdk-core/v5/core"
+)
+
+// WordTiming : The start and end time of a word, in seconds from the beginning of the synthesized audio
+type WordTiming struct {
+	Word  string  `json:"word"`
+	Start float64 `json:"start"`
+	End   float64 `json:"end"`
+}
+
+// MarkTiming : The time of an SSML mark, in seconds from the beginning of the synthesized audio
+type MarkTiming struct {
+	Name string  `json:"name"`
+	Time float64 `json:"time"`
+}
+
+// WordTimings : Decodes the `[word, start, end]` tuples of the message
+func (timings Timings) WordTimings() ([]WordTiming, error) {
+	wordTimings := make([]WordTiming, 0, len(timings.Words))
+	for i, tuple := range timings.Words {
+		if len(tuple) != 3 {
+			return nil, fmt.Errorf("word timing %d has %d elements, expected 3", i, len(tuple))
+		}
+		word, ok := tuple[0].(string)
+		if !ok {
+			return nil, fmt.Errorf("word timing %d has a word of type %T", i, tuple[0])
+		}
+		start, ok := tuple[1].(float64)
+		if !ok {
+			return nil, fmt.Errorf("word timing %d has a start time of type %T", i, tuple[1])
+		}
+		end, ok := tuple[2].(float64)
+		if !ok {
+			return nil, fmt.Errorf("word timing %d has an end time of type %T", i, tuple[2])
+		}
+		wordTimings = append(wordTimings, WordTiming{Word: word, Start: start, End: end})
+	}
+	return wordTimings, nil
+}
+
+// MarkTimings : Decodes the `[name, time]` tuples of the message
+func (marks Marks) MarkTimings() ([]MarkTiming, error) {
+	markTimings := make([]MarkTiming, 0, len(marks.Marks))
+	for i, tuple := range marks.Marks {
+		if len(tuple) != 2 {
+			return nil, fmt.Errorf("mark %d has %d elements, expected 2", i, len(tuple))
+		}
+		name, ok := tuple[0].(string)
+		if !ok {
+			return nil, fmt.Errorf("mark %d has a name of type %T", i, tuple[0])
+		}
+		time, ok := tuple[1].(float64)
+		if !ok {
+			return nil, fmt.Errorf("mark %d has a time of type %T", i, tuple[1])
+		}
+		markTimings = append(markTimings, MarkTiming{Name: name, Time: time})
+	}
+	return markTimings, nil
+}
+
+// SynthesizeTimingsCallback : An optional extension of SynthesizeCallbackWrapper. When the callback passed to a
+// websocket synthesis implements it, word timings and marks are also decoded and passed to these methods, right
+// after OnTimingInformation and OnMarks.
+type SynthesizeTimingsCallback interface {
+	SynthesizeCallbackWrapper
+
+	// OnWordTimings receives the word timings of a message, when word timings were requested
+	OnWordTimings([]WordTiming)
+
+	// OnMarkTimings receives the marks of a message, for input text that contains SSML marks
+	OnMarkTimings([]MarkTiming)
+}
+
+// TimingCollector : A SynthesizeTimingsCallback that aggregates the word timings and marks of one synthesis. Every
+// callback is also passed on to the wrapped callback, if there is one, so the collector can be slotted in front of
+// an existing callback.
+type TimingCollector struct {
+	callback SynthesizeCallbackWrapper
+
+	mutex sync.Mutex
+	words []WordTiming
+	marks []MarkTiming
+}
+
+// NewTimingCollector : Instantiate a TimingCollector. The wrapped callback may be nil.
+func NewTimingCollector(callback SynthesizeCallbackWrapper) *TimingCollector {
+	return &TimingCollector{callback: callback}
+}
+
+// Words : Returns the word timings received so far, ordered by start time
+func (collector *TimingCollector) Words() []WordTiming {
+	collector.mutex.Lock()
+	defer collector.mutex.Unlock()
+	words := append([]WordTiming(nil), collector.words...)
+	sort.SliceStable(words, func(i, j int) bool {
+		return words[i].Start < words[j].Start
+	})
+	return words
+}
+
+// Marks : Returns the marks received so far, ordered by time
+func (collector *TimingCollector) Marks() []MarkTiming {
+	collector.mutex.Lock()
+	defer collector.mutex.Unlock()
+	marks := append([]MarkTiming(nil), collector.marks...)
+	sort.SliceStable(marks, func(i, j int) bool {
+		return marks[i].Time < marks[j].Time
+	})
+	return marks
+}
+
+// Reset : Discards the collected timings, so the collector can be used for another synthesis
+func (collector *TimingCollector) Reset() {
+	collector.mutex.Lock()
+	defer collector.mutex.Unlock()
+	collector.words = nil
+	collector.marks = nil
+}
+
+// OnWordTimings : Collects word timings
+func (collector *TimingCollector) OnWordTimings(words []WordTiming) {
+	collector.mutex.Lock()
+	collector.words = append(collector.words, words...)
+	collector.mutex.Unlock()
+	if timingsCallback, ok := collector.callback.(SynthesizeTimingsCallback); ok {
+		timingsCallback.OnWordTimings(words)
+	}
+}
+
+// OnMarkTimings : Collects marks
+func (collector *TimingCollector) OnMarkTimings(marks []MarkTiming) {
+	collector.mutex.Lock()
+	collector.marks = append(collector.marks, marks...)
+	collector.mutex.Unlock()
+	if timingsCallback, ok := collector.callback.(SynthesizeTimingsCallback); ok {
+		timingsCallback.OnMarkTimings(marks)
+	}
+}
+
+// OnOpen : Passes the opening of the connection on to the wrapped callback
+func (collector *TimingCollector) OnOpen() {
+	if collector.callback != nil {
+		collector.callback.OnOpen()
+	}
+}
+
+// OnError : Passes the error on to the wrapped callback
+func (collector *TimingCollector) OnError(err error) {
+	if collector.callback != nil {
+		collector.callback.OnError(err)
+	}
+}
+
+// OnContentType : Passes the audio content type on to the wrapped callback
+func (collector *TimingCollector) OnContentType(contentType string) {
+	if collector.callback != nil {
+		collector.callback.OnContentType(contentType)
+	}
+}
+
+// OnTimingInformation : Passes the raw word timings on to the wrapped callback. They are collected from
+// OnWordTimings.
+func (collector *TimingCollector) OnTimingInformation(timings Timings) {
+	if collector.callback != nil {
+		collector.callback.OnTimingInformation(timings)
+	}
+}
+
+// OnMarks : Passes the raw marks on to the wrapped callback. They are collected from OnMarkTimings.
+func (collector *TimingCollector) OnMarks(marks Marks) {
+	if collector.callback != nil {
+		collector.callback.OnMarks(marks)
+	}
+}
+
+// OnAudioStream : Passes the audio on to the wrapped callback
+func (collector *TimingCollector) OnAudioStream(audio []byte) {
+	if collector.callback != nil {
+		collector.callback.OnAudioStream(audio)
+	}
+}
+
+// OnData : Passes the received message on to the wrapped callback
+func (collector *TimingCollector) OnData(response *core.DetailedResponse) {
+	if collector.callback != nil {
+		collector.callback.OnData(response)
+	}
+}
+
+// OnClose : Passes the closing of the connection on to the wrapped callback
+func (collector *TimingCollector) OnClose() {
+	if collector.callback != nil {
+		collector.callback.OnClose()
+	}
+}
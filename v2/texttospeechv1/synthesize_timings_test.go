@@ -0,0 +1,91 @@
+/**
+ * (C) Copyright IBM Corp. 2021.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package texttospeechv1_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http/httptest"
+
+	"github.com/IBM/go-sdk-core/v5/core"
+	"github.com/gorilla/websocket"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/watson-developer-cloud/go-sdk/v2/texttospeechv1"
+)
+
+var _ = Describe(`Synthesis timings`, func() {
+	It(`Decode word timings`, func() {
+		var timings texttospeechv1.Timings
+		Expect(json.Unmarshal([]byte(`{"words":[["Hello",0.0,0.35],["world",0.35,0.8]]}`), &timings)).To(Succeed())
+		words, err := timings.WordTimings()
+		Expect(err).To(BeNil())
+		Expect(words).To(Equal([]texttospeechv1.WordTiming{
+			{Word: "Hello", Start: 0, End: 0.35},
+			{Word: "world", Start: 0.35, End: 0.8},
+		}))
+	})
+
+	It(`Decode marks`, func() {
+		var marks texttospeechv1.Marks
+		Expect(json.Unmarshal([]byte(`{"marks":[["here",0.35]]}`), &marks)).To(Succeed())
+		markTimings, err := marks.MarkTimings()
+		Expect(err).To(BeNil())
+		Expect(markTimings).To(Equal([]texttospeechv1.MarkTiming{{Name: "here", Time: 0.35}}))
+	})
+
+	It(`Reject malformed timings`, func() {
+		_, err := texttospeechv1.Timings{Words: [][]interface{}{{"Hello", 0.0}}}.WordTimings()
+		Expect(err).ToNot(BeNil())
+		_, err = texttospeechv1.Timings{Words: [][]interface{}{{"Hello", "0.0", 0.35}}}.WordTimings()
+		Expect(err).ToNot(BeNil())
+		_, err = texttospeechv1.Marks{Marks: [][]interface{}{{1.0, 0.35}}}.MarkTimings()
+		Expect(err).ToNot(BeNil())
+	})
+
+	It(`Collect the timings of a websocket synthesis`, func() {
+		standIn := &synthesizeStandIn{messages: []standInMessage{
+			{websocket.TextMessage, `{"binary_streams":[{"content_type":"audio/wav"}]}`},
+			{websocket.TextMessage, `{"words":[["world",0.35,0.8]]}`},
+			{websocket.TextMessage, `{"words":[["Hello",0.0,0.35]]}`},
+			{websocket.TextMessage, `{"marks":[["there",0.8]]}`},
+			{websocket.TextMessage, `{"marks":[["here",0.35]]}`},
+		}}
+		testServer := httptest.NewServer(standIn.handler())
+		defer testServer.Close()
+		textToSpeechService, serviceErr := texttospeechv1.NewTextToSpeechV1(&texttospeechv1.TextToSpeechV1Options{
+			URL:           testServer.URL,
+			Authenticator: &core.NoAuthAuthenticator{},
+		})
+		Expect(serviceErr).To(BeNil())
+
+		recorder := &synthesizeRecorder{}
+		collector := texttospeechv1.NewTimingCollector(recorder)
+		options := textToSpeechService.NewSynthesizeUsingWebsocketOptions("Hello world", collector).SetTimings([]string{"words"})
+		Expect(textToSpeechService.SynthesizeUsingWebsocketWithContext(context.Background(), options)).To(Succeed())
+
+		Expect(collector.Words()).To(Equal([]texttospeechv1.WordTiming{
+			{Word: "Hello", Start: 0, End: 0.35},
+			{Word: "world", Start: 0.35, End: 0.8},
+		}))
+		Expect(collector.Marks()).To(Equal([]texttospeechv1.MarkTiming{{Name: "here", Time: 0.35}, {Name: "there", Time: 0.8}}))
+		Expect(recorder.Events()).To(Equal([]string{"open", "content_type", "timings", "timings", "marks", "marks", "close"}))
+
+		collector.Reset()
+		Expect(collector.Words()).To(BeEmpty())
+	})
+})
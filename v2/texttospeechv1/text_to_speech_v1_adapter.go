@@ -17,11 +17,12 @@
 package texttospeechv1
 
 import (
+	"context"
 	"fmt"
-	"strings"
 
 	"github.com/IBM/go-sdk-core/v5/core"
 	common "github.com/watson-developer-cloud/go-sdk/v2/common"
+	"github.com/watson-developer-cloud/go-sdk/v2/internal/websocketurl"
 )
 
 // Timings : An array of words and their start and end times in seconds from the beginning of the synthesized audio.
@@ -89,6 +90,15 @@ func (options *SynthesizeUsingWebsocketOptions) SetTimings(timings []string) *Sy
 
 // SynthesizeUsingWebsocket: Synthesize text over websocket connection
 func (textToSpeech *TextToSpeechV1) SynthesizeUsingWebsocket(synthesizeOptions *SynthesizeUsingWebsocketOptions) error {
+	return textToSpeech.SynthesizeUsingWebsocketWithContext(context.Background(), synthesizeOptions)
+}
+
+// SynthesizeUsingWebsocketWithContext is an alternate form of the SynthesizeUsingWebsocket method which supports a Context parameter.
+// The connection is dialed with ctx; cancelling it closes the socket. Errors that occur before the connection is open are
+// returned without invoking the callback. Otherwise the callback receives OnOpen, the content type, the audio, timings and
+// marks, and OnClose in that order; the first error of the synthesis is passed to OnError and returned, or the context
+// error if the synthesis was cancelled.
+func (textToSpeech *TextToSpeechV1) SynthesizeUsingWebsocketWithContext(ctx context.Context, synthesizeOptions *SynthesizeUsingWebsocketOptions) error {
 	if err := core.ValidateNotNil(synthesizeOptions, "synthesizeOptions cannot be nil"); err != nil {
 		return err
 	}
@@ -105,7 +115,7 @@ func (textToSpeech *TextToSpeechV1) SynthesizeUsingWebsocket(synthesizeOptions *
 	pathParameters := []string{}
 
 	builder := core.NewRequestBuilder(core.POST)
-	dialURL := strings.Replace(textToSpeech.Service.Options.URL, "https", "wss", 1)
+	dialURL := websocketurl.FromServiceURL(textToSpeech.Service.Options.URL)
 	_, err := builder.ConstructHTTPURL(dialURL, pathSegments, pathParameters)
 	if err != nil {
 		return err
@@ -155,6 +165,5 @@ func (textToSpeech *TextToSpeechV1) SynthesizeUsingWebsocket(synthesizeOptions *
 		return err
 	}
 
-	textToSpeech.NewSynthesizeListener(synthesizeOptions.Callback, request)
-	return nil
+	return textToSpeech.NewSynthesizeListenerWithContext(ctx, synthesizeOptions.Callback, request)
 }